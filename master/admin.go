@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/game"
+	"aluance.io/wordleserver/internal/store"
+)
+
+var adminCommands = map[string]command{
+	"purge":   purge,
+	"export":  export,
+	"import":  load,
+	"backup":  backup,
+	"restore": restore,
+}
+
+func purge(ctx context.Context, con console, args []string) error {
+	fs := newFlagSet("admin purge", con)
+	yes := fs.Bool("yes", false, "really delete every game")
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return ErrUsage
+	}
+	if !*yes {
+		return ErrConfirm
+	}
+
+	shutdown, err := setupStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	if err := game.PurgeAll(ctx); err != nil {
+		return err
+	}
+	fmt.Fprintln(con.err, "purged", config.Current().Store, "store")
+	return nil
+}
+
+func export(ctx context.Context, con console, args []string) error {
+	fs := newFlagSet("admin export", con)
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return ErrUsage
+	}
+	file := fs.Arg(0)
+
+	shutdown, err := setupStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	format := game.SNAPSHOT_JSONL
+	if strings.HasSuffix(file, ".gz") {
+		format = game.SNAPSHOT_GZIP
+	}
+	w := con.out
+	var f *os.File
+	if file != "-" {
+		if f, err = os.Create(file); err != nil {
+			return err
+		}
+		w = f
+	}
+
+	n, err := game.Export(ctx, w, format)
+	if f != nil {
+		// A file that fails to close may not have been written out
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(con.err, "exported", n, "games")
+	return nil
+}
+
+func load(ctx context.Context, con console, args []string) error {
+	fs := newFlagSet("admin import", con)
+	purge := fs.Bool("purge", false, "delete the games that are not in the file")
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return ErrUsage
+	}
+	file := fs.Arg(0)
+
+	shutdown, err := setupStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	r := con.in
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	n, err := game.Import(ctx, r, *purge)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(con.err, "imported", n, "games")
+	return nil
+}
+
+func backup(ctx context.Context, con console, args []string) error {
+	return boltFile(ctx, con, "admin backup", args, func(path, file string) error {
+		n, err := store.BackupBolt(ctx, path, file)
+		if err == nil {
+			fmt.Fprintln(con.err, "copied", n, "bytes")
+		}
+		return err
+	})
+}
+
+func restore(ctx context.Context, con console, args []string) error {
+	return boltFile(ctx, con, "admin restore", args, func(path, file string) error {
+		return store.RestoreBolt(ctx, file, path)
+	})
+}
+
+/////////////
+
+// Runs a command that copies between the bolt store and the file named by args
+func boltFile(ctx context.Context, con console, name string, args []string, transfer func(path, file string) error) error {
+	fs := newFlagSet(name, con)
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return ErrUsage
+	}
+	return transfer(config.Current().BoltPath, fs.Arg(0))
+}
+
+// Connects to the store chosen in the settings. The memory store is refused,
+// as a command would only see a store of its own, gone once it exits.
+func setupStore(ctx context.Context) (func() error, error) {
+	settings := config.Current()
+	if settings.Store == "" || settings.Store == store.STORE_MEMORY {
+		return nil, ErrMemoryStore
+	}
+	return store.Setup(ctx, settings.Store, settings.StoreUrl(), settings.RedisTtl)
+}
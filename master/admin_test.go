@@ -0,0 +1,65 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSnapshot = `{"events":[{"type":"GameCreated","time":"2026-01-01T00:00:00Z","word":"HAPPY","setup":{"id":"g1","mode":"Classic"}}]}
+{"events":[{"type":"GameCreated","time":"2026-01-01T00:01:00Z","word":"LUCKY","setup":{"id":"g2","mode":"Classic"}}]}
+`
+
+func TestAdmin(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := t.TempDir()
+	bolt := []string{"-store", "bolt", "-store-url", filepath.Join(dir, "wordle.bolt")}
+	args := func(command string, more ...string) []string {
+		return append(append([]string{"admin", command}, bolt...), more...)
+	}
+
+	code, _, stderr := runCommand(t, testSnapshot, args("import", "-")...)
+	require.Equal(0, code, stderr)
+	assert.Contains(stderr, "imported 2 games")
+
+	zipped := filepath.Join(dir, "games.jsonl.gz")
+	code, _, stderr = runCommand(t, "", args("export", zipped)...)
+	require.Equal(0, code, stderr)
+	assert.Contains(stderr, "exported 2 games")
+
+	backup := filepath.Join(dir, "backup.bolt")
+	code, _, stderr = runCommand(t, "", args("backup", backup)...)
+	require.Equal(0, code, stderr)
+
+	code, _, stderr = runCommand(t, "", args("purge")...)
+	assert.Equal(1, code)
+	assert.Contains(stderr, ErrConfirm.Error())
+	code, _, stderr = runCommand(t, "", args("purge", "-yes")...)
+	require.Equal(0, code, stderr)
+	code, stdout, _ := runCommand(t, "", args("export", "-")...)
+	require.Equal(0, code)
+	assert.Empty(stdout)
+
+	// The games come back from the backup and from the export
+	code, _, stderr = runCommand(t, "", args("restore", backup)...)
+	require.Equal(0, code, stderr)
+	code, stdout, _ = runCommand(t, "", args("export", "-")...)
+	require.Equal(0, code)
+	assert.Len(strings.Split(strings.TrimSpace(stdout), "\n"), 2)
+
+	code, _, stderr = runCommand(t, "", args("import", "-purge", zipped)...)
+	require.Equal(0, code, stderr)
+	code, stdout, _ = runCommand(t, "", args("export", "-")...)
+	require.Equal(0, code)
+	assert.Contains(stdout, `"id":"g1"`)
+	assert.Contains(stdout, `"id":"g2"`)
+
+	code, _, stderr = runCommand(t, "not a snapshot", args("import", "-")...)
+	assert.Equal(1, code)
+	assert.Contains(stderr, "admin import failed:")
+}
@@ -0,0 +1,22 @@
+// Plays games against a running server in the terminal
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"aluance.io/wordleserver/internal/client"
+)
+
+func main() {
+	session := client.Flags(flag.CommandLine)
+	flag.Parse()
+
+	c, opts := session()
+	if err := client.Run(context.Background(), c, opts, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "wordle:", err)
+		os.Exit(1)
+	}
+}
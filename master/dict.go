@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/dictionary"
+)
+
+var dictCommands = map[string]command{
+	"validate": validate,
+	"stats":    stats,
+}
+
+func validate(ctx context.Context, con console, args []string) error {
+	report, err := inspect(con, "dict validate", args)
+	if err != nil {
+		return err
+	}
+
+	for _, word := range report.Invalid {
+		fmt.Fprintf(con.out, "invalid: %q\n", word)
+	}
+	for _, word := range report.Duplicates {
+		fmt.Fprintln(con.out, "repeated:", word)
+	}
+	fmt.Fprintln(con.out, report.Words, "words of", config.CONFIG_GAME_WORDLENGTH, "letters in", report.Lines, "lines")
+	if !report.Valid() {
+		return ErrInvalidList
+	}
+	return nil
+}
+
+func stats(ctx context.Context, con console, args []string) error {
+	report, err := inspect(con, "dict stats", args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(con.out, "lines:", report.Lines)
+	fmt.Fprintln(con.out, "words:", report.Words)
+	fmt.Fprintln(con.out, "repeated:", len(report.Duplicates))
+	fmt.Fprintln(con.out, "invalid:", len(report.Invalid))
+
+	letters := make([]rune, 0, len(report.Letters))
+	for c := range report.Letters {
+		letters = append(letters, c)
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		a, b := letters[i], letters[j]
+		return report.Letters[a] > report.Letters[b] || (report.Letters[a] == report.Letters[b] && a < b)
+	})
+	fmt.Fprintln(con.out, "words holding each letter:")
+	for _, c := range letters {
+		fmt.Fprintf(con.out, "  %c %6d %5.1f%%\n", c, report.Letters[c], 100*float64(report.Letters[c])/float64(report.Words))
+	}
+	return nil
+}
+
+/////////////
+
+// Reads the word list named by args, or the built-in one when there is none
+func inspect(con console, name string, args []string) (dictionary.Report, error) {
+	fs := newFlagSet(name, con)
+	if err := fs.parse(args); err != nil {
+		return dictionary.Report{}, err
+	}
+	if fs.NArg() > 1 {
+		return dictionary.Report{}, ErrUsage
+	}
+
+	var f io.ReadCloser
+	var err error
+	if fs.NArg() > 0 {
+		f, err = os.Open(fs.Arg(0))
+	} else {
+		f, err = config.LoadEmbedFile(config.CONFIG_DICTIONARY_FILEPATH)
+	}
+	if err != nil {
+		return dictionary.Report{}, err
+	}
+	defer f.Close()
+
+	return dictionary.Inspect(f)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDict(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.txt")
+	require.NoError(t, os.WriteFile(good, []byte("happy\npuppy\ntoo\n"), 0600))
+	bad := filepath.Join(dir, "bad.txt")
+	require.NoError(t, os.WriteFile(bad, []byte("happy\nHappy\nhappy\n"), 0600))
+
+	tests := []struct {
+		args   []string
+		code   int
+		stdout []string
+	}{
+		{args: []string{"validate", good}, stdout: []string{"2 words of 5 letters in 3 lines"}},
+		{args: []string{"validate", bad}, code: 1, stdout: []string{`invalid: "Happy"`, "repeated: happy", "1 words of 5 letters in 3 lines"}},
+		{args: []string{"validate", filepath.Join(dir, "missing.txt")}, code: 1},
+		{args: []string{"validate", good, bad}, code: 2},
+		{args: []string{"stats", good}, stdout: []string{"words: 2", "  p      2 100.0%", "  a      1  50.0%"}},
+		{args: []string{"stats"}, stdout: []string{"words:", "  e "}},
+	}
+
+	for _, test := range tests {
+		code, stdout, _ := runCommand(t, "", append([]string{"dict"}, test.args...)...)
+		assert.Equal(t, test.code, code, test.args)
+		for _, line := range test.stdout {
+			assert.Contains(t, stdout, line, test.args)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package main
+
+import "errors"
+
+var (
+	ErrUsage       = errors.New("invalid command line")
+	ErrFlags       = errors.New("invalid flags")
+	ErrConfirm     = errors.New("purging deletes every game, add -yes to go ahead")
+	ErrInvalidList = errors.New("word list has invalid or repeated words")
+	ErrMemoryStore = errors.New("the memory store is only in the server, choose another with -store")
+)
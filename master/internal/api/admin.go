@@ -0,0 +1,267 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"aluance.io/wordleserver/internal/game"
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// Rejects requests to the admin routes without an admin key
+func adminOnly(c *gin.Context) {
+	if !hasRole(c, ROLE_ADMIN) {
+		handleError(c, ErrAdminOnly)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+func getLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"level": logging.Level()})
+}
+
+// Changes the lowest level logged until the server is restarted
+func putLogLevel(c *gin.Context) {
+	if handleError(c, logging.SetLevel(c.Query("level"))) {
+		return
+	}
+	logging.Info(c.Request.Context(), "log level changed", "level", logging.Level())
+
+	c.JSON(http.StatusOK, gin.H{"level": logging.Level()})
+}
+
+// Lists games, filtered by status and mode (comma separated names) and by
+// age (olderThan and newerThan durations such as "90m")
+func getAdminGames(c *gin.Context) {
+	filter, err := gameFilter(c, time.Now())
+	if handleError(c, err) {
+		return
+	}
+
+	list, err := game.List(c.Request.Context(), filter)
+	if handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"games": list, "count": len(list)})
+}
+
+// Removes every game, as long as the request has confirm=true
+func deleteAdminGames(c *gin.Context) {
+	if c.Query("confirm") != "true" {
+		handleError(c, ErrConfirmPurge)
+		return
+	}
+	if handleError(c, game.PurgeAll(c.Request.Context())) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Shows a game including its secret word and owner, or as it was after its
+// first n events with at=n
+func getAdminGame(c *gin.Context) {
+	var out string
+	var err error
+	if v := c.Query("at"); len(v) > 0 {
+		n, perr := strconv.Atoi(v)
+		if perr != nil {
+			handleError(c, game.ErrEventIndex)
+			return
+		}
+		out, err = game.InspectAt(c.Request.Context(), c.Param("id"), n)
+	} else {
+		out, err = game.Inspect(c.Request.Context(), c.Param("id"))
+	}
+	if handleError(c, err) {
+		return
+	}
+
+	c.Data(http.StatusOK, API_RESPONSE_CONTENT_TYPE, []byte(out))
+}
+
+func getAdminEvents(c *gin.Context) {
+	events, err := game.History(c.Request.Context(), c.Param("id"))
+	if handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+func deleteAdminGame(c *gin.Context) {
+	if handleError(c, game.Delete(c.Request.Context(), c.Param("id"))) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func postAdminResign(c *gin.Context) {
+	out, err := game.ForceResign(c.Request.Context(), c.Param("id"))
+	if handleError(c, err) {
+		return
+	}
+
+	c.Data(http.StatusOK, API_RESPONSE_CONTENT_TYPE, []byte(out))
+}
+
+func getAdminStats(c *gin.Context) {
+	stats, err := game.Statistics(c.Request.Context())
+	if handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// Streams a copy of the whole store, for stores that can make one while in
+// use
+func getAdminBackup(c *gin.Context) {
+	ctx := c.Request.Context()
+	s, err := store.WordleStore()
+	if handleError(c, err) {
+		return
+	}
+	b, ok := s.(store.Backuper)
+	if !ok {
+		handleError(c, ErrNoBackup)
+		return
+	}
+
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", `attachment; filename="wordle-backup.bolt"`)
+	c.Status(http.StatusOK)
+	if _, err := b.Backup(ctx, c.Writer); err != nil {
+		// Too late to change the status once the copy has started
+		logging.Error(ctx, "backup failed", "error", err.Error())
+	}
+}
+
+// Streams every game as JSON Lines, compressed with format=gzip
+func getAdminExport(c *gin.Context) {
+	ctx := c.Request.Context()
+	format := c.Query("format")
+	if len(format) < 1 {
+		format = game.SNAPSHOT_JSONL
+	}
+	file, ok := mapSnapshotFormatToFile[format]
+	if !ok {
+		handleError(c, game.ErrSnapshotFormat)
+		return
+	}
+
+	// The headers wait for the first game, so that an export failing before
+	// it can still be answered with an error
+	w := &startingWriter{w: c.Writer, start: func() {
+		c.Header("Content-Type", file.contentType)
+		c.Header("Content-Disposition", `attachment; filename="`+file.name+`"`)
+		c.Status(http.StatusOK)
+	}}
+	if _, err := game.Export(ctx, w, format); err != nil {
+		if !w.started {
+			handleError(c, err)
+			return
+		}
+		// Too late to change the status once the export has started
+		logging.Error(ctx, "export failed", "error", err.Error())
+		return
+	}
+	w.begin()
+}
+
+// Saves the games of an export, gzipped or not, replacing games with the
+// same id. With purge=true every other game is removed as well.
+func postAdminImport(c *gin.Context) {
+	n, err := game.Import(c.Request.Context(), c.Request.Body, c.Query("purge") == "true")
+	if handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"imported": n})
+}
+
+/////////////
+
+// Writer that calls start before anything is written
+type startingWriter struct {
+	w       io.Writer
+	start   func()
+	started bool
+}
+
+func (w *startingWriter) Write(b []byte) (int, error) {
+	w.begin()
+	return w.w.Write(b)
+}
+
+func (w *startingWriter) begin() {
+	if !w.started {
+		w.started = true
+		w.start()
+	}
+}
+
+var mapSnapshotFormatToFile = map[string]struct{ contentType, name string }{
+	game.SNAPSHOT_JSONL: {contentType: "application/x-ndjson", name: "wordle-snapshot.jsonl"},
+	game.SNAPSHOT_GZIP:  {contentType: "application/gzip", name: "wordle-snapshot.jsonl.gz"},
+}
+
+// Returns the filter described by the query parameters of an admin request
+func gameFilter(c *gin.Context, now time.Time) (game.Filter, error) {
+	filter := game.Filter{}
+
+	for _, name := range queryList(c, "status") {
+		status, err := game.ParseStatus(name)
+		if err != nil {
+			return filter, err
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	for _, name := range queryList(c, "mode") {
+		mode, err := game.ParseMode(name)
+		if err != nil {
+			return filter, err
+		}
+		filter.Modes = append(filter.Modes, mode)
+	}
+
+	if v := c.Query("olderThan"); len(v) > 0 {
+		age, err := time.ParseDuration(v)
+		if err != nil || age < 0 {
+			return filter, ErrInvalidAge
+		}
+		filter.CreatedBefore = now.Add(-age)
+	}
+	if v := c.Query("newerThan"); len(v) > 0 {
+		age, err := time.ParseDuration(v)
+		if err != nil || age < 0 {
+			return filter, ErrInvalidAge
+		}
+		filter.CreatedAfter = now.Add(-age)
+	}
+
+	return filter, nil
+}
+
+// Returns the values of a query parameter given more than once or as a comma
+// separated list
+func queryList(c *gin.Context, key string) []string {
+	values := []string{}
+	for _, v := range c.QueryArray(key) {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); len(s) > 0 {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aluance.io/wordleserver/internal/game"
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogLevel(t *testing.T) {
+	assert := assert.New(t)
+	defer logging.SetLevel("info")
+
+	router := setupRouter()
+
+	tests := []struct {
+		method string
+		key    string
+		level  string
+		status int
+		result string
+	}{
+		{method: "GET", key: "", status: http.StatusForbidden},
+		{method: "GET", key: TEST_CREATOR_KEY, status: http.StatusForbidden},
+		{method: "PUT", key: TEST_CREATOR_KEY, level: "debug", status: http.StatusForbidden},
+		{method: "GET", key: TEST_ADMIN_KEY, status: http.StatusOK, result: "info"},
+		{method: "PUT", key: TEST_ADMIN_KEY, level: "warn", status: http.StatusOK, result: "warn"},
+		{method: "GET", key: TEST_ADMIN_KEY, status: http.StatusOK, result: "warn"},
+		{method: "PUT", key: TEST_ADMIN_KEY, level: "loud", status: http.StatusBadRequest},
+		{method: "PUT", key: TEST_ADMIN_KEY, level: "DEBUG", status: http.StatusOK, result: "debug"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(test.method, "/admin/loglevel?level="+test.level, nil)
+		if len(test.key) > 0 {
+			req.Header.Set(API_KEY_HEADER, test.key)
+		}
+		router.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test)
+
+		if len(test.result) > 0 {
+			result := map[string]string{}
+			assert.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(test.result, result["level"])
+		}
+	}
+}
+
+func TestAdminGames(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	router := setupRouter()
+
+	// Create a game as a player
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/game?mode=timed", nil)
+	req.Header.Set(PLAYER_ID_HEADER, "p1")
+	req.Header.Set(API_KEY_HEADER, TEST_CREATOR_KEY)
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusOK, w.Code)
+	created := map[string]interface{}{}
+	require.NoError(json.Unmarshal(w.Body.Bytes(), &created))
+	gameId := created["id"].(string)
+	assert.NotContains(created, "secretWord")
+
+	tests := []struct {
+		method   string
+		path     string
+		key      string
+		status   int
+		contains []string
+		excludes []string
+	}{
+		{method: "GET", path: "/admin/games", status: http.StatusForbidden},
+		{method: "GET", path: "/admin/games/<ID>", key: TEST_CREATOR_KEY, status: http.StatusForbidden},
+		{method: "DELETE", path: "/admin/games?confirm=true", key: TEST_CREATOR_KEY, status: http.StatusForbidden},
+		{method: "GET", path: "/admin/games?mode=timed&newerThan=1m", key: TEST_ADMIN_KEY, status: http.StatusOK, contains: []string{"<ID>", `"owner":"player:p1"`}},
+		{method: "GET", path: "/admin/games?status=won,lost&newerThan=1m", key: TEST_ADMIN_KEY, status: http.StatusOK, excludes: []string{"<ID>"}},
+		{method: "GET", path: "/admin/games?olderThan=1h", key: TEST_ADMIN_KEY, status: http.StatusOK, excludes: []string{"<ID>"}},
+		{method: "GET", path: "/admin/games?status=paused", key: TEST_ADMIN_KEY, status: http.StatusBadRequest},
+		{method: "GET", path: "/admin/games?mode=blitz", key: TEST_ADMIN_KEY, status: http.StatusBadRequest},
+		{method: "GET", path: "/admin/games?olderThan=soon", key: TEST_ADMIN_KEY, status: http.StatusBadRequest},
+		{method: "GET", path: "/admin/games/<ID>", key: TEST_ADMIN_KEY, status: http.StatusOK, contains: []string{`"secretWord"`, `"owner":"player:p1"`}},
+		{method: "GET", path: "/admin/games/missing", key: TEST_ADMIN_KEY, status: http.StatusNotFound},
+		{method: "GET", path: "/admin/games/<ID>/events", key: TEST_ADMIN_KEY, status: http.StatusOK, contains: []string{`"type":"GameCreated"`}},
+		{method: "GET", path: "/admin/games/<ID>/events", key: TEST_CREATOR_KEY, status: http.StatusForbidden},
+		{method: "GET", path: "/admin/games/<ID>?at=1", key: TEST_ADMIN_KEY, status: http.StatusOK, contains: []string{`"gameStatus":"InPlay"`}},
+		{method: "GET", path: "/admin/games/<ID>?at=9", key: TEST_ADMIN_KEY, status: http.StatusBadRequest},
+		{method: "GET", path: "/admin/games/<ID>?at=first", key: TEST_ADMIN_KEY, status: http.StatusBadRequest},
+		{method: "POST", path: "/admin/games/<ID>/resign", key: TEST_ADMIN_KEY, status: http.StatusOK, contains: []string{`"gameStatus":"Resigned"`}},
+		{method: "POST", path: "/admin/games/<ID>/resign", key: TEST_ADMIN_KEY, status: http.StatusConflict},
+		{method: "GET", path: "/admin/games/<ID>/events", key: TEST_ADMIN_KEY, status: http.StatusOK, contains: []string{`"type":"GameResigned"`}},
+		{method: "GET", path: "/admin/stats", key: TEST_ADMIN_KEY, status: http.StatusOK, contains: []string{`"Resigned"`, `"Timed"`, `"GameResigned"`}},
+		{method: "DELETE", path: "/admin/games/<ID>", key: TEST_ADMIN_KEY, status: http.StatusNoContent},
+		{method: "DELETE", path: "/admin/games/<ID>", key: TEST_ADMIN_KEY, status: http.StatusNotFound},
+		{method: "DELETE", path: "/admin/games", key: TEST_ADMIN_KEY, status: http.StatusBadRequest},
+		{method: "DELETE", path: "/admin/games?confirm=true", key: TEST_ADMIN_KEY, status: http.StatusNoContent},
+		{method: "GET", path: "/admin/stats", key: TEST_ADMIN_KEY, status: http.StatusOK, contains: []string{`"games":0`}},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(test.method, strings.Replace(test.path, "<ID>", gameId, 1), nil)
+		if len(test.key) > 0 {
+			req.Header.Set(API_KEY_HEADER, test.key)
+		}
+		router.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.path, w.Body.String())
+
+		for _, s := range test.contains {
+			assert.Contains(w.Body.String(), strings.Replace(s, "<ID>", gameId, 1), test.path)
+		}
+		for _, s := range test.excludes {
+			assert.NotContains(w.Body.String(), strings.Replace(s, "<ID>", gameId, 1), test.path)
+		}
+	}
+}
+
+func TestAdminBackup(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	router := setupRouter()
+	get := func(key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/admin/backup", nil)
+		req.Header.Set(API_KEY_HEADER, key)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The memory store has nothing to copy from
+	assert.Equal(http.StatusForbidden, get(TEST_CREATOR_KEY).Code)
+	assert.Equal(http.StatusNotImplemented, get(TEST_ADMIN_KEY).Code)
+
+	dir := t.TempDir()
+	shutdown, err := store.Setup(ctx, store.STORE_BOLT, filepath.Join(dir, "wordle.bolt"), 0)
+	require.NoError(err)
+	t.Cleanup(func() { store.Use(nil) })
+	s, err := store.WordleStore()
+	require.NoError(err)
+	require.NoError(s.Save(ctx, "a", "backed up"))
+
+	w := get(TEST_ADMIN_KEY)
+	require.Equal(http.StatusOK, w.Code)
+	assert.Equal("application/octet-stream", w.Header().Get("Content-Type"))
+	backup := filepath.Join(dir, "backup.bolt")
+	require.NoError(os.WriteFile(backup, w.Body.Bytes(), 0600))
+	require.NoError(shutdown())
+
+	// The copy can be restored and used
+	restored := filepath.Join(dir, "restored.bolt")
+	require.NoError(store.RestoreBolt(ctx, backup, restored))
+	shutdown, err = store.Setup(ctx, store.STORE_BOLT, restored, 0)
+	require.NoError(err)
+	s, err = store.WordleStore()
+	require.NoError(err)
+	content, err := s.Load(ctx, "a")
+	require.NoError(err)
+	assert.Equal(`"backed up"`, string(content.(json.RawMessage)))
+	require.NoError(shutdown())
+}
+
+func TestAdminSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	router := setupRouter()
+	send := func(method string, path string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(API_KEY_HEADER, TEST_ADMIN_KEY)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(http.StatusNoContent, send("DELETE", "/admin/games?confirm=true", "").Code)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/game", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusOK, w.Code)
+
+	tests := []struct {
+		format      string
+		status      int
+		contentType string
+	}{
+		{format: "", status: http.StatusOK, contentType: "application/x-ndjson"},
+		{format: "jsonl", status: http.StatusOK, contentType: "application/x-ndjson"},
+		{format: "gzip", status: http.StatusOK, contentType: "application/gzip"},
+		{format: "xml", status: http.StatusBadRequest},
+	}
+
+	snapshots := []string{}
+	for _, test := range tests {
+		w := send("GET", "/admin/export?format="+test.format, "")
+		assert.Equal(test.status, w.Code, test.format)
+		if w.Code == http.StatusOK {
+			assert.Equal(test.contentType, w.Header().Get("Content-Type"), test.format)
+			snapshots = append(snapshots, w.Body.String())
+		}
+	}
+
+	// Both formats can be imported, replacing everything with purge=true
+	for _, snapshot := range snapshots {
+		require.Equal(http.StatusNoContent, send("DELETE", "/admin/games?confirm=true", "").Code)
+		w := send("POST", "/admin/import", snapshot)
+		require.Equal(http.StatusOK, w.Code)
+		assert.JSONEq(`{"imported":1}`, w.Body.String())
+	}
+	w = send("POST", "/admin/import?purge=true", snapshots[0])
+	require.Equal(http.StatusOK, w.Code)
+	w = send("GET", "/admin/stats", "")
+	assert.Contains(w.Body.String(), `"games":1`)
+
+	assert.Equal(http.StatusBadRequest, send("POST", "/admin/import?purge=true", "nonsense").Code)
+	w = send("GET", "/admin/stats", "")
+	assert.Contains(w.Body.String(), `"games":1`)
+}
+
+func TestAdminExportUnreadable(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	store.Use(store.NewMemoryStore())
+	t.Cleanup(func() { store.Use(nil) })
+	s, err := store.WordleStore()
+	require.NoError(err)
+	require.NoError(s.Save(ctx, "broken", []byte(`{"events":[]}`)))
+
+	// The export fails before any game is written, so the status tells
+	router := setupRouter()
+	for _, format := range []string{"jsonl", "gzip"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/admin/export?format="+format, nil)
+		req.Header.Set(API_KEY_HEADER, TEST_ADMIN_KEY)
+		router.ServeHTTP(w, req)
+		assert.Equal(http.StatusInternalServerError, w.Code, format)
+		assert.JSONEq(`{"error":"`+game.ErrUnreadable.Error()+`"}`, w.Body.String(), format)
+		assert.Empty(w.Header().Get("Content-Disposition"), format)
+	}
+}
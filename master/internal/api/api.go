@@ -1,30 +1,170 @@
 package api
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"math/rand"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/dictionary"
 	"aluance.io/wordleserver/internal/game"
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/metrics"
+	"aluance.io/wordleserver/internal/store"
+	"aluance.io/wordleserver/internal/tracing"
 	"github.com/gin-gonic/gin"
 )
 
 const API_RESPONSE_CONTENT_TYPE = "application/json; charset=utf-8"
 
-func Initialize() {
-	setupRouter()
+// Starts the server and serves requests until it is told to stop. Returns an
+// error when the server cannot start or fails while serving.
+func Initialize() error {
+	ctx := context.Background()
+	settings := config.Current()
+	if err := logging.SetLevel(settings.LogLevel); err != nil {
+		logging.Warn(ctx, "invalid log level", "level", settings.LogLevel)
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, settings.TraceExporter, settings.TraceEndpoint)
+	if err != nil {
+		logging.Error(ctx, "tracing not started", "exporter", settings.TraceExporter, "error", err.Error())
+	} else {
+		defer shutdownTracing(ctx)
+	}
+
+	shutdownStore, err := store.Setup(ctx, settings.Store, settings.StoreUrl(), settings.RedisTtl)
+	if err != nil {
+		logging.Error(ctx, "store not started", "backend", settings.Store, "error", err.Error())
+		return err
+	}
+	defer shutdownStore()
+	logging.Info(ctx, "store started", "backend", settings.Store)
+
+	if err := dictionary.Initialize(""); err != nil {
+		logging.Error(ctx, "dictionary not loaded", "error", err.Error())
+		return err
+	}
+	if len(os.Getenv(config.CONFIG_PUZZLE_KEY_ENV)) < 1 {
+		// Without a key of their own, servers disagree on the daily word and
+		// puzzle tokens, and both change when the server restarts
+		logging.Warn(ctx, "no puzzle key set, daily words and puzzles only last until restart", "env", config.CONFIG_PUZZLE_KEY_ENV)
+	}
+	if settings.Seed != 0 {
+		// Anyone who knows the seed knows every secret word
+		game.SetRand(rand.New(rand.NewSource(settings.Seed)))
+		logging.Warn(ctx, "games are seeded", "seed", settings.Seed)
+	}
+
+	stopSweeper := game.StartSweeper(config.CONFIG_GAME_SWEEPINTERVAL)
+	defer stopSweeper()
+
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", config.CONFIG_API_PORT))
+	if err != nil {
+		logging.Error(ctx, "cannot listen", "port", config.CONFIG_API_PORT, "error", err.Error())
+		return err
+	}
+	if settings.TlsEnabled() {
+		certs, err := newCertReloader(settings.TlsCert, settings.TlsKey, config.CONFIG_API_TLSCHECKINTERVAL)
+		if err != nil {
+			ln.Close()
+			logging.Error(ctx, "cannot load TLS certificate", "cert", settings.TlsCert, "key", settings.TlsKey, "error", err.Error())
+			return err
+		}
+		ln = tls.NewListener(ln, certs.tlsConfig())
+	}
+	logging.Info(ctx, "server started", "port", config.CONFIG_API_PORT, "tls", settings.TlsEnabled(), "commit", config.Commit())
+
+	stop, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err := serve(stop, ln, setupRouter(), config.CONFIG_API_SHUTDOWNDELAY, config.CONFIG_API_SHUTDOWNTIMEOUT); err != nil {
+		logging.Error(ctx, "server stopped", "error", err.Error())
+		return err
+	}
+	logging.Info(ctx, "server stopped")
+
+	return nil
+}
+
+// Serves requests until ctx is done, then reports the server as not ready for
+// delay before waiting up to timeout for the requests in flight
+func serve(ctx context.Context, ln net.Listener, handler http.Handler, delay time.Duration, timeout time.Duration) error {
+	shuttingDown.Store(false)
+	server := &http.Server{Handler: handler}
+
+	failed := make(chan error, 1)
+	go func() {
+		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			failed <- err
+		}
+	}()
+
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+
+	shuttingDown.Store(true)
+	logging.Info(context.Background(), "server shutting down")
+	time.Sleep(delay)
+
+	sctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return server.Shutdown(sctx)
 }
 
 func setupRouter() *gin.Engine {
-	router := gin.Default()
-	// TODO: Enable security | https://github.com/gin-contrib/secure
-	// router.Use(secure.New(secure.DefaultConfig()))
+	settings := config.Current()
+	router := gin.New()
+	if err := router.SetTrustedProxies(settings.TrustedProxies); err != nil {
+		logging.Error(context.Background(), "no proxies trusted", "proxies", settings.TrustedProxies, "error", err.Error())
+		router.SetTrustedProxies(nil)
+	}
+	router.Use(tracingMiddleware(), loggingMiddleware(), gin.Recovery(), metricsMiddleware())
+	router.Use(securityMiddleware(settings))
+	if corsHandler, err := corsMiddleware(settings); err != nil {
+		logging.Error(context.Background(), "CORS not enabled", "origins", settings.CorsOrigins, "error", err.Error())
+	} else if corsHandler != nil {
+		router.Use(corsHandler)
+	}
+	router.Use(rateLimitMiddleware(settings))
+
+	router.GET("/healthz", getHealthz)
+	router.GET("/readyz", getReadyz)
+	router.GET("/version", getVersion)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	admin := router.Group("/admin", adminOnly)
+	admin.GET("/loglevel", getLogLevel)
+	admin.PUT("/loglevel", putLogLevel)
+	admin.GET("/games", getAdminGames)
+	admin.DELETE("/games", deleteAdminGames)
+	admin.GET("/games/:id", getAdminGame)
+	admin.GET("/games/:id/events", getAdminEvents)
+	admin.DELETE("/games/:id", deleteAdminGame)
+	admin.POST("/games/:id/resign", postAdminResign)
+	admin.GET("/stats", getAdminStats)
+	admin.GET("/backup", getAdminBackup)
+	admin.GET("/export", getAdminExport)
+	admin.POST("/import", postAdminImport)
 
 	router.GET("/game", getGame)
+	router.GET("/games", getGames)
 	router.GET("/play", getPlay)
 	router.GET("/resign", getResign)
-
-	router.Run(fmt.Sprintf(":%d", config.CONFIG_API_PORT))
+	router.GET("/puzzle", getPuzzle)
+	router.GET("/game/:id/hint", getHint)
+	router.GET("/game/:id/analysis", getAnalysis)
+	router.GET("/game/:id/replay", getReplay)
 
 	return router
 }
@@ -32,13 +172,36 @@ func setupRouter() *gin.Engine {
 func getGame(c *gin.Context) {
 	gameId := c.Query("id")
 	startWord := c.Query("word")
+	token := c.Query("puzzle")
 
 	var g game.Game
 	var err error
 	if len(gameId) < 1 {
-		g, err = game.Create(startWord)
+		var options []game.Option
+		options, err = gameOptions(c)
+		if handleError(c, err) {
+			return
+		}
+		owner := clientOf(c)
+		if handleError(c, checkInPlay(c, owner)) {
+			return
+		}
+		options = append(options, game.WithOwner(owner))
+		if len(token) > 0 {
+			if len(startWord) > 0 {
+				handleError(c, ErrWordAndPuzzle)
+				return
+			}
+			g, err = game.CreateFromPuzzle(c.Request.Context(), token, options...)
+		} else {
+			if len(startWord) > 0 && !mayChooseWord(c) {
+				handleError(c, ErrChooseWord)
+				return
+			}
+			g, err = game.Create(c.Request.Context(), startWord, options...)
+		}
 	} else {
-		g, err = game.Retrieve(gameId)
+		g, err = game.Retrieve(c.Request.Context(), gameId)
 	}
 	if handleError(c, err) {
 		return
@@ -49,9 +212,74 @@ func getGame(c *gin.Context) {
 		return
 	}
 
+	setVersion(c, g)
 	c.Data(http.StatusOK, API_RESPONSE_CONTENT_TYPE, []byte(out))
 }
 
+// Returns the options for a new game from the query parameters
+func gameOptions(c *gin.Context) ([]game.Option, error) {
+	mode, err := game.ParseMode(c.Query("mode"))
+	if err != nil {
+		return nil, err
+	}
+	options := []game.Option{game.WithMode(mode)}
+
+	if hints, ok := c.GetQuery("hints"); ok {
+		enabled, err := strconv.ParseBool(hints)
+		if err != nil {
+			return nil, ErrInvalidHints
+		}
+		if !enabled {
+			options = append(options, game.WithoutHints())
+		}
+	}
+
+	return options, nil
+}
+
+// Lists the games of the client, newest first. The client is the player only
+// when named with an API key, so a player id alone lists the games of the
+// address, not those of the player. The status filter takes
+// comma separated names and since an RFC 3339 time; pass the next cursor of
+// a page to get the following one.
+func getGames(c *gin.Context) {
+	q := game.Query{Owner: clientOf(c), Cursor: c.Query("cursor"), Limit: config.CONFIG_API_PAGESIZE}
+	for _, name := range queryList(c, "status") {
+		status, err := game.ParseStatus(name)
+		if handleError(c, err) {
+			return
+		}
+		q.Statuses = append(q.Statuses, status)
+	}
+	if v := c.Query("since"); len(v) > 0 {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			handleError(c, ErrInvalidSince)
+			return
+		}
+		q.Since = since
+	}
+	if v := c.Query("limit"); len(v) > 0 {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			handleError(c, ErrInvalidLimit)
+			return
+		}
+		q.Limit = limit
+	}
+
+	list, next, err := game.Games(c.Request.Context(), q)
+	if handleError(c, err) {
+		return
+	}
+
+	out := gin.H{"games": list}
+	if len(next) > 0 {
+		out["next"] = next
+	}
+	c.JSON(http.StatusOK, out)
+}
+
 func getPlay(c *gin.Context) {
 	gameId := c.Query("id")
 	guessWord := c.Query("guess")
@@ -60,16 +288,32 @@ func getPlay(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
 		return
 	}
-	g, err := game.Retrieve(gameId)
+	ctx, key, err := idempotentContext(c)
+	if handleError(c, err) {
+		return
+	}
+	g, err := game.Retrieve(ctx, gameId)
 	if handleError(c, err) {
 		return
 	}
+	// A repeated play gets its original response whatever the version is now
+	if !g.Remembers(key) && handleError(c, checkVersion(c, g)) {
+		return
+	}
 
-	out, err := g.Play(guessWord)
+	out, err := g.Play(ctx, guessWord)
+	if err == game.ErrConflict && len(key) > 0 {
+		// The request this repeats may have been saved in the meantime
+		if latest, rerr := game.Retrieve(ctx, gameId); rerr == nil && latest.Remembers(key) {
+			g = latest
+			out, err = g.Play(ctx, guessWord)
+		}
+	}
 	if err != nil {
 		safeErrors := []error{game.ErrGameOver, game.ErrInvalidWord, game.ErrOutOfTurns}
 		for _, safe := range safeErrors {
 			if err == safe {
+				setVersion(c, g)
 				c.Data(http.StatusOK, API_RESPONSE_CONTENT_TYPE, []byte(out))
 				return
 			}
@@ -79,6 +323,7 @@ func getPlay(c *gin.Context) {
 		return
 	}
 
+	setVersion(c, g)
 	c.Data(http.StatusOK, API_RESPONSE_CONTENT_TYPE, []byte(out))
 }
 
@@ -89,12 +334,76 @@ func getResign(c *gin.Context) {
 		handleError(c, ErrInvalidId)
 		return
 	}
-	g, err := game.Retrieve(gameId)
+	g, err := game.Retrieve(c.Request.Context(), gameId)
+	if handleError(c, err) {
+		return
+	}
+	if handleError(c, checkVersion(c, g)) {
+		return
+	}
+
+	out, err := g.Resign(c.Request.Context())
+	if handleError(c, err) {
+		return
+	}
+	setVersion(c, g)
+
+	c.Data(http.StatusOK, API_RESPONSE_CONTENT_TYPE, []byte(out))
+}
+
+func getPuzzle(c *gin.Context) {
+	secretWord := c.Query("word")
+
+	token, err := game.NewPuzzle(c.Request.Context(), secretWord)
+	if handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"puzzle": token})
+}
+
+func getHint(c *gin.Context) {
+	gameId := c.Param("id")
+
+	g, err := game.Retrieve(c.Request.Context(), gameId)
 	if handleError(c, err) {
 		return
 	}
 
-	out, err := g.Resign()
+	out, err := g.Hint(c.Request.Context(), c.Query("strategy"))
+	if handleError(c, err) {
+		return
+	}
+	setVersion(c, g)
+
+	c.Data(http.StatusOK, API_RESPONSE_CONTENT_TYPE, []byte(out))
+}
+
+func getAnalysis(c *gin.Context) {
+	gameId := c.Param("id")
+
+	g, err := game.Retrieve(c.Request.Context(), gameId)
+	if handleError(c, err) {
+		return
+	}
+
+	out, err := g.Analyze(c.Request.Context())
+	if handleError(c, err) {
+		return
+	}
+
+	c.Data(http.StatusOK, API_RESPONSE_CONTENT_TYPE, []byte(out))
+}
+
+func getReplay(c *gin.Context) {
+	gameId := c.Param("id")
+
+	g, err := game.Retrieve(c.Request.Context(), gameId)
+	if handleError(c, err) {
+		return
+	}
+
+	out, err := g.Replay(c.Request.Context())
 	if handleError(c, err) {
 		return
 	}
@@ -104,7 +413,11 @@ func getResign(c *gin.Context) {
 
 func handleError(c *gin.Context, err error) bool {
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status, ok := mapErrorToStatus[err]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return true
 	}
 
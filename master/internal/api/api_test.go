@@ -3,16 +3,36 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 
 	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/logging"
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+const TEST_ADMIN_KEY = "test-admin-key"
+const TEST_CREATOR_KEY = "test-creator-key"
+
+func TestMain(m *testing.M) {
+	s := config.Load()
+	s.ApiKeys = map[string]string{TEST_ADMIN_KEY: ROLE_ADMIN, TEST_CREATOR_KEY: ROLE_CREATOR}
+	// Limits have tests of their own
+	s.RateLimitIp, s.RateLimitPlayer, s.RateLimitGame = config.RateLimit{}, config.RateLimit{}, config.RateLimit{}
+	s.MaxInPlay = 0
+	config.Set(s)
+	logging.SetOutput(io.Discard)
+	gin.SetMode(gin.TestMode)
+
+	os.Exit(m.Run())
+}
+
 func TestGetGame(t *testing.T) {
 	tests := []struct {
 		id     string
@@ -40,6 +60,7 @@ func TestGetGame(t *testing.T) {
 		}
 		if len(test.word) > 0 {
 			q.Add("word", test.word)
+			req.Header.Set(API_KEY_HEADER, TEST_ADMIN_KEY)
 		}
 		req.URL.RawQuery = q.Encode()
 
@@ -61,6 +82,47 @@ func TestGetGame(t *testing.T) {
 	}
 }
 
+func TestGetGameMode(t *testing.T) {
+	tests := []struct {
+		mode   string
+		result string
+		status int
+	}{
+		{mode: "", result: "Classic", status: http.StatusOK},
+		{mode: "timed", result: "Timed", status: http.StatusOK},
+		{mode: "speedrun", result: "SpeedRun", status: http.StatusOK},
+		{mode: "marathon", status: http.StatusBadRequest},
+	}
+
+	assert := assert.New(t)
+	router := setupRouter()
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/game", nil)
+		assert.NoError(err)
+
+		q := req.URL.Query()
+		if len(test.mode) > 0 {
+			q.Add("mode", test.mode)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		router.ServeHTTP(w, req)
+		if !assert.Equal(test.status, w.Code, test.mode) || w.Code != http.StatusOK {
+			continue
+		}
+
+		mapResult := map[string]interface{}{}
+		assert.NoError(json.Unmarshal(w.Body.Bytes(), &mapResult))
+		assert.Equal(test.result, mapResult["mode"])
+		assert.Contains(mapResult, "elapsedSeconds")
+		if test.result != "Classic" {
+			assert.Contains(mapResult, "timeRemainingSeconds")
+		}
+	}
+}
+
 func TestGetPlay(t *testing.T) {
 	tests := []struct {
 		id     string
@@ -117,6 +179,7 @@ func TestGetPlay(t *testing.T) {
 	q := req.URL.Query()
 	q.Add("word", startWord)
 	req.URL.RawQuery = q.Encode()
+	req.Header.Set(API_KEY_HEADER, TEST_ADMIN_KEY)
 
 	router.ServeHTTP(w, req)
 	require.Equal(http.StatusOK, w.Code)
@@ -164,6 +227,7 @@ func TestGetPlay(t *testing.T) {
 	q = req.URL.Query()
 	q.Add("word", startWord)
 	req.URL.RawQuery = q.Encode()
+	req.Header.Set(API_KEY_HEADER, TEST_ADMIN_KEY)
 
 	router.ServeHTTP(w, req)
 	require.Equal(http.StatusOK, w.Code)
@@ -245,3 +309,414 @@ func TestGetResign(t *testing.T) {
 	}
 	assert.EqualValues("Resigned", mapResult["gameStatus"])
 }
+
+func TestGetHint(t *testing.T) {
+	tests := []struct {
+		hints    string
+		strategy string
+		status   int
+	}{
+		{hints: "", strategy: "", status: http.StatusOK},
+		{hints: "true", strategy: "minmax", status: http.StatusOK},
+		{hints: "", strategy: "random", status: http.StatusBadRequest},
+		{hints: "false", strategy: "", status: http.StatusForbidden},
+	}
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	router := setupRouter()
+
+	for _, test := range tests {
+		// Create game
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/game", nil)
+		require.NoError(err)
+		q := req.URL.Query()
+		if len(test.hints) > 0 {
+			q.Add("hints", test.hints)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		router.ServeHTTP(w, req)
+		require.Equal(http.StatusOK, w.Code)
+
+		mapResult := map[string]interface{}{}
+		require.NoError(json.Unmarshal(w.Body.Bytes(), &mapResult))
+		gameId := mapResult["id"].(string)
+
+		// Ask for a hint
+		w = httptest.NewRecorder()
+		req, err = http.NewRequest("GET", fmt.Sprintf("/game/%s/hint", gameId), nil)
+		require.NoError(err)
+		q = req.URL.Query()
+		if len(test.strategy) > 0 {
+			q.Add("strategy", test.strategy)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		router.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, w.Body.String())
+		if w.Code != http.StatusOK {
+			continue
+		}
+		assert.Contains(w.Result().Header["Content-Type"], API_RESPONSE_CONTENT_TYPE)
+
+		mapResult = map[string]interface{}{}
+		require.NoError(json.Unmarshal(w.Body.Bytes(), &mapResult))
+		testElements := []string{"id", "candidatesRemaining", "suggestions", "hintsUsed"}
+		for _, elem := range testElements {
+			assert.Contains(mapResult, elem)
+		}
+
+		// A second hint straight away is rate limited
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(http.StatusTooManyRequests, w.Code)
+	}
+
+	// Bad hints option
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/game?hints=maybe", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(http.StatusBadRequest, w.Code)
+}
+
+func TestGetAnalysis(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	router := setupRouter()
+
+	// Create game
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/game?word=happy", nil)
+	req.Header.Set(API_KEY_HEADER, TEST_ADMIN_KEY)
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusOK, w.Code)
+
+	mapResult := map[string]interface{}{}
+	require.NoError(json.Unmarshal(w.Body.Bytes(), &mapResult))
+	gameId := mapResult["id"].(string)
+
+	// Not available during play
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/game/%s/analysis", gameId), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(http.StatusConflict, w.Code)
+
+	// Win the game
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/play?id=%s&guess=happy", gameId), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/game/%s/analysis", gameId), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Contains(w.Result().Header["Content-Type"], API_RESPONSE_CONTENT_TYPE)
+
+	mapResult = map[string]interface{}{}
+	require.NoError(json.Unmarshal(w.Body.Bytes(), &mapResult))
+	testElements := []string{"id", "gameStatus", "secretWord", "attempts"}
+	for _, elem := range testElements {
+		assert.Contains(mapResult, elem)
+	}
+	assert.Len(mapResult["attempts"], 1)
+}
+
+func TestGetReplay(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	router := setupRouter()
+
+	// Create game
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/game?word=happy", nil)
+	req.Header.Set(API_KEY_HEADER, TEST_ADMIN_KEY)
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusOK, w.Code)
+
+	mapResult := map[string]interface{}{}
+	require.NoError(json.Unmarshal(w.Body.Bytes(), &mapResult))
+	gameId := mapResult["id"].(string)
+
+	// Not available during play
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/game/%s/replay", gameId), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(http.StatusConflict, w.Code)
+
+	for _, guess := range []string{"puppy", "happy"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", fmt.Sprintf("/play?id=%s&guess=%s", gameId, guess), nil)
+		router.ServeHTTP(w, req)
+		require.Equal(http.StatusOK, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/game/%s/replay", gameId), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Contains(w.Result().Header["Content-Type"], API_RESPONSE_CONTENT_TYPE)
+
+	mapResult = map[string]interface{}{}
+	require.NoError(json.Unmarshal(w.Body.Bytes(), &mapResult))
+	testElements := []string{"id", "gameStatus", "secretWord", "candidates", "steps"}
+	for _, elem := range testElements {
+		assert.Contains(mapResult, elem)
+	}
+	assert.Len(mapResult["steps"], 2)
+}
+
+func TestGetPuzzle(t *testing.T) {
+	tests := []struct {
+		word   string
+		status int
+	}{
+		{word: "", status: http.StatusBadRequest},
+		{word: "blagu", status: http.StatusBadRequest},
+		{word: "happy", status: http.StatusOK},
+	}
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	router := setupRouter()
+
+	for _, test := range tests {
+		// Create puzzle
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/puzzle", nil)
+		require.NoError(err)
+		q := req.URL.Query()
+		q.Add("word", test.word)
+		req.URL.RawQuery = q.Encode()
+
+		router.ServeHTTP(w, req)
+		if !assert.Equal(test.status, w.Code, test.word) || w.Code != http.StatusOK {
+			continue
+		}
+
+		mapResult := map[string]interface{}{}
+		require.NoError(json.Unmarshal(w.Body.Bytes(), &mapResult))
+		token := mapResult["puzzle"].(string)
+		assert.NotContains(strings.ToLower(token), test.word)
+
+		// Start a game from it
+		w = httptest.NewRecorder()
+		req, err = http.NewRequest("GET", "/game", nil)
+		require.NoError(err)
+		q = req.URL.Query()
+		q.Add("puzzle", token)
+		req.URL.RawQuery = q.Encode()
+
+		router.ServeHTTP(w, req)
+		assert.Equal(http.StatusOK, w.Code)
+		assert.NotContains(strings.ToLower(w.Body.String()), test.word)
+
+		mapResult = map[string]interface{}{}
+		require.NoError(json.Unmarshal(w.Body.Bytes(), &mapResult))
+		assert.Equal(true, mapResult["puzzle"])
+
+		// A word cannot be combined with a puzzle
+		q.Add("word", test.word)
+		req.URL.RawQuery = q.Encode()
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(http.StatusBadRequest, w.Code)
+	}
+
+	// Broken token
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/game?puzzle=broken", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(http.StatusBadRequest, w.Code)
+}
+
+func TestGetGameWord(t *testing.T) {
+	tests := []struct {
+		policy string
+		key    string
+		mode   string
+		status int
+	}{
+		{policy: ROLE_CREATOR, key: "", status: http.StatusForbidden},
+		{policy: ROLE_CREATOR, key: "wrong", status: http.StatusForbidden},
+		{policy: ROLE_CREATOR, key: TEST_CREATOR_KEY, status: http.StatusOK},
+		{policy: ROLE_CREATOR, key: TEST_ADMIN_KEY, status: http.StatusOK},
+		{policy: ROLE_ADMIN, key: TEST_CREATOR_KEY, status: http.StatusForbidden},
+		{policy: ROLE_ADMIN, key: TEST_ADMIN_KEY, status: http.StatusOK},
+		{policy: config.CONFIG_CUSTOM_WORDS_OPEN, key: "", status: http.StatusOK},
+		{policy: config.CONFIG_CUSTOM_WORDS_OPEN, key: "", mode: "daily", status: http.StatusBadRequest},
+	}
+
+	assert := assert.New(t)
+
+	router := setupRouter()
+	saved := config.Current()
+	defer config.Set(saved)
+
+	for _, test := range tests {
+		s := config.Current()
+		s.CustomWords = test.policy
+		config.Set(s)
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/game", nil)
+		assert.NoError(err)
+		q := req.URL.Query()
+		q.Add("word", "happy")
+		if len(test.mode) > 0 {
+			q.Add("mode", test.mode)
+		}
+		req.URL.RawQuery = q.Encode()
+		if len(test.key) > 0 {
+			req.Header.Set(API_KEY_HEADER, test.key)
+		}
+
+		router.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, fmt.Sprintf("%+v: %s", test, w.Body.String()))
+	}
+}
+
+func TestGetGameDaily(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	router := setupRouter()
+	saved := config.Current()
+	defer config.Set(saved)
+
+	for _, hide := range []bool{true, false} {
+		s := config.Current()
+		s.HideDailySecret = hide
+		config.Set(s)
+
+		// Create and resign a daily game
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/game?mode=daily", nil)
+		router.ServeHTTP(w, req)
+		require.Equal(http.StatusOK, w.Code)
+
+		mapResult := map[string]interface{}{}
+		require.NoError(json.Unmarshal(w.Body.Bytes(), &mapResult))
+		assert.Equal("Daily", mapResult["mode"])
+		gameId := mapResult["id"].(string)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", fmt.Sprintf("/resign?id=%s", gameId), nil)
+		router.ServeHTTP(w, req)
+		require.Equal(http.StatusOK, w.Code)
+
+		mapResult = map[string]interface{}{}
+		require.NoError(json.Unmarshal(w.Body.Bytes(), &mapResult))
+		assert.Equal("Resigned", mapResult["gameStatus"])
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", fmt.Sprintf("/game/%s/analysis", gameId), nil)
+		router.ServeHTTP(w, req)
+
+		if hide {
+			assert.NotContains(mapResult, "secretWord")
+			assert.Equal(http.StatusConflict, w.Code)
+		} else {
+			assert.Contains(mapResult, "secretWord")
+			assert.Equal(http.StatusOK, w.Code)
+		}
+	}
+}
+
+func TestGetGames(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	router := setupRouter()
+
+	ids := []string{}
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/game", nil)
+		req.Header.Set(PLAYER_ID_HEADER, "lister")
+		req.Header.Set(API_KEY_HEADER, TEST_CREATOR_KEY)
+		router.ServeHTTP(w, req)
+		require.Equal(http.StatusOK, w.Code)
+		out := map[string]interface{}{}
+		require.NoError(json.Unmarshal(w.Body.Bytes(), &out))
+		ids = append([]string{out["id"].(string)}, ids...)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/resign?id=%s", ids[0]), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusOK, w.Code)
+
+	list := func(player string, query string) (int, []string, string) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/games?"+query, nil)
+		req.Header.Set(PLAYER_ID_HEADER, player)
+		req.Header.Set(API_KEY_HEADER, TEST_CREATOR_KEY)
+		router.ServeHTTP(w, req)
+
+		out := struct {
+			Games []map[string]interface{} `json:"games"`
+			Next  string                   `json:"next"`
+		}{}
+		json.Unmarshal(w.Body.Bytes(), &out)
+		got := []string{}
+		for _, g := range out.Games {
+			got = append(got, g["id"].(string))
+			assert.NotContains(g, "secretWord")
+			assert.NotContains(g, "owner")
+		}
+		return w.Code, got, out.Next
+	}
+
+	tests := []struct {
+		player string
+		query  string
+		status int
+		result []string
+	}{
+		{player: "lister", status: http.StatusOK, result: ids},
+		{player: "someone-else", status: http.StatusOK, result: []string{}},
+		{player: "lister", query: "status=inplay", status: http.StatusOK, result: ids[1:]},
+		{player: "lister", query: "status=Resigned,Won", status: http.StatusOK, result: ids[:1]},
+		{player: "lister", query: "since=2000-01-01T00:00:00Z", status: http.StatusOK, result: ids},
+		{player: "lister", query: "since=2999-01-01T00:00:00Z", status: http.StatusOK, result: []string{}},
+		{player: "lister", query: "status=paused", status: http.StatusBadRequest},
+		{player: "lister", query: "since=yesterday", status: http.StatusBadRequest},
+		{player: "lister", query: "limit=none", status: http.StatusBadRequest},
+		{player: "lister", query: "cursor=!!", status: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		code, got, _ := list(test.player, test.query)
+		assert.Equal(test.status, code, test.query)
+		if test.status == http.StatusOK {
+			assert.Equal(test.result, got, test.query)
+		}
+	}
+
+	// Naming the player without a key lists the games of the address
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/games", nil)
+	req.RemoteAddr = "10.2.0.1:1234"
+	req.Header.Set(PLAYER_ID_HEADER, "lister")
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusOK, w.Code)
+	for _, id := range ids {
+		assert.NotContains(w.Body.String(), id)
+	}
+
+	// Follow the cursor
+	code, got, next := list("lister", "limit=2")
+	assert.Equal(http.StatusOK, code)
+	assert.Equal(ids[:2], got)
+	require.NotEmpty(next)
+	code, got, next = list("lister", "limit=2&cursor="+next)
+	assert.Equal(http.StatusOK, code)
+	assert.Equal(ids[2:], got)
+	assert.Empty(next)
+}
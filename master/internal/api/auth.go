@@ -0,0 +1,52 @@
+package api
+
+import (
+	"crypto/subtle"
+
+	"aluance.io/wordleserver/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+const API_KEY_HEADER = "X-API-Key"
+
+// Roles that can be granted to API keys. Admins can do anything creators can.
+const ROLE_ADMIN = "admin"
+const ROLE_CREATOR = "creator"
+
+// Returns the role granted to the API key of the request, if any
+func roleOf(c *gin.Context) string {
+	key := c.GetHeader(API_KEY_HEADER)
+	if len(key) < 1 {
+		return ""
+	}
+
+	role := ""
+	for k, r := range config.Current().ApiKeys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			role = r
+		}
+	}
+
+	return role
+}
+
+// Reports whether the request was made with a key granting role
+func hasRole(c *gin.Context, role string) bool {
+	r := roleOf(c)
+	if len(r) < 1 {
+		return false
+	}
+
+	return r == ROLE_ADMIN || r == role
+}
+
+// Reports whether the request may create a game with a chosen secret word.
+// Anyone can still share a chosen word through a puzzle token.
+func mayChooseWord(c *gin.Context) bool {
+	policy := config.Current().CustomWords
+	if policy == config.CONFIG_CUSTOM_WORDS_OPEN {
+		return true
+	}
+
+	return hasRole(c, policy)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"aluance.io/wordleserver/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoles(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		key     string
+		role    string
+		creator bool
+		admin   bool
+	}{
+		{key: "", role: "", creator: false, admin: false},
+		{key: "unknown", role: "", creator: false, admin: false},
+		{key: TEST_CREATOR_KEY, role: ROLE_CREATOR, creator: true, admin: false},
+		{key: TEST_ADMIN_KEY, role: ROLE_ADMIN, creator: true, admin: true},
+	}
+
+	for _, test := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest("GET", "/", nil)
+		if len(test.key) > 0 {
+			c.Request.Header.Set(API_KEY_HEADER, test.key)
+		}
+
+		assert.Equal(test.role, roleOf(c), test.key)
+		assert.Equal(test.creator, hasRole(c, ROLE_CREATOR), test.key)
+		assert.Equal(test.admin, hasRole(c, ROLE_ADMIN), test.key)
+		assert.Equal(test.creator, mayChooseWord(c), test.key)
+	}
+
+	// An empty role never matches
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	assert.False(hasRole(c, ""))
+
+	// Open policy
+	saved := config.Current()
+	defer config.Set(saved)
+	s := config.Current()
+	s.CustomWords = config.CONFIG_CUSTOM_WORDS_OPEN
+	config.Set(s)
+	assert.True(mayChooseWord(c))
+}
@@ -1,7 +1,70 @@
 package api
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+
+	"aluance.io/wordleserver/internal/game"
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/puzzle"
+	"aluance.io/wordleserver/internal/solver"
+	"aluance.io/wordleserver/internal/store"
+)
 
 var (
-	ErrInvalidId = errors.New("invalid id")
+	ErrInvalidId     = errors.New("invalid id")
+	ErrInvalidHints  = errors.New("invalid hints option")
+	ErrWordAndPuzzle = errors.New("word and puzzle cannot be combined")
+	ErrChooseWord    = errors.New("not allowed to choose the secret word")
+	ErrAdminOnly     = errors.New("only allowed with an admin key")
+	ErrRateLimited   = errors.New("too many requests")
+	ErrTooManyGames  = errors.New("too many games in play")
+	ErrCorsOrigin    = errors.New("invalid CORS origin")
+	ErrTlsConfig     = errors.New("invalid TLS certificate or key")
+	ErrInvalidAge    = errors.New("invalid age filter")
+	ErrConfirmPurge  = errors.New("purge must be confirmed")
+	ErrInvalidSince  = errors.New("invalid since time")
+	ErrInvalidLimit  = errors.New("invalid page size")
+	ErrStaleVersion  = errors.New("game version does not match If-Match")
+	ErrInvalidKey    = errors.New("invalid idempotency key")
+	ErrNoBackup      = errors.New("store cannot be backed up while in use")
 )
+
+// Errors that are reported with a status other than 500
+var mapErrorToStatus = map[error]int{
+	ErrInvalidId:           http.StatusBadRequest,
+	ErrInvalidHints:        http.StatusBadRequest,
+	ErrWordAndPuzzle:       http.StatusBadRequest,
+	solver.ErrStrategy:     http.StatusBadRequest,
+	game.ErrInvalidMode:    http.StatusBadRequest,
+	game.ErrWordLength:     http.StatusBadRequest,
+	game.ErrInvalidWord:    http.StatusBadRequest,
+	game.ErrDailyWord:      http.StatusBadRequest,
+	puzzle.ErrInvalidToken: http.StatusBadRequest,
+	logging.ErrLevel:       http.StatusBadRequest,
+	game.ErrInvalidStatus:  http.StatusBadRequest,
+	ErrInvalidAge:          http.StatusBadRequest,
+	ErrConfirmPurge:        http.StatusBadRequest,
+	ErrInvalidSince:        http.StatusBadRequest,
+	ErrInvalidLimit:        http.StatusBadRequest,
+	store.ErrInvalidCursor: http.StatusBadRequest,
+	game.ErrEventIndex:     http.StatusBadRequest,
+	game.ErrSnapshot:       http.StatusBadRequest,
+	game.ErrSnapshotFormat: http.StatusBadRequest,
+	game.ErrNotFound:       http.StatusNotFound,
+	game.ErrGameOver:       http.StatusConflict,
+	game.ErrGameInPlay:     http.StatusConflict,
+	game.ErrPeriodOpen:     http.StatusConflict,
+	game.ErrConflict:       http.StatusConflict,
+	ErrStaleVersion:        http.StatusPreconditionFailed,
+	ErrInvalidKey:          http.StatusBadRequest,
+	game.ErrKeyReused:      http.StatusUnprocessableEntity,
+	ErrChooseWord:          http.StatusForbidden,
+	ErrAdminOnly:           http.StatusForbidden,
+	game.ErrHintsDisabled:  http.StatusForbidden,
+	game.ErrHintLimit:      http.StatusTooManyRequests,
+	game.ErrHintCooldown:   http.StatusTooManyRequests,
+	ErrRateLimited:         http.StatusTooManyRequests,
+	ErrTooManyGames:        http.StatusTooManyRequests,
+	ErrNoBackup:            http.StatusNotImplemented,
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"runtime"
+	"sync/atomic"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/dictionary"
+	"aluance.io/wordleserver/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// Set once the server starts shutting down, so that no new traffic is sent
+var shuttingDown atomic.Bool
+
+// Reports that the process is alive
+func getHealthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Reports whether the server can take requests: the dictionary is loaded, the
+// store can be reached and the server is not shutting down
+func getReadyz(c *gin.Context) {
+	checks := gin.H{"dictionary": "ok", "store": "ok", "shutdown": "ok"}
+	ready := true
+
+	if err := dictionary.Initialize(""); err != nil {
+		checks["dictionary"], ready = err.Error(), false
+	} else if dictionary.Size() < 1 {
+		checks["dictionary"], ready = "empty", false
+	}
+
+	if s, err := store.WordleStore(); err != nil {
+		checks["store"], ready = err.Error(), false
+	} else if err := s.Ping(c.Request.Context()); err != nil {
+		checks["store"], ready = err.Error(), false
+	}
+
+	if shuttingDown.Load() {
+		checks["shutdown"], ready = "shutting down", false
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
+
+// Reports what was built and how it is configured. API keys are counted,
+// never shown.
+func getVersion(c *gin.Context) {
+	settings := config.Current()
+
+	c.JSON(http.StatusOK, gin.H{
+		"commit":    config.Commit(),
+		"goVersion": runtime.Version(),
+		"config": gin.H{
+			"wordLength":       config.CONFIG_GAME_WORDLENGTH,
+			"maxAttempts":      config.CONFIG_GAME_MAXATTEMPTS,
+			"maxValidAttempts": config.CONFIG_GAME_MAXVALIDATTEMPTS,
+			"dictionary":       config.CONFIG_DICTIONARY_FILENAME,
+			"dictionarySize":   dictionary.Size(),
+			"customWords":      settings.CustomWords,
+			"hideDailySecret":  settings.HideDailySecret,
+			"apiKeys":          len(settings.ApiKeys),
+			"logLevel":         settings.LogLevel,
+			"traceExporter":    settings.TraceExporter,
+		},
+	})
+}
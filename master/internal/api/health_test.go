@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"aluance.io/wordleserver/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	assert := assert.New(t)
+
+	router := setupRouter()
+	defer shuttingDown.Store(false)
+
+	tests := []struct {
+		path         string
+		shuttingDown bool
+		status       int
+		result       string
+	}{
+		{path: "/healthz", status: http.StatusOK, result: "ok"},
+		{path: "/readyz", status: http.StatusOK, result: "ready"},
+		{path: "/healthz", shuttingDown: true, status: http.StatusOK, result: "ok"},
+		{path: "/readyz", shuttingDown: true, status: http.StatusServiceUnavailable, result: "not ready"},
+	}
+
+	for _, test := range tests {
+		shuttingDown.Store(test.shuttingDown)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", test.path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test)
+
+		result := map[string]interface{}{}
+		assert.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(test.result, result["status"], test)
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/version", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code)
+
+	result := map[string]interface{}{}
+	assert.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(config.Commit(), result["commit"])
+	assert.Equal(runtime.Version(), result["goVersion"])
+	assert.Contains(result, "config")
+
+	// API keys are never shown
+	assert.NotContains(w.Body.String(), TEST_ADMIN_KEY)
+	assert.NotContains(w.Body.String(), TEST_CREATOR_KEY)
+}
+
+func TestServe(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	url := "http://" + ln.Addr().String() + "/readyz"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- serve(ctx, ln, setupRouter(), 200*time.Millisecond, time.Second)
+	}()
+
+	resp, err := http.Get(url)
+	require.NoError(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusOK, resp.StatusCode)
+
+	// Readiness fails while the server waits to shut down
+	cancel()
+	require.Eventually(shuttingDown.Load, time.Second, 5*time.Millisecond)
+	resp, err = http.Get(url)
+	require.NoError(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+
+	select {
+	case err := <-done:
+		assert.NoError(err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+	shuttingDown.Store(false)
+}
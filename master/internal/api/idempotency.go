@@ -0,0 +1,31 @@
+package api
+
+import (
+	"context"
+
+	"aluance.io/wordleserver/internal/game"
+	"github.com/gin-gonic/gin"
+)
+
+const IDEMPOTENCY_KEY_HEADER = "Idempotency-Key"
+const IDEMPOTENCY_KEY_PARAM = "idempotencyKey"
+
+// Longest idempotency key accepted
+const IDEMPOTENCY_KEY_MAXLEN = 64
+
+// Returns the context to play with, carrying the idempotency key of the
+// request from its header or query parameter if it has one
+func idempotentContext(c *gin.Context) (context.Context, string, error) {
+	key := c.GetHeader(IDEMPOTENCY_KEY_HEADER)
+	if len(key) < 1 {
+		key = c.Query(IDEMPOTENCY_KEY_PARAM)
+	}
+	if len(key) < 1 {
+		return c.Request.Context(), "", nil
+	}
+	if len(key) > IDEMPOTENCY_KEY_MAXLEN {
+		return nil, "", ErrInvalidKey
+	}
+
+	return game.WithIdempotencyKey(c.Request.Context(), key), key, nil
+}
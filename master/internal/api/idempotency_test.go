@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotentPlay(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/game?word=happy", nil)
+	req.Header.Set(API_KEY_HEADER, TEST_ADMIN_KEY)
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusOK, w.Code)
+	out := map[string]interface{}{}
+	require.NoError(json.Unmarshal(w.Body.Bytes(), &out))
+	gameId := out["id"].(string)
+
+	tests := []struct {
+		guess    string
+		header   string
+		param    string
+		ifMatch  string
+		status   int
+		attempts float64
+	}{
+		{guess: "puppy", header: "k1", ifMatch: `"1"`, status: http.StatusOK, attempts: 1},
+		{guess: "puppy", header: "k1", ifMatch: `"1"`, status: http.StatusOK, attempts: 1}, // retried with the old version
+		{guess: "puppy", param: "k1", status: http.StatusOK, attempts: 1},
+		{guess: "bless", param: "k1", status: http.StatusUnprocessableEntity},
+		{guess: "bless", param: "k2", ifMatch: `"1"`, status: http.StatusPreconditionFailed},
+		{guess: "bless", param: "k2", status: http.StatusOK, attempts: 2},
+		{guess: "bless", header: strings.Repeat("k", IDEMPOTENCY_KEY_MAXLEN+1), status: http.StatusBadRequest},
+		{guess: "bless", status: http.StatusOK, attempts: 3},
+	}
+
+	for i, test := range tests {
+		w := httptest.NewRecorder()
+		path := fmt.Sprintf("/play?id=%s&guess=%s", gameId, test.guess)
+		if len(test.param) > 0 {
+			path += "&" + IDEMPOTENCY_KEY_PARAM + "=" + test.param
+		}
+		req, _ := http.NewRequest("GET", path, nil)
+		if len(test.header) > 0 {
+			req.Header.Set(IDEMPOTENCY_KEY_HEADER, test.header)
+		}
+		if len(test.ifMatch) > 0 {
+			req.Header.Set(IF_MATCH_HEADER, test.ifMatch)
+		}
+		router.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, i, w.Body.String())
+
+		if test.status == http.StatusOK {
+			out := map[string]interface{}{}
+			require.NoError(json.Unmarshal(w.Body.Bytes(), &out))
+			assert.Equal(test.attempts, out["attemptsUsed"], i)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"time"
+
+	"aluance.io/wordleserver/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/xid"
+)
+
+const REQUEST_ID_HEADER = "X-Request-ID"
+
+// Longest request id accepted from a client before a new one is made
+const requestIdMaxLength = 64
+
+// Routes polled by probes and scrapers, only logged at debug level
+var quietRoutes = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+// Gives every request an id, carried by its context into the game and store
+// packages and echoed in the response, and logs the request once it is done.
+// Secret words in the query are redacted.
+func loggingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		id := c.GetHeader(REQUEST_ID_HEADER)
+		if len(id) < 1 || len(id) > requestIdMaxLength {
+			id = xid.New().String()
+		}
+		ctx := logging.WithRequestId(c.Request.Context(), id)
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(REQUEST_ID_HEADER, id)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		args := []interface{}{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"query", logging.RedactQuery(c.Request.URL.Query()).Encode(),
+			"status", status,
+			"duration", time.Since(start).String(),
+			"clientIp", c.ClientIP(),
+		}
+		if status >= 500 {
+			logging.Error(ctx, "request", args...)
+		} else if quietRoutes[c.FullPath()] {
+			logging.Debug(ctx, "request", args...)
+		} else {
+			logging.Info(ctx, "request", args...)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"aluance.io/wordleserver/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	logging.SetOutput(&buf)
+	defer logging.SetOutput(io.Discard)
+	require.NoError(logging.SetLevel("debug"))
+	defer logging.SetLevel("info")
+
+	router := setupRouter()
+
+	// A request id given by the client is kept
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/game?word=happy", nil)
+	req.Header.Set(API_KEY_HEADER, TEST_ADMIN_KEY)
+	req.Header.Set(REQUEST_ID_HEADER, "client-id")
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusOK, w.Code)
+	assert.Equal("client-id", w.Header().Get(REQUEST_ID_HEADER))
+
+	// Every line of the request carries its id, and the secret word is never logged
+	assert.NotContains(strings.ToLower(buf.String()), "happy")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.NotEmpty(lines)
+	messages := []string{}
+	for _, line := range lines {
+		record := map[string]interface{}{}
+		require.NoError(json.Unmarshal([]byte(line), &record), line)
+		assert.Equal("client-id", record["requestId"], line)
+		messages = append(messages, record["msg"].(string))
+	}
+	assert.Contains(messages, "game created")
+	assert.Contains(messages, "request")
+
+	// Otherwise a new id is made for every request
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/game", nil)
+	router.ServeHTTP(w, req)
+	first := w.Header().Get(REQUEST_ID_HEADER)
+	assert.NotEmpty(first)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/game", nil)
+	router.ServeHTTP(w, req)
+	assert.NotEmpty(w.Header().Get(REQUEST_ID_HEADER))
+	assert.NotEqual(first, w.Header().Get(REQUEST_ID_HEADER))
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"aluance.io/wordleserver/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Records the count and duration of every request. Requests are labelled with
+// the route pattern rather than the path, so that game ids do not create new
+// series.
+func metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if len(route) < 1 {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+
+		metrics.HttpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HttpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"aluance.io/wordleserver/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	router := setupRouter()
+
+	tests := []struct {
+		path   string
+		route  string
+		status int
+	}{
+		{path: "/game", route: "/game", status: http.StatusOK},
+		{path: "/play", route: "/play", status: http.StatusBadRequest},
+		{path: "/game/abc/hint", route: "/game/:id/hint", status: http.StatusInternalServerError},
+		{path: "/nowhere", route: "unmatched", status: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		counter := metrics.HttpRequests.WithLabelValues("GET", test.route, fmt.Sprint(test.status))
+		before := testutil.ToFloat64(counter)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", test.path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.path)
+
+		assert.Equal(before+1, testutil.ToFloat64(counter), test.path)
+	}
+}
+
+func TestGetMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/game", nil)
+	router.ServeHTTP(w, req)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/metrics", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code)
+
+	for _, name := range []string{"wordle_http_requests_total", "wordle_http_request_duration_seconds", "wordle_games_created_total", "wordle_store_size", "wordle_dictionary_size"} {
+		assert.Contains(w.Body.String(), name)
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/game"
+	"aluance.io/wordleserver/internal/metrics"
+	"aluance.io/wordleserver/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// Header naming the player, used to share limits across addresses. It is
+// only believed from clients with an API key, as anyone can send it.
+const PLAYER_ID_HEADER = "X-Player-ID"
+
+// Longest player id accepted, longer ones are ignored
+const playerIdMaxLength = 64
+
+// Refuses requests over the limits of the client address, of the player and
+// of the game, with 429 and a Retry-After header. Each router has buckets of
+// its own.
+func rateLimitMiddleware(settings config.Settings) gin.HandlerFunc {
+	byIp := ratelimit.New(settings.RateLimitIp.PerMinute, settings.RateLimitIp.Burst)
+	byPlayer := ratelimit.New(settings.RateLimitPlayer.PerMinute, settings.RateLimitPlayer.Burst)
+	byGame := ratelimit.New(settings.RateLimitGame.PerMinute, settings.RateLimitGame.Burst)
+
+	return func(c *gin.Context) {
+		if quietRoutes[c.FullPath()] {
+			return
+		}
+
+		checks := []struct {
+			scope   string
+			limiter *ratelimit.Limiter
+			key     string
+		}{
+			{scope: "ip", limiter: byIp, key: c.ClientIP()},
+			{scope: "player", limiter: byPlayer, key: playerOf(c)},
+			{scope: "game", limiter: byGame, key: gameIdOf(c)},
+		}
+
+		now := time.Now()
+		for _, check := range checks {
+			if len(check.key) < 1 {
+				continue
+			}
+			if ok, wait := check.limiter.Allow(check.key, now); !ok {
+				metrics.RateLimited.WithLabelValues(check.scope).Inc()
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				handleError(c, ErrRateLimited)
+				c.Abort()
+				return
+			}
+		}
+	}
+}
+
+// Returns the client that owns the games created by the request: the player
+// if named by a client with an API key, otherwise the client address
+func clientOf(c *gin.Context) string {
+	if p := playerOf(c); len(p) > 0 {
+		return "player:" + p
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+// Refuses a new game when the client already has as many games in play as
+// allowed
+func checkInPlay(c *gin.Context, owner string) error {
+	max := config.Current().MaxInPlay
+	if max < 1 {
+		return nil
+	}
+
+	count, err := game.CountInPlay(c.Request.Context(), owner)
+	if err != nil {
+		return err
+	}
+	if count >= max {
+		metrics.RateLimited.WithLabelValues("inplay").Inc()
+		return ErrTooManyGames
+	}
+
+	return nil
+}
+
+/////////////
+
+// Returns the player named by the request, if the client is trusted to name
+// one
+func playerOf(c *gin.Context) string {
+	p := c.GetHeader(PLAYER_ID_HEADER)
+	if len(p) > playerIdMaxLength || len(roleOf(c)) < 1 {
+		return ""
+	}
+
+	return p
+}
+
+func gameIdOf(c *gin.Context) string {
+	if id := c.Param("id"); len(id) > 0 {
+		return id
+	}
+
+	return c.Query("id")
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Sets the limits for the routers made while the test runs
+func withLimits(t *testing.T, fn func(s *config.Settings)) {
+	saved := config.Current()
+	t.Cleanup(func() { config.Set(saved) })
+
+	s := config.Current()
+	fn(&s)
+	config.Set(s)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	withLimits(t, func(s *config.Settings) {
+		s.RateLimitIp = config.RateLimit{PerMinute: 1, Burst: 4}
+		s.RateLimitPlayer = config.RateLimit{PerMinute: 1, Burst: 2}
+		s.RateLimitGame = config.RateLimit{PerMinute: 1, Burst: 1}
+		s.TrustedProxies = []string{"10.0.0.9"}
+	})
+
+	// Only a trusted proxy names the client, and only a client with a key
+	// names the player
+	tests := []struct {
+		name      string
+		path      string
+		ip        string
+		forwarded string
+		player    string
+		key       string
+		status    int
+		scope     string
+	}{
+		{name: "game id", path: "/play?id=game1", ip: "10.0.0.1", status: http.StatusInternalServerError},
+		{name: "game id again", path: "/play?id=game1", ip: "10.0.0.1", status: http.StatusTooManyRequests, scope: "game"},
+		{name: "game id in path", path: "/game/game2/hint", ip: "10.0.0.1", status: http.StatusInternalServerError},
+		{name: "ip exhausted", path: "/healthz", ip: "10.0.0.1", status: http.StatusOK},
+		{name: "ip", path: "/version", ip: "10.0.0.1", status: http.StatusOK},
+		{name: "ip again", path: "/version", ip: "10.0.0.1", status: http.StatusTooManyRequests, scope: "ip"},
+		{name: "probes", path: "/readyz", ip: "10.0.0.1", status: http.StatusOK},
+		{name: "player", path: "/version", ip: "10.0.0.2", player: "p1", key: TEST_CREATOR_KEY, status: http.StatusOK},
+		{name: "player other ip", path: "/version", ip: "10.0.0.3", player: "p1", key: TEST_CREATOR_KEY, status: http.StatusOK},
+		{name: "player again", path: "/version", ip: "10.0.0.4", player: "p1", key: TEST_CREATOR_KEY, status: http.StatusTooManyRequests, scope: "player"},
+		{name: "other player", path: "/version", ip: "10.0.0.4", player: "p2", key: TEST_CREATOR_KEY, status: http.StatusOK},
+		{name: "player without key", path: "/version", ip: "10.0.0.5", player: "p1", status: http.StatusOK},
+		{name: "forwarded", path: "/version", ip: "10.0.0.6", forwarded: "1.1.1.1", status: http.StatusOK},
+		{name: "forwarded other", path: "/version", ip: "10.0.0.6", forwarded: "1.1.1.2", player: "p3", status: http.StatusOK},
+		{name: "forwarded another", path: "/version", ip: "10.0.0.6", forwarded: "1.1.1.3", player: "p4", status: http.StatusOK},
+		{name: "forwarded yet another", path: "/version", ip: "10.0.0.6", forwarded: "1.1.1.4", player: "p5", status: http.StatusOK},
+		{name: "forwarded again", path: "/version", ip: "10.0.0.6", forwarded: "1.1.1.5", player: "p6", status: http.StatusTooManyRequests, scope: "ip"},
+		{name: "trusted proxy", path: "/version", ip: "10.0.0.9", forwarded: "1.1.1.6", status: http.StatusOK},
+		{name: "trusted proxy again", path: "/version", ip: "10.0.0.9", forwarded: "1.1.1.6", status: http.StatusOK},
+	}
+
+	router := setupRouter()
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		var before float64
+		if len(test.scope) > 0 {
+			before = testutil.ToFloat64(metrics.RateLimited.WithLabelValues(test.scope))
+		}
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", test.path, nil)
+		req.RemoteAddr = test.ip + ":1234"
+		if len(test.forwarded) > 0 {
+			req.Header.Set("X-Forwarded-For", test.forwarded)
+			req.Header.Set("X-Real-IP", test.forwarded)
+		}
+		if len(test.player) > 0 {
+			req.Header.Set(PLAYER_ID_HEADER, test.player)
+		}
+		if len(test.key) > 0 {
+			req.Header.Set(API_KEY_HEADER, test.key)
+		}
+		router.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.name)
+
+		if test.status == http.StatusTooManyRequests {
+			assert.Equal("60", w.Header().Get("Retry-After"), test.name)
+			assert.Equal(before+1, testutil.ToFloat64(metrics.RateLimited.WithLabelValues(test.scope)), test.name)
+		} else {
+			assert.Empty(w.Header().Get("Retry-After"), test.name)
+		}
+	}
+}
+
+func TestMaxInPlay(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	withLimits(t, func(s *config.Settings) {
+		s.MaxInPlay = 2
+	})
+	router := setupRouter()
+
+	newGame := func(ip string, player string, key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/game", nil)
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set("X-Forwarded-For", player)
+		req.Header.Set(PLAYER_ID_HEADER, player)
+		req.Header.Set(API_KEY_HEADER, key)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	player := "inplay-" + t.Name()
+	ids := []string{}
+	for i := 0; i < 2; i++ {
+		w := newGame("10.1.0.1", player, TEST_CREATOR_KEY)
+		require.Equal(http.StatusOK, w.Code)
+		result := map[string]interface{}{}
+		require.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+		ids = append(ids, result["id"].(string))
+	}
+
+	// The cap is per client
+	assert.Equal(http.StatusTooManyRequests, newGame("10.1.0.2", player, TEST_CREATOR_KEY).Code)
+	assert.Equal(http.StatusOK, newGame("10.1.0.1", player+"-other", TEST_CREATOR_KEY).Code)
+
+	// Without a key the cap is on the address, whatever player is named
+	for i := 0; i < 2; i++ {
+		assert.Equal(http.StatusOK, newGame("10.1.0.3", fmt.Sprintf("%s-%d", player, i), "").Code)
+	}
+	assert.Equal(http.StatusTooManyRequests, newGame("10.1.0.3", player+"-new", "").Code)
+
+	// Finished games do not count
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/resign?id="+ids[0], nil)
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusOK, w.Code)
+	assert.Equal(http.StatusOK, newGame("10.1.0.1", player, TEST_CREATOR_KEY).Code)
+
+	// The owner is not shown
+	w = newGame("10.1.0.1", player+"-shown", TEST_CREATOR_KEY)
+	require.Equal(http.StatusOK, w.Code)
+	assert.NotContains(w.Body.String(), "owner")
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"aluance.io/wordleserver/internal/config"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// Headers that browsers may send to and read from the API
+var corsAllowHeaders = []string{"Content-Type", API_KEY_HEADER, PLAYER_ID_HEADER, REQUEST_ID_HEADER, IF_MATCH_HEADER, IDEMPOTENCY_KEY_HEADER, "traceparent", "tracestate"}
+var corsExposeHeaders = []string{REQUEST_ID_HEADER, ETAG_HEADER, "Retry-After"}
+
+// Sets the headers asking browsers to keep to HTTPS, not to sniff content
+// types, not to frame the responses and not to load anything from them. HSTS
+// is only sent over HTTPS, including behind a proxy ending TLS.
+func securityMiddleware(settings config.Settings) gin.HandlerFunc {
+	hsts := ""
+	if settings.HstsMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d; includeSubDomains", settings.HstsMaxAge)
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "no-referrer")
+		if len(settings.ContentSecurityPolicy) > 0 {
+			h.Set("Content-Security-Policy", settings.ContentSecurityPolicy)
+		}
+		if len(hsts) > 0 && (c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https") {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+	}
+}
+
+// Returns the middleware letting the allowed origins call the API from a
+// browser, or nil when no origin is allowed. Requests from other origins are
+// refused with 403.
+func corsMiddleware(settings config.Settings) (gin.HandlerFunc, error) {
+	if len(settings.CorsOrigins) < 1 {
+		return nil, nil
+	}
+
+	cfg := cors.Config{
+		AllowOrigins:  settings.CorsOrigins,
+		AllowMethods:  []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
+		AllowHeaders:  corsAllowHeaders,
+		ExposeHeaders: corsExposeHeaders,
+		MaxAge:        config.CONFIG_API_CORSMAXAGE,
+	}
+	for _, origin := range settings.CorsOrigins {
+		if origin == "*" {
+			cfg.AllowAllOrigins, cfg.AllowOrigins = true, nil
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, ErrCorsOrigin
+	}
+
+	return cors.New(cfg), nil
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"aluance.io/wordleserver/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	withLimits(t, func(s *config.Settings) {
+		s.HstsMaxAge = 600
+		s.ContentSecurityPolicy = "default-src 'none'"
+	})
+	router := setupRouter()
+
+	tests := []struct {
+		tls       bool
+		forwarded string
+		hsts      string
+	}{
+		{tls: false, hsts: ""},
+		{tls: true, hsts: "max-age=600; includeSubDomains"},
+		{forwarded: "https", hsts: "max-age=600; includeSubDomains"},
+		{forwarded: "http", hsts: ""},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/healthz", nil)
+		if test.tls {
+			req.TLS = &tls.ConnectionState{}
+		}
+		if len(test.forwarded) > 0 {
+			req.Header.Set("X-Forwarded-Proto", test.forwarded)
+		}
+		router.ServeHTTP(w, req)
+
+		assert.Equal("nosniff", w.Header().Get("X-Content-Type-Options"))
+		assert.Equal("DENY", w.Header().Get("X-Frame-Options"))
+		assert.Equal("no-referrer", w.Header().Get("Referrer-Policy"))
+		assert.Equal("default-src 'none'", w.Header().Get("Content-Security-Policy"))
+		assert.Equal(test.hsts, w.Header().Get("Strict-Transport-Security"), test)
+	}
+
+	// Headers are also set on errors
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/nowhere", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(http.StatusNotFound, w.Code)
+	assert.Equal("nosniff", w.Header().Get("X-Content-Type-Options"))
+}
+
+func TestCorsMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	withLimits(t, func(s *config.Settings) {
+		s.CorsOrigins = []string{"https://wordle.example.com"}
+	})
+	router := setupRouter()
+
+	tests := []struct {
+		method string
+		origin string
+		status int
+		allow  string
+	}{
+		{method: "GET", origin: "", status: http.StatusOK, allow: ""},
+		{method: "GET", origin: "https://wordle.example.com", status: http.StatusOK, allow: "https://wordle.example.com"},
+		{method: "GET", origin: "https://evil.example.com", status: http.StatusForbidden, allow: ""},
+		{method: "OPTIONS", origin: "https://wordle.example.com", status: http.StatusNoContent, allow: "https://wordle.example.com"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(test.method, "/healthz", nil)
+		if len(test.origin) > 0 {
+			req.Header.Set("Origin", test.origin)
+		}
+		if test.method == "OPTIONS" {
+			req.Header.Set("Access-Control-Request-Method", "GET")
+			req.Header.Set("Access-Control-Request-Headers", API_KEY_HEADER)
+		}
+		router.ServeHTTP(w, req)
+
+		assert.Equal(test.status, w.Code, test)
+		assert.Equal(test.allow, w.Header().Get("Access-Control-Allow-Origin"), test)
+		if test.method == "OPTIONS" {
+			assert.Contains(w.Header().Get("Access-Control-Allow-Headers"), http.CanonicalHeaderKey(API_KEY_HEADER))
+		}
+	}
+}
+
+func TestCorsOrigins(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		origins []string
+		enabled bool
+		err     error
+	}{
+		{origins: nil, enabled: false, err: nil},
+		{origins: []string{"*"}, enabled: true, err: nil},
+		{origins: []string{"http://localhost:3000", "https://wordle.example.com"}, enabled: true, err: nil},
+		{origins: []string{"wordle.example.com"}, enabled: false, err: ErrCorsOrigin},
+	}
+
+	for _, test := range tests {
+		handler, err := corsMiddleware(config.Settings{CorsOrigins: test.origins})
+		assert.Equal(test.err, err, test.origins)
+		assert.Equal(test.enabled, handler != nil, test.origins)
+	}
+}
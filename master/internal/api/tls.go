@@ -0,0 +1,95 @@
+package api
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// Serves the certificate and key found at two paths, loading them again when
+// either file changes, so that renewed certificates are used without a
+// restart. The files are checked at most once per interval, during a
+// handshake.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+// Returns a reloader for the certificate and key, which must load
+func newCertReloader(certFile string, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+	if err := r.reload(time.Now()); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Returns the TLS configuration serving the certificate of the reloader
+func (r *certReloader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+// Returns the current certificate. A certificate that fails to load is
+// skipped and the previous one is kept.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.checked) >= r.interval {
+		r.reload(now)
+	}
+
+	return r.cert, nil
+}
+
+/////////////
+
+// Loads the files if they changed since they were last loaded. Called with
+// the lock held, or before the reloader is shared.
+func (r *certReloader) reload(now time.Time) error {
+	r.checked = now
+
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	if r.cert != nil && !modTime.After(r.modTime) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return ErrTlsConfig
+	}
+	r.cert = &cert
+	r.modTime = modTime
+
+	return nil
+}
+
+func latestModTime(paths ...string) (time.Time, error) {
+	var latest time.Time
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return latest, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
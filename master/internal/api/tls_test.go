@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Writes a self-signed certificate for localhost with the serial number and
+// returns the paths of the certificate and key
+func writeCert(t *testing.T, dir string, serial int64) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+
+	// Make sure the files look newer than any written before
+	later := time.Now().Add(time.Duration(serial) * time.Second)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+
+	return certFile, keyFile
+}
+
+func serialOf(t *testing.T, cert *tls.Certificate) int64 {
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.SerialNumber.Int64()
+}
+
+func TestCertReloader(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := t.TempDir()
+
+	// Missing and broken files are refused at start up
+	_, err := newCertReloader(filepath.Join(dir, "none.crt"), filepath.Join(dir, "none.key"), 0)
+	assert.Error(err)
+	broken := filepath.Join(dir, "broken.pem")
+	require.NoError(os.WriteFile(broken, []byte("not a certificate"), 0600))
+	_, err = newCertReloader(broken, broken, 0)
+	assert.ErrorIs(err, ErrTlsConfig)
+
+	certFile, keyFile := writeCert(t, dir, 1)
+	r, err := newCertReloader(certFile, keyFile, 0)
+	require.NoError(err)
+	cert, err := r.GetCertificate(nil)
+	require.NoError(err)
+	assert.Equal(int64(1), serialOf(t, cert))
+
+	// A renewed certificate is picked up
+	writeCert(t, dir, 2)
+	cert, err = r.GetCertificate(nil)
+	require.NoError(err)
+	assert.Equal(int64(2), serialOf(t, cert))
+
+	// A broken renewal keeps the previous certificate
+	later := time.Now().Add(time.Minute)
+	require.NoError(os.WriteFile(certFile, []byte("half written"), 0600))
+	require.NoError(os.Chtimes(certFile, later, later))
+	cert, err = r.GetCertificate(nil)
+	require.NoError(err)
+	assert.Equal(int64(2), serialOf(t, cert))
+
+	// Files are not checked again within the interval
+	r.interval = time.Hour
+	writeCert(t, dir, 3)
+	cert, _ = r.GetCertificate(nil)
+	assert.Equal(int64(2), serialOf(t, cert))
+}
+
+func TestServeTls(t *testing.T) {
+	require := require.New(t)
+
+	certFile, keyFile := writeCert(t, t.TempDir(), 1)
+	certs, err := newCertReloader(certFile, keyFile, 0)
+	require.NoError(err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- serve(ctx, tls.NewListener(ln, certs.tlsConfig()), setupRouter(), 0, time.Second)
+	}()
+	defer func() {
+		cancel()
+		<-done
+		shuttingDown.Store(false)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + ln.Addr().String() + "/healthz")
+	require.NoError(err)
+	resp.Body.Close()
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.NotNil(resp.TLS)
+	require.Equal(int64(1), resp.TLS.PeerCertificates[0].SerialNumber.Int64())
+}
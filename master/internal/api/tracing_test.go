@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	savedProvider, savedPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	defer func() {
+		otel.SetTracerProvider(savedProvider)
+		otel.SetTextMapPropagator(savedPropagator)
+	}()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	router := setupRouter()
+
+	// The trace of the client is continued
+	traceId := "4bf92f3577b34da6a3ce929d0e0e4736"
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/game", nil)
+	req.Header.Set("traceparent", "00-"+traceId+"-00f067aa0ba902b7-01")
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusOK, w.Code)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		assert.Equal(traceId, span.SpanContext().TraceID().String(), span.Name())
+		spans[span.Name()] = span
+	}
+	for _, name := range []string{"GET /game", "game.Create", "dictionary.GenerateWord", "store.CompareAndSwap"} {
+		require.Contains(spans, name)
+	}
+	assert.Equal(spans["GET /game"].SpanContext().SpanID(), spans["game.Create"].Parent().SpanID())
+	assert.Equal(spans["game.Create"].SpanContext().SpanID(), spans["store.CompareAndSwap"].Parent().SpanID())
+	assert.Equal(spans["game.Create"].SpanContext().SpanID(), spans["dictionary.GenerateWord"].Parent().SpanID())
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	"aluance.io/wordleserver/internal/game"
+	"github.com/gin-gonic/gin"
+)
+
+const ETAG_HEADER = "ETag"
+const IF_MATCH_HEADER = "If-Match"
+
+// Sets the ETag of the response to the version of the game
+func setVersion(c *gin.Context, g game.Game) {
+	c.Header(ETAG_HEADER, etagOf(g.Version()))
+}
+
+// Refuses to change a game when the request has an If-Match header and none of
+// its tags is the current version. The store still refuses the change if the
+// game is saved by someone else in the meantime.
+func checkVersion(c *gin.Context, g game.Game) error {
+	header := c.GetHeader(IF_MATCH_HEADER)
+	if len(header) < 1 {
+		return nil
+	}
+
+	current := etagOf(g.Version())
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current {
+			return nil
+		}
+	}
+	return ErrStaleVersion
+}
+
+/////////////
+
+func etagOf(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIfMatch(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/game?word=happy", nil)
+	req.Header.Set(API_KEY_HEADER, TEST_ADMIN_KEY)
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusOK, w.Code)
+	assert.Equal(`"1"`, w.Header().Get(ETAG_HEADER))
+	out := map[string]interface{}{}
+	require.NoError(json.Unmarshal(w.Body.Bytes(), &out))
+	gameId := out["id"].(string)
+
+	tests := []struct {
+		path    string
+		ifMatch string
+		status  int
+		etag    string
+	}{
+		{path: "/play?id=%s&guess=puppy", ifMatch: `"1"`, status: http.StatusOK, etag: `"2"`},
+		{path: "/play?id=%s&guess=bless", ifMatch: `"1"`, status: http.StatusPreconditionFailed},
+		{path: "/play?id=%s&guess=bless", ifMatch: `W/"2"`, status: http.StatusOK, etag: `"3"`},
+		{path: "/play?id=%s&guess=zzzzz", ifMatch: `"1", "3"`, status: http.StatusOK, etag: `"4"`},
+		{path: "/play?id=%s&guess=peppy", status: http.StatusOK, etag: `"5"`},
+		{path: "/game?id=%s", status: http.StatusOK, etag: `"5"`},
+		{path: "/resign?id=%s", ifMatch: `"4"`, status: http.StatusPreconditionFailed},
+		{path: "/resign?id=%s", ifMatch: "*", status: http.StatusOK, etag: `"6"`},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf(test.path, gameId), nil)
+		if len(test.ifMatch) > 0 {
+			req.Header.Set(IF_MATCH_HEADER, test.ifMatch)
+		}
+		router.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.path, w.Body.String())
+		assert.Equal(test.etag, w.Header().Get(ETAG_HEADER), test.path)
+	}
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"aluance.io/wordleserver/internal/game"
+	"aluance.io/wordleserver/internal/solver"
+)
+
+const API_KEY_HEADER = "X-API-Key"
+const PLAYER_ID_HEADER = "X-Player-ID"
+const ETAG_HEADER = "ETag"
+const IF_MATCH_HEADER = "If-Match"
+
+// Time allowed for each request to the server
+const CLIENT_TIMEOUT = 10 * time.Second
+
+// Client of the game API
+type Client struct {
+	BaseUrl string
+	ApiKey  string // sent when set, needed to choose the word
+	Player  string // owner of the games created, the client address if empty
+	http    *http.Client
+}
+
+// Game as reported by the server
+type Game struct {
+	Id                   string                `json:"id"`
+	Status               game.GameStatusType   `json:"gameStatus"`
+	Mode                 game.GameMode         `json:"mode"`
+	SecretWord           string                `json:"secretWord"`
+	Attempts             []*game.WordleAttempt `json:"attempts"`
+	ValidAttempts        int                   `json:"validAttempts"`
+	TimedOut             bool                  `json:"timedOut"`
+	Puzzle               bool                  `json:"puzzle"`
+	HintsDisabled        bool                  `json:"hintsDisabled"`
+	HintsUsed            int                   `json:"hintsUsed"`
+	TimeRemainingSeconds float64               `json:"timeRemainingSeconds"`
+	Version              string                `json:"-"` // ETag of the response
+}
+
+// Suggestions for the next guess
+type Hint struct {
+	CandidatesRemaining int                 `json:"candidatesRemaining"`
+	Suggestions         []solver.Suggestion `json:"suggestions"`
+	HintsLeft           int                 `json:"hintsLeft"`
+}
+
+// Returns a client of the server at baseUrl
+func New(baseUrl, apiKey, player string) *Client {
+	return &Client{
+		BaseUrl: strings.TrimSuffix(baseUrl, "/"),
+		ApiKey:  apiKey,
+		Player:  player,
+		http:    &http.Client{Timeout: CLIENT_TIMEOUT},
+	}
+}
+
+// Starts a game in the given mode, or from a puzzle token when one is given
+func (c *Client) NewGame(ctx context.Context, mode string, puzzle string) (*Game, error) {
+	q := url.Values{}
+	if len(mode) > 0 {
+		q.Set("mode", mode)
+	}
+	if len(puzzle) > 0 {
+		q.Set("puzzle", puzzle)
+	}
+	return c.game(ctx, "/game", q, "")
+}
+
+// Returns the game with the given id
+func (c *Client) Game(ctx context.Context, id string) (*Game, error) {
+	return c.game(ctx, "/game", url.Values{"id": {id}}, "")
+}
+
+// Plays a guess. Words that are not in the dictionary are not an error: the
+// game comes back with the guess marked as not valid.
+func (c *Client) Play(ctx context.Context, g *Game, word string) (*Game, error) {
+	return c.game(ctx, "/play", url.Values{"id": {g.Id}, "guess": {word}}, g.Version)
+}
+
+// Gives up the game
+func (c *Client) Resign(ctx context.Context, g *Game) (*Game, error) {
+	return c.game(ctx, "/resign", url.Values{"id": {g.Id}}, g.Version)
+}
+
+// Asks for suggestions for the next guess. Hints count against the game, so
+// the version of g is brought up to date.
+func (c *Client) Hint(ctx context.Context, g *Game) (*Hint, error) {
+	h := &Hint{}
+	header, err := c.get(ctx, "/game/"+url.PathEscape(g.Id)+"/hint", nil, "", h)
+	if err != nil {
+		return nil, err
+	}
+	g.Version = header.Get(ETAG_HEADER)
+	return h, nil
+}
+
+/////////////
+
+func (c *Client) game(ctx context.Context, path string, q url.Values, version string) (*Game, error) {
+	g := &Game{}
+	header, err := c.get(ctx, path, q, version, g)
+	if err != nil {
+		return nil, err
+	}
+	g.Version = header.Get(ETAG_HEADER)
+	return g, nil
+}
+
+// Sends a GET request and decodes the response into out. Error responses
+// become errors with the message from the server.
+func (c *Client) get(ctx context.Context, path string, q url.Values, version string, out interface{}) (http.Header, error) {
+	u := c.BaseUrl + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.ApiKey) > 0 {
+		req.Header.Set(API_KEY_HEADER, c.ApiKey)
+	}
+	if len(c.Player) > 0 {
+		req.Header.Set(PLAYER_ID_HEADER, c.Player)
+	}
+	if len(version) > 0 {
+		req.Header.Set(IF_MATCH_HEADER, version)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &failure) != nil || len(failure.Error) < 1 {
+			return nil, ErrResponse
+		}
+		return nil, errors.New(failure.Error)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return nil, ErrResponse
+	}
+
+	return resp.Header, nil
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"aluance.io/wordleserver/internal/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGame = `{"id":"g1","gameStatus":"InPlay","mode":"Timed","attempts":[
+	{"tryWord":"lucky","isValidWord":true,"tryResult":["Grey","Yellow","Grey","Grey","Green"]}],
+	"validAttempts":1,"timeRemainingSeconds":42}`
+
+// Returns a server answering with canned responses and the requests it was
+// sent. There is no game with the id "missing".
+func newTestServer(t *testing.T, responses map[string]string) (*httptest.Server, *[]*http.Request) {
+	requests := []*http.Request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		body, ok := responses[r.URL.Path]
+		if !ok || r.URL.Query().Get("id") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"game not found"}`))
+			return
+		}
+		w.Header().Set(ETAG_HEADER, `"3"`)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestClient(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	server, requests := newTestServer(t, map[string]string{
+		"/game":         testGame,
+		"/play":         testGame,
+		"/resign":       testGame,
+		"/game/g1/hint": `{"candidatesRemaining":2,"suggestions":[{"word":"happy"}],"hintsLeft":1}`,
+		"/garbled":      "not json",
+	})
+	c := New(server.URL+"/", "key", "p1")
+
+	g, err := c.NewGame(ctx, "timed", "")
+	require.NoError(err)
+	assert.Equal("g1", g.Id)
+	assert.Equal(game.Timed, g.Mode)
+	assert.Equal(`"3"`, g.Version)
+	require.Len(g.Attempts, 1)
+	assert.Equal([]game.LetterHint{game.Grey, game.Yellow, game.Grey, game.Grey, game.Green}, g.Attempts[0].TryResult)
+	assert.Equal(42.0, g.TimeRemainingSeconds)
+
+	_, err = c.Play(ctx, g, "happy")
+	require.NoError(err)
+	_, err = c.Resign(ctx, g)
+	require.NoError(err)
+	g.Version = `"2"`
+	h, err := c.Hint(ctx, g)
+	require.NoError(err)
+	assert.Equal(`"3"`, g.Version)
+	assert.Equal(2, h.CandidatesRemaining)
+	assert.Equal("happy", h.Suggestions[0].Word)
+
+	tests := []struct {
+		path    string
+		query   string
+		ifMatch string
+	}{
+		{path: "/game", query: "mode=timed"},
+		{path: "/play", query: "guess=happy&id=g1", ifMatch: `"3"`},
+		{path: "/resign", query: "id=g1", ifMatch: `"3"`},
+		{path: "/game/g1/hint"},
+	}
+	require.Len(*requests, len(tests))
+	for i, test := range tests {
+		r := (*requests)[i]
+		assert.Equal(test.path, r.URL.Path)
+		assert.Equal(test.query, r.URL.RawQuery, test.path)
+		assert.Equal(test.ifMatch, r.Header.Get(IF_MATCH_HEADER), test.path)
+		assert.Equal("key", r.Header.Get(API_KEY_HEADER), test.path)
+		assert.Equal("p1", r.Header.Get(PLAYER_ID_HEADER), test.path)
+	}
+
+	// Errors carry the message from the server
+	_, err = c.Game(ctx, "missing")
+	assert.EqualError(err, "game not found")
+	_, err = c.get(ctx, "/garbled", nil, "", &Game{})
+	assert.Equal(ErrResponse, err)
+}
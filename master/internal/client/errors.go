@@ -0,0 +1,9 @@
+package client
+
+import "errors"
+
+var (
+	ErrResponse   = errors.New("unexpected response from server")
+	ErrNoGame     = errors.New("no game in play")
+	ErrBadCommand = errors.New("unknown command, :help lists them")
+)
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"aluance.io/wordleserver/internal/game"
+)
+
+const PROMPT = "> "
+
+// Names of the environment variables the flags default to
+const CLIENT_SERVER_ENV = "WORDLE_SERVER"
+const CLIENT_API_KEY_ENV = "WORDLE_API_KEY"
+const CLIENT_PLAYER_ENV = "WORDLE_PLAYER"
+
+// Server played against unless another is chosen
+const CLIENT_DEFAULT_SERVER = "http://localhost:8080"
+
+const help = `Type a word to guess it, or one of
+  :hint           suggest the next guess
+  :resign         give up the game
+  :new [mode]     start a game: classic, timed, speedrun or daily
+  :puzzle <token> start a game from a puzzle
+  :resume <id>    carry on with another game
+  :quit           leave, the game can be resumed later
+An empty line redraws the board.`
+
+// How a session starts: resuming a game, from a puzzle, or a new game in the
+// mode
+type Options struct {
+	Mode   string
+	Puzzle string
+	Resume string
+	Color  bool
+}
+
+// Plays games with commands read from in and the board written to out, until
+// in ends or the player quits
+func Run(ctx context.Context, c *Client, opts Options, in io.Reader, out io.Writer) error {
+	s := session{client: c, screen: Screen{Color: opts.Color}, out: out}
+
+	var err error
+	switch {
+	case len(opts.Resume) > 0:
+		s.game, err = c.Game(ctx, opts.Resume)
+	default:
+		s.game, err = c.NewGame(ctx, opts.Mode, opts.Puzzle)
+	}
+	if err != nil {
+		return err
+	}
+	s.draw("")
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if s.do(ctx, strings.TrimSpace(scanner.Text())) {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Registers the flags of a session on fs. Once fs is parsed, the returned
+// function gives the client and options they describe.
+func Flags(fs *flag.FlagSet) func() (*Client, Options) {
+	server := fs.String("server", envOr(CLIENT_SERVER_ENV, CLIENT_DEFAULT_SERVER), "base URL of the server")
+	key := fs.String("key", os.Getenv(CLIENT_API_KEY_ENV), "API key sent with each request")
+	player := fs.String("player", os.Getenv(CLIENT_PLAYER_ENV), "player id owning the games")
+	mode := fs.String("mode", "classic", "mode of a new game: classic, timed, speedrun or daily")
+	puzzle := fs.String("puzzle", "", "start from a puzzle token")
+	resume := fs.String("resume", "", "id of a game to carry on with")
+	plain := fs.Bool("plain", len(os.Getenv("NO_COLOR")) > 0, "mark letters without color")
+
+	return func() (*Client, Options) {
+		opts := Options{Mode: *mode, Puzzle: *puzzle, Resume: *resume, Color: !*plain}
+		return New(*server, *key, *player), opts
+	}
+}
+
+/////////////
+
+type session struct {
+	client *Client
+	screen Screen
+	out    io.Writer
+	game   *Game
+}
+
+// Carries out one line of input and returns whether the player quit
+func (s *session) do(ctx context.Context, line string) bool {
+	if !strings.HasPrefix(line, ":") {
+		if len(line) < 1 {
+			s.update(s.client.Game(ctx, s.game.Id))
+		} else if s.game.Status != game.InPlay {
+			s.draw(game.ErrGameOver.Error() + ", :new starts another")
+		} else {
+			s.update(s.client.Play(ctx, s.game, line))
+		}
+		return false
+	}
+
+	command, arg, _ := strings.Cut(line[1:], " ")
+	arg = strings.TrimSpace(arg)
+	switch strings.ToLower(command) {
+	case "q", "quit":
+		fmt.Fprintln(s.out, "resume with", s.game.Id)
+		return true
+	case "h", "hint":
+		h, err := s.client.Hint(ctx, s.game)
+		if err != nil {
+			s.draw(err.Error())
+		} else {
+			s.draw(hintLine(h))
+		}
+	case "resign":
+		s.update(s.client.Resign(ctx, s.game))
+	case "new":
+		s.update(s.client.NewGame(ctx, arg, ""))
+	case "puzzle":
+		s.update(s.client.NewGame(ctx, "", arg))
+	case "resume":
+		s.update(s.client.Game(ctx, arg))
+	case "help":
+		s.draw(help)
+	default:
+		s.draw(ErrBadCommand.Error())
+	}
+	return false
+}
+
+// Shows the game returned by the server, or the error and the game as it was
+func (s *session) update(g *Game, err error) {
+	if err != nil {
+		s.draw(err.Error())
+		return
+	}
+	s.game = g
+	s.draw("")
+}
+
+func (s *session) draw(message string) {
+	fmt.Fprint(s.out, s.screen.Render(s.game))
+	if len(message) > 0 {
+		fmt.Fprintln(s.out, message)
+	}
+	fmt.Fprint(s.out, PROMPT)
+}
+
+func hintLine(h *Hint) string {
+	words := make([]string, len(h.Suggestions))
+	for i, sg := range h.Suggestions {
+		words[i] = sg.Word
+	}
+	return fmt.Sprintf("try %s (%d words possible, %d hints left)", strings.Join(words, ", "), h.CandidatesRemaining, h.HintsLeft)
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); len(v) > 0 {
+		return v
+	}
+	return fallback
+}
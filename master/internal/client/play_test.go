@@ -0,0 +1,106 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	server, requests := newTestServer(t, map[string]string{
+		"/game":         testGame,
+		"/play":         testGame,
+		"/resign":       `{"id":"g1","gameStatus":"Resigned","secretWord":"HAPPY"}`,
+		"/game/g1/hint": `{"candidatesRemaining":2,"suggestions":[{"word":"happy"},{"word":"puppy"}],"hintsLeft":1}`,
+	})
+	c := New(server.URL, "", "")
+
+	tests := []struct {
+		opts   Options
+		input  string
+		paths  []string
+		output []string
+		err    string
+	}{
+		{
+			opts:   Options{Mode: "timed"},
+			input:  "happy\n:hint\n:resign\nlucky\n:new daily\n:bogus\n:quit\nnever read\n",
+			paths:  []string{"/game", "/play", "/game/g1/hint", "/resign", "/game"},
+			output: []string{"Timed game g1", "try happy, puppy (2 words possible, 1 hints left)", "Resigned. The word was HAPPY", "game is finished, :new starts another", ErrBadCommand.Error(), "resume with g1"},
+		},
+		{
+			opts:   Options{Resume: "g1"},
+			input:  "\n:resume missing\n",
+			paths:  []string{"/game", "/game", "/game"},
+			output: []string{"game not found"},
+		},
+		{
+			opts:   Options{Puzzle: "token"},
+			input:  ":help\n",
+			paths:  []string{"/game"},
+			output: []string{":resume <id>"},
+		},
+		{
+			opts:  Options{Resume: "missing"},
+			paths: []string{"/game"},
+			err:   "game not found",
+		},
+	}
+
+	for i, test := range tests {
+		*requests = nil
+		var out bytes.Buffer
+		err := Run(ctx, c, test.opts, strings.NewReader(test.input), &out)
+		if len(test.err) > 0 {
+			assert.EqualError(t, err, test.err, i)
+		} else {
+			require.NoError(t, err, i)
+		}
+		paths := []string{}
+		for _, r := range *requests {
+			paths = append(paths, r.URL.Path)
+		}
+		assert.Equal(t, test.paths, paths, i)
+		for _, line := range test.output {
+			assert.Contains(t, out.String(), line, i)
+		}
+	}
+}
+
+func TestFlags(t *testing.T) {
+	t.Setenv(CLIENT_SERVER_ENV, "http://wordle.test/")
+	t.Setenv(CLIENT_PLAYER_ENV, "p1")
+	t.Setenv("NO_COLOR", "")
+
+	tests := []struct {
+		args   []string
+		server string
+		player string
+		opts   Options
+	}{
+		{server: "http://wordle.test", player: "p1", opts: Options{Mode: "classic", Color: true}},
+		{
+			args:   []string{"-server", "http://other.test", "-player", "p2", "-mode", "daily", "-plain"},
+			server: "http://other.test",
+			player: "p2",
+			opts:   Options{Mode: "daily"},
+		},
+		{args: []string{"-resume", "g1"}, server: "http://wordle.test", player: "p1", opts: Options{Mode: "classic", Resume: "g1", Color: true}},
+	}
+
+	for _, test := range tests {
+		fs := flag.NewFlagSet("play", flag.ContinueOnError)
+		session := Flags(fs)
+		require.NoError(t, fs.Parse(test.args))
+		c, opts := session()
+		assert.Equal(t, test.server, c.BaseUrl, test.args)
+		assert.Equal(t, test.player, c.Player, test.args)
+		assert.Equal(t, test.opts, opts, test.args)
+	}
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/game"
+)
+
+// ANSI escapes used to draw the screen
+const ANSI_RESET = "\033[0m"
+const ANSI_CLEAR = "\033[H\033[2J"
+
+var mapLetterHintToColor = map[game.LetterHint]string{
+	game.Blank:  "\033[1;37;40m",
+	game.Green:  "\033[1;30;42m",
+	game.Yellow: "\033[1;30;43m",
+	game.Grey:   "\033[1;37;100m",
+	game.Red:    "\033[1;37;41m",
+}
+
+// Marks around each letter when there is no color: [A] green, (A) yellow,
+// plain grey, !A! not a word
+var mapLetterHintToMarks = map[game.LetterHint][2]string{
+	game.Blank:  {" ", " "},
+	game.Green:  {"[", "]"},
+	game.Yellow: {"(", ")"},
+	game.Grey:   {" ", " "},
+	game.Red:    {"!", "!"},
+}
+
+// Letter hints are ranked so the keyboard shows the best one known
+var mapLetterHintToRank = map[game.LetterHint]int{
+	game.Grey:   1,
+	game.Yellow: 2,
+	game.Green:  3,
+}
+
+var keyboardRows = []string{"QWERTYUIOP", "ASDFGHJKL", "ZXCVBNM"}
+
+// Draws games as rows of tiles with a keyboard underneath
+type Screen struct {
+	Color bool
+}
+
+// Returns the board, keyboard and status line of the game
+func (s Screen) Render(g *Game) string {
+	var b strings.Builder
+	if s.Color {
+		b.WriteString(ANSI_CLEAR)
+	}
+	fmt.Fprintf(&b, "%s game %s\n\n", g.Mode, g.Id)
+	b.WriteString(s.board(g))
+	b.WriteString("\n")
+	b.WriteString(s.keyboard(g))
+	b.WriteString("\n")
+	b.WriteString(status(g))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Returns a tile for the letter in the colors of the hint
+func (s Screen) Tile(letter rune, h game.LetterHint) string {
+	letter = unicode.ToUpper(letter)
+	if s.Color {
+		return mapLetterHintToColor[h] + " " + string(letter) + " " + ANSI_RESET
+	}
+	marks := mapLetterHintToMarks[h]
+	return marks[0] + string(letter) + marks[1]
+}
+
+/////////////
+
+// One row per attempt, words not in the dictionary in red, then empty rows
+// for the valid attempts left
+func (s Screen) board(g *Game) string {
+	var b strings.Builder
+	for _, a := range g.Attempts {
+		for i, r := range []rune(a.TryWord) {
+			h := game.Red
+			if a.IsValidWord && i < len(a.TryResult) {
+				h = a.TryResult[i]
+			}
+			b.WriteString(s.Tile(r, h))
+		}
+		b.WriteString("\n")
+	}
+	if g.Status == game.InPlay {
+		for i := g.ValidAttempts; i < config.CONFIG_GAME_MAXVALIDATTEMPTS; i++ {
+			b.WriteString(strings.Repeat(s.Tile('.', game.Blank), config.CONFIG_GAME_WORDLENGTH))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// The keyboard with each letter in the best hint it has had
+func (s Screen) keyboard(g *Game) string {
+	letters := lettersOf(g)
+	var b strings.Builder
+	for i, row := range keyboardRows {
+		b.WriteString(strings.Repeat(" ", i))
+		for _, r := range row {
+			b.WriteString(s.Tile(r, letters[r]))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Returns the best hint each letter has had in the valid attempts
+func lettersOf(g *Game) map[rune]game.LetterHint {
+	letters := map[rune]game.LetterHint{}
+	for _, a := range g.Attempts {
+		if !a.IsValidWord {
+			continue
+		}
+		for i, r := range []rune(strings.ToUpper(a.TryWord)) {
+			if i >= len(a.TryResult) {
+				break
+			}
+			if h := a.TryResult[i]; mapLetterHintToRank[h] > mapLetterHintToRank[letters[r]] {
+				letters[r] = h
+			}
+		}
+	}
+	return letters
+}
+
+func status(g *Game) string {
+	switch g.Status {
+	case game.Won:
+		return fmt.Sprintf("Won in %d!", g.ValidAttempts)
+	case game.Lost:
+		if g.TimedOut {
+			return "Out of time." + reveal(g)
+		}
+		return "Lost." + reveal(g)
+	case game.Resigned:
+		return "Resigned." + reveal(g)
+	}
+
+	line := fmt.Sprintf("%d of %d guesses left", config.CONFIG_GAME_MAXVALIDATTEMPTS-g.ValidAttempts, config.CONFIG_GAME_MAXVALIDATTEMPTS)
+	if g.TimeRemainingSeconds > 0 {
+		line += fmt.Sprintf(", %.0fs to go", g.TimeRemainingSeconds)
+	}
+	if n := len(g.Attempts); n > 0 && !g.Attempts[n-1].IsValidWord {
+		line = g.Attempts[n-1].TryWord + " is not a word. " + line
+	}
+	return line
+}
+
+// Daily games keep the word secret until the day is over
+func reveal(g *Game) string {
+	if len(g.SecretWord) < 1 {
+		return ""
+	}
+	return " The word was " + g.SecretWord
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"aluance.io/wordleserver/internal/game"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTile(t *testing.T) {
+	tests := []struct {
+		color  bool
+		hint   game.LetterHint
+		result string
+	}{
+		{hint: game.Green, result: "[A]"},
+		{hint: game.Yellow, result: "(A)"},
+		{hint: game.Grey, result: " A "},
+		{hint: game.Red, result: "!A!"},
+		{hint: game.Blank, result: " A "},
+		{color: true, hint: game.Green, result: "\033[1;30;42m A \033[0m"},
+		{color: true, hint: game.Red, result: "\033[1;37;41m A \033[0m"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.result, Screen{Color: test.color}.Tile('a', test.hint), test.hint.String())
+	}
+}
+
+func TestRender(t *testing.T) {
+	attempt := func(word string, valid bool, hints ...game.LetterHint) *game.WordleAttempt {
+		return &game.WordleAttempt{TryWord: word, IsValidWord: valid, TryResult: hints}
+	}
+	G, Y, X := game.Green, game.Yellow, game.Grey
+
+	tests := []struct {
+		game     Game
+		rows     []string
+		keyboard []string
+		status   string
+	}{
+		{
+			game:   Game{Mode: game.Classic},
+			rows:   []string{" .  .  .  .  . "},
+			status: "6 of 6 guesses left",
+		},
+		{
+			game: Game{Mode: game.Timed, ValidAttempts: 2, TimeRemainingSeconds: 41.6, Attempts: []*game.WordleAttempt{
+				attempt("LUCKY", true, X, Y, X, X, G),
+				attempt("PUPPY", true, X, G, X, X, G),
+				attempt("ZZZZZ", false),
+			}},
+			rows:     []string{" L (U) C  K [Y]", " P [U] P  P [Y]", "!Z!!Z!!Z!!Z!!Z!", " .  .  .  .  . "},
+			keyboard: []string{" Q  W  E  R  T [Y][U] I  O  P ", "  A  S  D  F  G  H  J  K  L ", "   Z  X  C  V  B  N  M "},
+			status:   "ZZZZZ is not a word. 4 of 6 guesses left, 42s to go",
+		},
+		{
+			game: Game{Status: game.Won, ValidAttempts: 1, Attempts: []*game.WordleAttempt{
+				attempt("HAPPY", true, G, G, G, G, G),
+			}},
+			rows:   []string{"[H][A][P][P][Y]"},
+			status: "Won in 1!",
+		},
+		{
+			game:   Game{Status: game.Lost, TimedOut: true, SecretWord: "HAPPY"},
+			status: "Out of time. The word was HAPPY",
+		},
+		{
+			game:   Game{Status: game.Resigned, Mode: game.Daily},
+			status: "Resigned.",
+		},
+	}
+
+	for _, test := range tests {
+		out := Screen{}.Render(&test.game)
+		for _, row := range test.rows {
+			assert.Contains(t, out, row+"\n")
+		}
+		for _, row := range test.keyboard {
+			assert.Contains(t, out, row+"\n")
+		}
+		assert.True(t, strings.HasSuffix(out, test.status+"\n"), out)
+	}
+}
@@ -0,0 +1,25 @@
+package config
+
+import "runtime/debug"
+
+// Commit the binary was built from. Set at build time with
+// -ldflags "-X aluance.io/wordleserver/internal/config.BuildCommit=<commit>".
+var BuildCommit = ""
+
+// Returns the commit the binary was built from, falling back to the revision
+// recorded by the Go toolchain when BuildCommit was not set
+func Commit() string {
+	if len(BuildCommit) > 0 {
+		return BuildCommit
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" && len(s.Value) > 0 {
+				return s.Value
+			}
+		}
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommit(t *testing.T) {
+	assert := assert.New(t)
+
+	saved := BuildCommit
+	defer func() { BuildCommit = saved }()
+
+	BuildCommit = "abc1234"
+	assert.Equal("abc1234", Commit())
+
+	BuildCommit = ""
+	assert.NotEmpty(Commit())
+}
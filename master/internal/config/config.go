@@ -6,9 +6,15 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"time"
 )
 
 const CONFIG_API_PORT = 8080
+const CONFIG_API_SHUTDOWNDELAY = 5 * time.Second     // time for probes to see the server is not ready
+const CONFIG_API_SHUTDOWNTIMEOUT = 15 * time.Second  // time for requests in flight to finish
+const CONFIG_API_CORSMAXAGE = 12 * time.Hour         // time browsers may cache preflight results
+const CONFIG_API_TLSCHECKINTERVAL = 10 * time.Second // time between checks for a new certificate
+const CONFIG_API_PAGESIZE = 20                       // games listed per page unless asked for fewer or more
 
 // const CONFIG_DICTIONARY_FILENAME = "google-10000-english-usa-no-swears-medium.txt"
 const CONFIG_DICTIONARY_FILENAME = "corncob_lowercase.txt"
@@ -16,6 +22,14 @@ const CONFIG_DICTIONARY_FILEPATH = "data/" + CONFIG_DICTIONARY_FILENAME
 const CONFIG_GAME_WORDLENGTH = 5
 const CONFIG_GAME_MAXATTEMPTS = 12
 const CONFIG_GAME_MAXVALIDATTEMPTS = 6
+const CONFIG_GAME_GUESSTIMEOUT = 60 * time.Second
+const CONFIG_GAME_TIMELIMIT = 5 * time.Minute
+const CONFIG_GAME_SWEEPINTERVAL = 30 * time.Second
+const CONFIG_GAME_IDEMPOTENCYTTL = 24 * time.Hour // how long repeated plays get the original response
+const CONFIG_HINT_MAXPERGAME = 3
+const CONFIG_HINT_COOLDOWN = 5 * time.Second
+const CONFIG_HINT_SUGGESTIONS = 5
+const CONFIG_PUZZLE_KEY_ENV = "WORDLE_PUZZLE_KEY"
 
 func RootDir() string {
 	_, b, _, _ := runtime.Caller(0)
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the environment variables read by Load
+const CONFIG_API_KEYS_ENV = "WORDLE_API_KEYS"
+const CONFIG_CUSTOM_WORDS_ENV = "WORDLE_CUSTOM_WORDS"
+const CONFIG_HIDE_DAILY_SECRET_ENV = "WORDLE_HIDE_DAILY_SECRET"
+const CONFIG_LOG_LEVEL_ENV = "WORDLE_LOG_LEVEL"
+const CONFIG_TRACE_EXPORTER_ENV = "WORDLE_TRACE_EXPORTER"
+const CONFIG_TRACE_ENDPOINT_ENV = "WORDLE_TRACE_ENDPOINT"
+const CONFIG_RATE_LIMIT_IP_ENV = "WORDLE_RATE_LIMIT_IP"
+const CONFIG_RATE_LIMIT_PLAYER_ENV = "WORDLE_RATE_LIMIT_PLAYER"
+const CONFIG_RATE_LIMIT_GAME_ENV = "WORDLE_RATE_LIMIT_GAME"
+const CONFIG_MAX_IN_PLAY_ENV = "WORDLE_MAX_IN_PLAY"
+const CONFIG_CORS_ORIGINS_ENV = "WORDLE_CORS_ORIGINS"
+const CONFIG_TRUSTED_PROXIES_ENV = "WORDLE_TRUSTED_PROXIES"
+const CONFIG_HSTS_MAX_AGE_ENV = "WORDLE_HSTS_MAX_AGE"
+const CONFIG_CSP_ENV = "WORDLE_CSP"
+const CONFIG_TLS_CERT_ENV = "WORDLE_TLS_CERT"
+const CONFIG_TLS_KEY_ENV = "WORDLE_TLS_KEY"
+const CONFIG_STORE_ENV = "WORDLE_STORE"
+const CONFIG_REDIS_URL_ENV = "WORDLE_REDIS_URL"
+const CONFIG_REDIS_TTL_ENV = "WORDLE_REDIS_TTL"
+const CONFIG_SQL_URL_ENV = "WORDLE_SQL_URL"
+const CONFIG_BOLT_PATH_ENV = "WORDLE_BOLT_PATH"
+const CONFIG_SEED_ENV = "WORDLE_SEED"
+
+// Policy value that lets anyone choose a secret word
+const CONFIG_CUSTOM_WORDS_OPEN = "open"
+
+// Settings that can be changed through the environment without rebuilding
+type Settings struct {
+	// API keys and the role granted to each, from "key:role,key:role"
+	ApiKeys map[string]string
+	// Role needed to create a game with a chosen secret word, or "open"
+	CustomWords string
+	// Keep the secret word of daily games hidden until the day is over
+	HideDailySecret bool
+	// Lowest level logged at start up, one of "debug", "info", "warn" or "error"
+	LogLevel string
+	// Where spans are sent, one of "none", "stdout" or "otlp"
+	TraceExporter string
+	// URL of the OTLP collector, or empty for the OTEL_EXPORTER_OTLP_* variables
+	TraceEndpoint string
+	// Requests allowed per client address, player and game, from "perMinute:burst"
+	RateLimitIp     RateLimit
+	RateLimitPlayer RateLimit
+	RateLimitGame   RateLimit
+	// Games a client may have in play at once, or 0 for no limit
+	MaxInPlay int
+	// Origins of the web front-ends allowed to call the API, "*" for any
+	CorsOrigins []string
+	// Addresses or CIDRs of the proxies whose X-Forwarded-For and X-Real-IP
+	// headers name the client, none when empty
+	TrustedProxies []string
+	// Seconds browsers must keep to HTTPS, or 0 to leave out the HSTS header
+	HstsMaxAge int
+	// Content-Security-Policy sent with every response
+	ContentSecurityPolicy string
+	// Paths of the certificate and key to serve HTTPS with, reloaded when changed
+	TlsCert string
+	TlsKey  string
+	// Where games are kept, one of "memory", "redis", "redis-cluster", "sqlite",
+	// "postgres" or "bolt"
+	Store string
+	// Address of the Redis server, as redis://[user:password@]host:port/db, or
+	// of nodes of a Redis Cluster, as redis://host:port?addr=host:port
+	RedisUrl string
+	// How long a game is kept in Redis after it was last saved
+	RedisTtl time.Duration
+	// SQLite file name or Postgres connection URL of the SQL stores
+	SqlUrl string
+	// File of the bolt store
+	BoltPath string
+	// Seed of the secret words and game ids picked, so that a sequence of
+	// games can be played again, or 0 to pick them at random
+	Seed int64
+}
+
+// Serving with TLS needs both a certificate and a key
+func (s Settings) TlsEnabled() bool {
+	return len(s.TlsCert) > 0 && len(s.TlsKey) > 0
+}
+
+// Address of the store chosen with Store
+func (s Settings) StoreUrl() string {
+	switch s.Store {
+	case "redis", "redis-cluster":
+		return s.RedisUrl
+	case "sqlite", "postgres":
+		return s.SqlUrl
+	case "bolt":
+		return s.BoltPath
+	}
+	return ""
+}
+
+// Changes the address of the store chosen with Store. The memory store has
+// none, so it is left alone.
+func (s *Settings) SetStoreUrl(url string) {
+	switch s.Store {
+	case "redis", "redis-cluster":
+		s.RedisUrl = url
+	case "sqlite", "postgres":
+		s.SqlUrl = url
+	case "bolt":
+		s.BoltPath = url
+	}
+}
+
+// Token bucket refilled with PerMinute tokens a minute, holding up to Burst.
+// A PerMinute of 0 turns the limit off.
+type RateLimit struct {
+	PerMinute float64
+	Burst     int
+}
+
+var current *Settings
+var settingsMutex sync.RWMutex
+
+// Returns the settings in use, loading them from the environment on first use
+func Current() Settings {
+	settingsMutex.RLock()
+	if current != nil {
+		defer settingsMutex.RUnlock()
+		return *current
+	}
+	settingsMutex.RUnlock()
+
+	s := Load()
+	Set(s)
+	return s
+}
+
+// Replaces the settings in use
+func Set(s Settings) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+	current = &s
+}
+
+// Returns the settings described by the environment, with defaults for
+// anything that is not set
+func Load() Settings {
+	s := Settings{
+		ApiKeys:               map[string]string{},
+		CustomWords:           "creator",
+		HideDailySecret:       true,
+		LogLevel:              "info",
+		TraceExporter:         "none",
+		RateLimitIp:           RateLimit{PerMinute: 300, Burst: 60},
+		RateLimitPlayer:       RateLimit{PerMinute: 120, Burst: 30},
+		RateLimitGame:         RateLimit{PerMinute: 60, Burst: 12},
+		MaxInPlay:             20,
+		CorsOrigins:           []string{},
+		TrustedProxies:        []string{},
+		HstsMaxAge:            31536000,
+		ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+		Store:                 "memory",
+		RedisUrl:              "redis://localhost:6379/0",
+		RedisTtl:              30 * 24 * time.Hour,
+		SqlUrl:                "file:wordle.db?_pragma=busy_timeout(5000)",
+		BoltPath:              "wordle.bolt",
+	}
+
+	for _, entry := range strings.Split(os.Getenv(CONFIG_API_KEYS_ENV), ",") {
+		kv := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(kv) == 2 && len(kv[0]) > 0 && len(kv[1]) > 0 {
+			s.ApiKeys[kv[0]] = kv[1]
+		}
+	}
+	if v := os.Getenv(CONFIG_CUSTOM_WORDS_ENV); len(v) > 0 {
+		s.CustomWords = v
+	}
+	if v, err := strconv.ParseBool(os.Getenv(CONFIG_HIDE_DAILY_SECRET_ENV)); err == nil {
+		s.HideDailySecret = v
+	}
+	if v := os.Getenv(CONFIG_LOG_LEVEL_ENV); len(v) > 0 {
+		s.LogLevel = v
+	}
+	if v := os.Getenv(CONFIG_TRACE_EXPORTER_ENV); len(v) > 0 {
+		s.TraceExporter = v
+	}
+	s.TraceEndpoint = os.Getenv(CONFIG_TRACE_ENDPOINT_ENV)
+	if v, ok := parseRateLimit(os.Getenv(CONFIG_RATE_LIMIT_IP_ENV)); ok {
+		s.RateLimitIp = v
+	}
+	if v, ok := parseRateLimit(os.Getenv(CONFIG_RATE_LIMIT_PLAYER_ENV)); ok {
+		s.RateLimitPlayer = v
+	}
+	if v, ok := parseRateLimit(os.Getenv(CONFIG_RATE_LIMIT_GAME_ENV)); ok {
+		s.RateLimitGame = v
+	}
+	if v, err := strconv.Atoi(os.Getenv(CONFIG_MAX_IN_PLAY_ENV)); err == nil && v >= 0 {
+		s.MaxInPlay = v
+	}
+	for _, origin := range strings.Split(os.Getenv(CONFIG_CORS_ORIGINS_ENV), ",") {
+		if origin = strings.TrimSpace(origin); len(origin) > 0 {
+			s.CorsOrigins = append(s.CorsOrigins, origin)
+		}
+	}
+	for _, proxy := range strings.Split(os.Getenv(CONFIG_TRUSTED_PROXIES_ENV), ",") {
+		if proxy = strings.TrimSpace(proxy); len(proxy) > 0 {
+			s.TrustedProxies = append(s.TrustedProxies, proxy)
+		}
+	}
+	if v, err := strconv.Atoi(os.Getenv(CONFIG_HSTS_MAX_AGE_ENV)); err == nil && v >= 0 {
+		s.HstsMaxAge = v
+	}
+	if v, ok := os.LookupEnv(CONFIG_CSP_ENV); ok {
+		s.ContentSecurityPolicy = v
+	}
+	s.TlsCert = os.Getenv(CONFIG_TLS_CERT_ENV)
+	s.TlsKey = os.Getenv(CONFIG_TLS_KEY_ENV)
+	if v := os.Getenv(CONFIG_STORE_ENV); len(v) > 0 {
+		s.Store = v
+	}
+	if v := os.Getenv(CONFIG_REDIS_URL_ENV); len(v) > 0 {
+		s.RedisUrl = v
+	}
+	if v, err := time.ParseDuration(os.Getenv(CONFIG_REDIS_TTL_ENV)); err == nil && v > 0 {
+		s.RedisTtl = v
+	}
+	if v := os.Getenv(CONFIG_SQL_URL_ENV); len(v) > 0 {
+		s.SqlUrl = v
+	}
+	if v := os.Getenv(CONFIG_BOLT_PATH_ENV); len(v) > 0 {
+		s.BoltPath = v
+	}
+	if v, err := strconv.ParseInt(os.Getenv(CONFIG_SEED_ENV), 10, 64); err == nil {
+		s.Seed = v
+	}
+
+	return s
+}
+
+/////////////
+
+// Parses "perMinute:burst", where the burst may be left out
+func parseRateLimit(v string) (RateLimit, bool) {
+	if len(v) < 1 {
+		return RateLimit{}, false
+	}
+
+	parts := strings.SplitN(v, ":", 2)
+	perMinute, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || perMinute < 0 {
+		return RateLimit{}, false
+	}
+	r := RateLimit{PerMinute: perMinute}
+	if len(parts) > 1 {
+		if r.Burst, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil || r.Burst < 0 {
+			return RateLimit{}, false
+		}
+	}
+
+	return r, true
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	// Returns the defaults, changed by fn
+	defaults := func(fn func(s *Settings)) Settings {
+		s := Settings{
+			ApiKeys:               map[string]string{},
+			CustomWords:           "creator",
+			HideDailySecret:       true,
+			LogLevel:              "info",
+			TraceExporter:         "none",
+			RateLimitIp:           RateLimit{PerMinute: 300, Burst: 60},
+			RateLimitPlayer:       RateLimit{PerMinute: 120, Burst: 30},
+			RateLimitGame:         RateLimit{PerMinute: 60, Burst: 12},
+			MaxInPlay:             20,
+			CorsOrigins:           []string{},
+			TrustedProxies:        []string{},
+			HstsMaxAge:            31536000,
+			ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+			Store:                 "memory",
+			RedisUrl:              "redis://localhost:6379/0",
+			RedisTtl:              30 * 24 * time.Hour,
+			SqlUrl:                "file:wordle.db?_pragma=busy_timeout(5000)",
+			BoltPath:              "wordle.bolt",
+		}
+		if fn != nil {
+			fn(&s)
+		}
+		return s
+	}
+
+	tests := []struct {
+		env    map[string]string
+		result Settings
+	}{
+		{
+			env:    map[string]string{},
+			result: defaults(nil),
+		},
+		{
+			env: map[string]string{
+				CONFIG_API_KEYS_ENV:          "abc:admin, def:creator,bad,:nokey,norole:",
+				CONFIG_CUSTOM_WORDS_ENV:      "open",
+				CONFIG_HIDE_DAILY_SECRET_ENV: "false",
+				CONFIG_LOG_LEVEL_ENV:         "debug",
+				CONFIG_TRACE_EXPORTER_ENV:    "otlp",
+				CONFIG_TRACE_ENDPOINT_ENV:    "http://collector:4318",
+				CONFIG_RATE_LIMIT_IP_ENV:     "30:5",
+				CONFIG_RATE_LIMIT_PLAYER_ENV: "0",
+				CONFIG_RATE_LIMIT_GAME_ENV:   " 10.5 : 2 ",
+				CONFIG_MAX_IN_PLAY_ENV:       "0",
+				CONFIG_CORS_ORIGINS_ENV:      "https://wordle.example.com, ,http://localhost:3000",
+				CONFIG_TRUSTED_PROXIES_ENV:   "10.0.0.0/8, ,192.168.1.1",
+				CONFIG_HSTS_MAX_AGE_ENV:      "0",
+				CONFIG_CSP_ENV:               "default-src 'self'",
+				CONFIG_TLS_CERT_ENV:          "/certs/tls.crt",
+				CONFIG_TLS_KEY_ENV:           "/certs/tls.key",
+				CONFIG_STORE_ENV:             "redis",
+				CONFIG_REDIS_URL_ENV:         "redis://cache:6379/2",
+				CONFIG_REDIS_TTL_ENV:         "48h",
+				CONFIG_SQL_URL_ENV:           "postgres://db/wordle",
+				CONFIG_BOLT_PATH_ENV:         "/data/wordle.bolt",
+				CONFIG_SEED_ENV:              "-42",
+			},
+			result: defaults(func(s *Settings) {
+				s.ApiKeys = map[string]string{"abc": "admin", "def": "creator"}
+				s.CustomWords = "open"
+				s.HideDailySecret = false
+				s.LogLevel = "debug"
+				s.TraceExporter = "otlp"
+				s.TraceEndpoint = "http://collector:4318"
+				s.RateLimitIp = RateLimit{PerMinute: 30, Burst: 5}
+				s.RateLimitPlayer = RateLimit{}
+				s.RateLimitGame = RateLimit{PerMinute: 10.5, Burst: 2}
+				s.MaxInPlay = 0
+				s.CorsOrigins = []string{"https://wordle.example.com", "http://localhost:3000"}
+				s.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1"}
+				s.HstsMaxAge = 0
+				s.ContentSecurityPolicy = "default-src 'self'"
+				s.TlsCert = "/certs/tls.crt"
+				s.TlsKey = "/certs/tls.key"
+				s.Store = "redis"
+				s.RedisUrl = "redis://cache:6379/2"
+				s.RedisTtl = 48 * time.Hour
+				s.SqlUrl = "postgres://db/wordle"
+				s.BoltPath = "/data/wordle.bolt"
+				s.Seed = -42
+			}),
+		},
+		{
+			env: map[string]string{
+				CONFIG_HIDE_DAILY_SECRET_ENV: "perhaps",
+				CONFIG_RATE_LIMIT_IP_ENV:     "fast",
+				CONFIG_RATE_LIMIT_PLAYER_ENV: "-1:5",
+				CONFIG_RATE_LIMIT_GAME_ENV:   "10:lots",
+				CONFIG_MAX_IN_PLAY_ENV:       "-3",
+				CONFIG_HSTS_MAX_AGE_ENV:      "forever",
+				CONFIG_REDIS_TTL_ENV:         "-1h",
+				CONFIG_SEED_ENV:              "0x2a",
+			},
+			result: defaults(nil),
+		},
+	}
+
+	names := []string{
+		CONFIG_API_KEYS_ENV, CONFIG_CUSTOM_WORDS_ENV, CONFIG_HIDE_DAILY_SECRET_ENV, CONFIG_LOG_LEVEL_ENV,
+		CONFIG_TRACE_EXPORTER_ENV, CONFIG_TRACE_ENDPOINT_ENV, CONFIG_RATE_LIMIT_IP_ENV,
+		CONFIG_RATE_LIMIT_PLAYER_ENV, CONFIG_RATE_LIMIT_GAME_ENV, CONFIG_MAX_IN_PLAY_ENV,
+		CONFIG_CORS_ORIGINS_ENV, CONFIG_TRUSTED_PROXIES_ENV, CONFIG_HSTS_MAX_AGE_ENV, CONFIG_TLS_CERT_ENV, CONFIG_TLS_KEY_ENV,
+		CONFIG_STORE_ENV, CONFIG_REDIS_URL_ENV, CONFIG_REDIS_TTL_ENV, CONFIG_SQL_URL_ENV,
+		CONFIG_BOLT_PATH_ENV, CONFIG_SEED_ENV,
+	}
+	for _, test := range tests {
+		for _, k := range names {
+			t.Setenv(k, test.env[k])
+		}
+		// An empty policy is allowed, so the variable is only set when given
+		t.Setenv(CONFIG_CSP_ENV, "")
+		os.Unsetenv(CONFIG_CSP_ENV)
+		if v, ok := test.env[CONFIG_CSP_ENV]; ok {
+			os.Setenv(CONFIG_CSP_ENV, v)
+		}
+
+		assert.Equal(test.result, Load())
+	}
+}
+
+func TestTlsEnabled(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(Settings{}.TlsEnabled())
+	assert.False(Settings{TlsCert: "tls.crt"}.TlsEnabled())
+	assert.False(Settings{TlsKey: "tls.key"}.TlsEnabled())
+	assert.True(Settings{TlsCert: "tls.crt", TlsKey: "tls.key"}.TlsEnabled())
+}
+
+func TestStoreUrl(t *testing.T) {
+	assert := assert.New(t)
+
+	s := Settings{RedisUrl: "redis://cache:6379/0", SqlUrl: "wordle.db", BoltPath: "wordle.bolt"}
+	tests := []struct {
+		store  string
+		result string
+	}{
+		{store: "memory", result: ""},
+		{store: "redis", result: "redis://cache:6379/0"},
+		{store: "redis-cluster", result: "redis://cache:6379/0"},
+		{store: "sqlite", result: "wordle.db"},
+		{store: "postgres", result: "wordle.db"},
+		{store: "bolt", result: "wordle.bolt"},
+	}
+
+	for _, test := range tests {
+		s.Store = test.store
+		assert.Equal(test.result, s.StoreUrl(), test.store)
+
+		changed := s
+		changed.SetStoreUrl("elsewhere")
+		if len(test.result) > 0 {
+			assert.Equal("elsewhere", changed.StoreUrl(), test.store)
+		} else {
+			assert.Equal(s, changed, test.store)
+		}
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	Set(Settings{CustomWords: "admin"})
+	assert.Equal("admin", Current().CustomWords)
+
+	// Changing the copy does not change the settings in use
+	s := Current()
+	s.CustomWords = "open"
+	assert.Equal("admin", Current().CustomWords)
+}
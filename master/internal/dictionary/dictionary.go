@@ -2,38 +2,198 @@ package dictionary
 
 import (
 	"bufio"
+	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
 	"math/rand"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/tracing"
 	"github.com/matryer/resync"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func GenerateWord() (string, error) {
-	if err := Initialize(""); err != nil {
-		return "", err
+// Words of the game length that games are played with
+type Dictionary struct {
+	words   []string
+	wordMap map[string]bool
+}
+
+// Returns a dictionary of the words of the game length in r, one per line.
+// Other lines are ignored.
+func New(r io.Reader) (*Dictionary, error) {
+	d := &Dictionary{words: []string{}, wordMap: make(map[string]bool)}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := scanner.Text()
+		if len(word) == config.CONFIG_GAME_WORDLENGTH {
+			d.words = append(d.words, word)
+			d.wordMap[word] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Returns a dictionary of the words in a file embedded in the build, the
+// configured one when filename is empty
+func Load(filename string) (*Dictionary, error) {
+	if len(filename) < 1 {
+		filename = config.CONFIG_DICTIONARY_FILEPATH
 	}
 
+	f, err := config.LoadEmbedFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return New(f)
+}
+
+// Returns a word picked with r, or with the shared source when r is nil
+func (d *Dictionary) GenerateWord(ctx context.Context, r *rand.Rand) (string, error) {
+	_, span := tracing.Start(ctx, "dictionary.GenerateWord")
+	defer span.End()
+
 	word := "blank"
-	if max := wordleDict.size(); max > 0 {
-		index := rand.Intn(max)
-		word = wordleDict.words[index]
+	if max := d.size(); max > 0 {
+		if r != nil {
+			word = d.words[r.Intn(max)]
+		} else {
+			word = d.words[sharedIntn(max)]
+		}
 	}
 
 	return word, nil
 }
 
-func IsWordValid(w string) bool {
+// Returns the word of the day, chosen with the daily key so that it cannot be
+// worked out from the word list. Every caller with the same key gets the same
+// word for the same UTC date.
+func (d *Dictionary) DailyWord(ctx context.Context, day time.Time) (string, error) {
+	_, span := tracing.Start(ctx, "dictionary.DailyWord")
+	defer span.End()
+
+	word := "blank"
+	if max := d.size(); max > 0 {
+		mac := hmac.New(sha256.New, sharedDailyKey())
+		mac.Write([]byte("daily:" + day.UTC().Format("2006-01-02")))
+		word = d.words[int(binary.BigEndian.Uint64(mac.Sum(nil))%uint64(max))]
+	}
+
+	return word, nil
+}
+
+// Reports whether w is in the dictionary. The word itself is not recorded in
+// the span, as it may be a secret word.
+func (d *Dictionary) IsWordValid(ctx context.Context, w string) bool {
+	_, span := tracing.Start(ctx, "dictionary.IsWordValid")
+	defer span.End()
+
+	member := d.wordMap[strings.ToLower(w)]
+	span.SetAttributes(attribute.Bool("dictionary.valid", member))
+
+	return member
+}
+
+// Returns a copy of every word in the dictionary
+func (d *Dictionary) Words() ([]string, error) {
+	return append([]string{}, d.words...), nil
+}
+
+// Returns the number of words in the dictionary
+func (d *Dictionary) Size() int {
+	return d.size()
+}
+
+// Returns the dictionary the package functions use, loading it on first use
+func Shared() (*Dictionary, error) {
 	if err := Initialize(""); err != nil {
+		return nil, err
+	}
+
+	return wordleDict.Dictionary, nil
+}
+
+// Replaces the shared source words are picked with, so that a seeded source
+// picks the same words every run. A nil r is a source seeded from the time.
+func SetRand(r *rand.Rand) {
+	sharedRand.Lock()
+	defer sharedRand.Unlock()
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	sharedRand.r = r
+}
+
+// Replaces the key the daily words are chosen with. A nil key goes back to
+// the one in config.CONFIG_PUZZLE_KEY_ENV, or to a random key when it is not
+// set, in which case the daily words change when the server restarts.
+func SetDailyKey(key []byte) {
+	dailyKey.Lock()
+	defer dailyKey.Unlock()
+	dailyKey.key = key
+}
+
+func GenerateWord(ctx context.Context) (string, error) {
+	d, err := Shared()
+	if err != nil {
+		return "", err
+	}
+
+	return d.GenerateWord(ctx, nil)
+}
+
+// Returns the word of the day from the shared dictionary
+func DailyWord(ctx context.Context, day time.Time) (string, error) {
+	d, err := Shared()
+	if err != nil {
+		return "", err
+	}
+
+	return d.DailyWord(ctx, day)
+}
+
+// Reports whether w is in the shared dictionary
+func IsWordValid(ctx context.Context, w string) bool {
+	d, err := Shared()
+	if err != nil {
 		return false
 	}
 
-	if member, ok := wordleDict.wordMap[strings.ToLower(w)]; ok {
-		return member
+	return d.IsWordValid(ctx, w)
+}
+
+// Returns a copy of every word in the shared dictionary
+func Words() ([]string, error) {
+	d, err := Shared()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Words()
+}
+
+// Returns the number of words in the shared dictionary
+func Size() int {
+	d, err := Shared()
+	if err != nil {
+		return 0
 	}
 
-	return false
+	return d.Size()
 }
 
 func Initialize(filename string) error {
@@ -55,44 +215,66 @@ func Initialize(filename string) error {
 
 	// Do this only once (unless reset)
 	wordleDict.init_once.Do(func() {
-		rand.Seed(time.Now().UnixNano())
-
 		// Load only words of configured length from the file
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			word := scanner.Text()
-			if len(word) == config.CONFIG_GAME_WORDLENGTH {
-				wordleDict.words = append(wordleDict.words, word)
-				wordleDict.wordMap[word] = true
-			}
-		}
-
-		if err := scanner.Err(); err != nil {
+		d, err := New(f)
+		if err != nil {
 			return
 		}
 
+		wordleDict.Dictionary = d
 		wordleDict.initalized = true
 	})
 
 	return nil
 }
 
-type dict struct {
-	init_once  resync.Once
-	initalized bool
-	words      []string
-	wordMap    map[string]bool
+/////////////
+
+// Source of the words picked without one of their own
+var sharedRand = struct {
+	sync.Mutex
+	r *rand.Rand
+}{r: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+func sharedIntn(n int) int {
+	sharedRand.Lock()
+	defer sharedRand.Unlock()
+	return sharedRand.r.Intn(n)
 }
 
-func (d *dict) size() int {
+// Key the daily words are chosen with, set on first use
+var dailyKey = struct {
+	sync.Mutex
+	key []byte
+}{}
+
+func sharedDailyKey() []byte {
+	dailyKey.Lock()
+	defer dailyKey.Unlock()
+	if dailyKey.key == nil {
+		dailyKey.key = []byte(os.Getenv(config.CONFIG_PUZZLE_KEY_ENV))
+	}
+	if len(dailyKey.key) < 1 {
+		dailyKey.key = make([]byte, 32)
+		crand.Read(dailyKey.key)
+	}
+	return dailyKey.key
+}
+
+func (d *Dictionary) size() int {
 	return len(d.words)
 }
 
+type dict struct {
+	*Dictionary
+	init_once  resync.Once
+	initalized bool
+}
+
 func (d *dict) reset() {
-	d.words = []string{}
-	d.wordMap = make(map[string]bool)
+	d.Dictionary = &Dictionary{words: []string{}, wordMap: make(map[string]bool)}
 	d.init_once.Reset()
 	d.initalized = false
 }
 
-var wordleDict = &dict{initalized: false, words: []string{}, wordMap: make(map[string]bool)}
+var wordleDict = &dict{initalized: false, Dictionary: &Dictionary{words: []string{}, wordMap: make(map[string]bool)}}
@@ -1,8 +1,10 @@
 package dictionary
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,6 +18,7 @@ const TEST_DICTIONARY_FILENAME = "google-10000-english-usa-no-swears-medium.txt"
 const TEST_DICTIONARY_FILEPATH = "data/google-10000-english-usa-no-swears-medium.txt"
 
 func TestGenerateWord(t *testing.T) {
+	ctx := context.Background()
 	assert := assert.New(t)
 	require := require.New(t)
 
@@ -23,12 +26,88 @@ func TestGenerateWord(t *testing.T) {
 	err := Initialize(TEST_DICTIONARY_FILEPATH)
 	require.NoError(err)
 
-	word, err := GenerateWord()
+	word, err := GenerateWord(ctx)
 	assert.NoError(err)
 	assert.Equal(config.CONFIG_GAME_WORDLENGTH, len(word))
 }
 
+func TestSetRand(t *testing.T) {
+	ctx := context.Background()
+	require := require.New(t)
+
+	wordleDict.reset()
+	require.NoError(Initialize(TEST_DICTIONARY_FILEPATH))
+	defer SetRand(nil)
+
+	// A seeded source picks the same words every time
+	picks := func() []string {
+		SetRand(rand.New(rand.NewSource(42)))
+		out := []string{}
+		for i := 0; i < 10; i++ {
+			word, err := GenerateWord(ctx)
+			require.NoError(err)
+			out = append(out, word)
+		}
+		return out
+	}
+	assert.Equal(t, picks(), picks())
+}
+
+func TestDailyWord(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	wordleDict.reset()
+	err := Initialize(TEST_DICTIONARY_FILEPATH)
+	require.NoError(err)
+
+	day := time.Date(2022, 2, 14, 9, 30, 0, 0, time.UTC)
+	word, err := DailyWord(ctx, day)
+	assert.NoError(err)
+	assert.Equal(config.CONFIG_GAME_WORDLENGTH, len(word))
+	assert.True(IsWordValid(ctx, word))
+
+	// Same word all day, in any time zone
+	later, err := DailyWord(ctx, day.Add(14*time.Hour).In(time.FixedZone("EST", -5*3600)))
+	assert.NoError(err)
+	assert.Equal(word, later)
+
+	// The words change from day to day
+	words := map[string]bool{}
+	for i := 0; i < 10; i++ {
+		w, err := DailyWord(ctx, day.AddDate(0, 0, i))
+		assert.NoError(err)
+		words[w] = true
+	}
+	assert.Greater(len(words), 1)
+}
+
+func TestDailyKey(t *testing.T) {
+	ctx := context.Background()
+	defer SetDailyKey(nil)
+
+	d, err := Load(TEST_DICTIONARY_FILEPATH)
+	require.NoError(t, err)
+	words := func(key string) []string {
+		SetDailyKey([]byte(key))
+		out := []string{}
+		day := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		for i := 0; i < 10; i++ {
+			w, err := d.DailyWord(ctx, day.AddDate(0, 0, i))
+			require.NoError(t, err)
+			out = append(out, w)
+		}
+		return out
+	}
+
+	// The words of the days cannot be worked out without the key
+	assert.Equal(t, words("first key"), words("first key"))
+	assert.NotEqual(t, words("first key"), words("second key"))
+}
+
 func TestIsWordValid(t *testing.T) {
+	ctx := context.Background()
 	assert := assert.New(t)
 	require := require.New(t)
 
@@ -39,16 +118,35 @@ func TestIsWordValid(t *testing.T) {
 	// Test valid words
 	testWords := []string{"blank", "blANk", "anime", "drawn", "lives", "nodes"}
 	for _, tw := range testWords {
-		assert.True(IsWordValid(tw), fmt.Sprintf("\"%s\" should be valid", tw))
+		assert.True(IsWordValid(ctx, tw), fmt.Sprintf("\"%s\" should be valid", tw))
 	}
 
 	// Test invalid words
 	testWords = []string{"xxxxx", "whizz", "bangs", "blagu"}
 	for _, tw := range testWords {
-		assert.False(IsWordValid(tw), fmt.Sprintf("\"%s\" should NOT be valid", tw))
+		assert.False(IsWordValid(ctx, tw), fmt.Sprintf("\"%s\" should NOT be valid", tw))
 	}
 }
 
+func TestWords(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	wordleDict.reset()
+	err := Initialize(TEST_DICTIONARY_FILEPATH)
+	require.NoError(err)
+
+	words, err := Words()
+	assert.NoError(err)
+	assert.Equal(TEST_DICTIONARY_LENGTH, len(words))
+
+	assert.Equal(TEST_DICTIONARY_LENGTH, Size())
+
+	// Changing the copy must not change the dictionary
+	words[0] = "xxxxx"
+	assert.NotEqual("xxxxx", wordleDict.words[0])
+}
+
 func TestInitialize(t *testing.T) {
 	assert := assert.New(t)
 	rand.Seed(time.Now().UnixNano())
@@ -80,3 +178,51 @@ func TestInitialize(t *testing.T) {
 
 	// assert.Equal(wordleDict.words[rand.Intn(TEST_DICTIONARY_LENGTH)], "bless")
 }
+
+func TestDictionary(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	d, err := New(strings.NewReader("happy\r\npuppy\r\ntoo\r\nlucky\r\n"))
+	require.NoError(err)
+	assert.Equal(3, d.Size())
+	words, err := d.Words()
+	require.NoError(err)
+	assert.Equal([]string{"happy", "puppy", "lucky"}, words)
+	assert.True(d.IsWordValid(ctx, "PUPPY"))
+	assert.False(d.IsWordValid(ctx, "too"))
+
+	// The same source picks the same words
+	picks := func(seed int64) []string {
+		r := rand.New(rand.NewSource(seed))
+		out := []string{}
+		for i := 0; i < 10; i++ {
+			word, err := d.GenerateWord(ctx, r)
+			require.NoError(err)
+			out = append(out, word)
+		}
+		return out
+	}
+	assert.Equal(picks(7), picks(7))
+
+	day := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	first, err := d.DailyWord(ctx, day)
+	require.NoError(err)
+	again, err := d.DailyWord(ctx, day.Add(6*time.Hour))
+	require.NoError(err)
+	assert.Equal(first, again)
+
+	// Dictionaries are separate from the shared one
+	loaded, err := Load(TEST_DICTIONARY_FILEPATH)
+	require.NoError(err)
+	assert.Equal(TEST_DICTIONARY_LENGTH, loaded.Size())
+	_, err = Load("data/missing.txt")
+	assert.Error(err)
+
+	wordleDict.reset()
+	shared, err := Shared()
+	require.NoError(err)
+	assert.Same(wordleDict.Dictionary, shared)
+	assert.NotSame(loaded, shared)
+}
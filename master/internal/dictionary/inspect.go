@@ -0,0 +1,72 @@
+package dictionary
+
+import (
+	"bufio"
+	"io"
+
+	"aluance.io/wordleserver/internal/config"
+)
+
+// What a word list holds, as read by Inspect. Only lines of the game length
+// are loaded by Initialize, so the others are counted and otherwise ignored.
+type Report struct {
+	Lines      int          // lines in the list
+	Words      int          // distinct words of the game length
+	Duplicates []string     // words listed more than once
+	Invalid    []string     // lines that are not all lower case letters
+	Letters    map[rune]int // how many of the words hold each letter
+}
+
+// Reports whether the list can be used as it is
+func (r Report) Valid() bool {
+	return r.Words > 0 && len(r.Duplicates) < 1 && len(r.Invalid) < 1
+}
+
+// Reads a word list in the format Initialize loads, one word per line
+func Inspect(r io.Reader) (Report, error) {
+	report := Report{Duplicates: []string{}, Invalid: []string{}, Letters: map[rune]int{}}
+	seen := map[string]bool{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := scanner.Text()
+		report.Lines++
+		if len(word) != config.CONFIG_GAME_WORDLENGTH {
+			continue
+		}
+		if !lowerCase(word) {
+			report.Invalid = append(report.Invalid, word)
+			continue
+		}
+		if seen[word] {
+			report.Duplicates = append(report.Duplicates, word)
+			continue
+		}
+		seen[word] = true
+
+		report.Words++
+		letters := map[rune]bool{}
+		for _, c := range word {
+			letters[c] = true
+		}
+		for c := range letters {
+			report.Letters[c]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+/////////////
+
+func lowerCase(word string) bool {
+	for _, c := range word {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package dictionary
+
+import (
+	"strings"
+	"testing"
+
+	"aluance.io/wordleserver/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		list   string
+		report Report
+		valid  bool
+	}{
+		{
+			list:   "happy\r\npuppy\r\nlong words\r\nlucky\r\nbat\r\nbat\r\n",
+			report: Report{Lines: 6, Words: 3, Duplicates: []string{}, Invalid: []string{}},
+			valid:  true,
+		},
+		{
+			list:   "happy\nbat\nhappy\nHAPPY\nha-ha\n\n",
+			report: Report{Lines: 6, Words: 1, Duplicates: []string{"happy"}, Invalid: []string{"HAPPY", "ha-ha"}},
+		},
+		{
+			list:   "happy\npuppy\ntoo\n",
+			report: Report{Lines: 3, Words: 2, Duplicates: []string{}, Invalid: []string{}},
+			valid:  true,
+		},
+		{
+			list:   "",
+			report: Report{Duplicates: []string{}, Invalid: []string{}},
+		},
+	}
+
+	for _, test := range tests {
+		report, err := Inspect(strings.NewReader(test.list))
+		require.NoError(t, err)
+		report.Letters = nil
+		assert.Equal(t, test.report, report, test.list)
+		assert.Equal(t, test.valid, report.Valid(), test.list)
+	}
+
+	report, err := Inspect(strings.NewReader("happy\npuppy\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[rune]int{'h': 1, 'a': 1, 'p': 2, 'y': 2, 'u': 1}, report.Letters)
+
+	// The list the game uses loads the same words
+	f, err := config.LoadEmbedFile(TEST_DICTIONARY_FILEPATH)
+	require.NoError(t, err)
+	defer f.Close()
+	report, err = Inspect(f)
+	require.NoError(t, err)
+	assert.Equal(t, TEST_DICTIONARY_LENGTH, report.Words)
+}
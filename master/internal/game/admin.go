@@ -0,0 +1,339 @@
+package game
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Selects the games returned by List. Empty or zero fields match any game.
+type Filter struct {
+	Statuses      []GameStatusType
+	Modes         []GameMode
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+// What List and Games show of each game. The secret word is only shown by
+// Inspect.
+type Summary struct {
+	Id          string         `json:"id"`
+	Status      GameStatusType `json:"gameStatus"`
+	Mode        GameMode       `json:"mode"`
+	Owner       string         `json:"owner,omitempty"`
+	Attempts    int            `json:"attemptsUsed"`
+	Created     time.Time      `json:"created"`
+	LastUpdated time.Time      `json:"lastUpdated"`
+}
+
+// Counts of the games in the store
+type Stats struct {
+	Keys       int            `json:"keys"` // entries in the store, games or not
+	Games      int            `json:"games"`
+	Unreadable []string       `json:"unreadable,omitempty"` // ids of the entries that are not games
+	ByStatus   map[string]int `json:"byStatus"`
+	ByMode     map[string]int `json:"byMode"`
+	ByEvent    map[string]int `json:"byEvent"`
+	Oldest     *time.Time     `json:"oldest,omitempty"`
+	Newest     *time.Time     `json:"newest,omitempty"`
+}
+
+// Returns the games matching filter, oldest first
+func List(ctx context.Context, filter Filter) ([]Summary, error) {
+	return defaultEngine.List(ctx, filter)
+}
+
+// Returns the games of the engine matching filter, oldest first
+func (e *Engine) List(ctx context.Context, filter Filter) (_ []Summary, err error) {
+	ctx, span := tracing.Start(ctx, "game.List")
+	defer func() { tracing.End(span, err) }()
+
+	games, _, err := e.loadAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []Summary{}
+	for _, g := range games {
+		if filter.matches(g) {
+			list = append(list, g.summary())
+		}
+	}
+	span.SetAttributes(attribute.Int("game.count", len(list)))
+
+	return list, nil
+}
+
+// Returns the full state of a game, including its secret word and owner
+func Inspect(ctx context.Context, id string) (string, error) {
+	return defaultEngine.Inspect(ctx, id)
+}
+
+// Returns the full state of a game of the engine
+func (e *Engine) Inspect(ctx context.Context, id string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "game.Inspect", attribute.String("game.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	g, err := e.load(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	return g.adminReport(), nil
+}
+
+// Returns everything that happened to a game, in order
+func History(ctx context.Context, id string) ([]Event, error) {
+	return defaultEngine.History(ctx, id)
+}
+
+// Returns everything that happened to a game of the engine, in order
+func (e *Engine) History(ctx context.Context, id string) (_ []Event, err error) {
+	ctx, span := tracing.Start(ctx, "game.History", attribute.String("game.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	g, err := e.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]Event{}, g.Events...), nil
+}
+
+// Returns the full state of a game as it was after its first n events
+func InspectAt(ctx context.Context, id string, n int) (string, error) {
+	return defaultEngine.InspectAt(ctx, id, n)
+}
+
+// Returns the full state of a game of the engine after its first n events
+func (e *Engine) InspectAt(ctx context.Context, id string, n int) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "game.InspectAt", attribute.String("game.id", id), attribute.Int("game.events", n))
+	defer func() { tracing.End(span, err) }()
+
+	g, err := e.load(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if n < 1 || n > len(g.Events) {
+		return "", ErrEventIndex
+	}
+	past, err := rebuild(g.Events[:n])
+	if err != nil {
+		return "", err
+	}
+
+	return past.adminReport(), nil
+}
+
+// Resigns a game on behalf of its player, whatever its mode. Returns the
+// full state of the game.
+func ForceResign(ctx context.Context, id string) (string, error) {
+	return defaultEngine.ForceResign(ctx, id)
+}
+
+// Resigns a game of the engine on behalf of its player
+func (e *Engine) ForceResign(ctx context.Context, id string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "game.ForceResign", attribute.String("game.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	g, err := e.load(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if g.Status != InPlay {
+		return g.adminReport(), ErrGameOver
+	}
+	if _, err := g.Resign(ctx); err != nil {
+		return g.adminReport(), err
+	}
+	logging.Warn(ctx, "game resigned by admin", "id", id)
+
+	return g.adminReport(), nil
+}
+
+// Removes a game from the store
+func Delete(ctx context.Context, id string) error {
+	return defaultEngine.Delete(ctx, id)
+}
+
+// Removes a game from the store of the engine
+func (e *Engine) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "game.Delete", attribute.String("game.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	s, err := e.store()
+	if err != nil {
+		return err
+	}
+	if ok, err := s.Exists(ctx, id); err != nil || !ok {
+		return ErrNotFound
+	}
+	if err := s.Delete(ctx, id); err != nil {
+		return err
+	}
+	logging.Warn(ctx, "game deleted by admin", "id", id)
+
+	return nil
+}
+
+// Removes every game from the store
+func PurgeAll(ctx context.Context) error {
+	return defaultEngine.PurgeAll(ctx)
+}
+
+// Removes every game from the store of the engine
+func (e *Engine) PurgeAll(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "game.PurgeAll")
+	defer func() { tracing.End(span, err) }()
+
+	s, err := e.store()
+	if err != nil {
+		return err
+	}
+	if err := s.PurgeAll(ctx); err != nil {
+		return err
+	}
+	logging.Warn(ctx, "games purged by admin")
+
+	return nil
+}
+
+// Returns counts of the games in the store by status and mode, and of their
+// events by type
+func Statistics(ctx context.Context) (Stats, error) {
+	return defaultEngine.Statistics(ctx)
+}
+
+// Returns counts of the games in the store of the engine
+func (e *Engine) Statistics(ctx context.Context) (_ Stats, err error) {
+	ctx, span := tracing.Start(ctx, "game.Statistics")
+	defer func() { tracing.End(span, err) }()
+
+	games, unreadable, err := e.loadAll(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	stats := Stats{Keys: len(games) + len(unreadable), Games: len(games), Unreadable: unreadable, ByStatus: map[string]int{}, ByMode: map[string]int{}, ByEvent: map[string]int{}}
+	for _, g := range games {
+		stats.ByStatus[g.Status.String()]++
+		stats.ByMode[g.Mode.String()]++
+		for _, ev := range g.Events {
+			stats.ByEvent[ev.Type.String()]++
+		}
+	}
+	if len(games) > 0 {
+		oldest, newest := games[0].Created, games[len(games)-1].Created
+		stats.Oldest, stats.Newest = &oldest, &newest
+	}
+
+	return stats, nil
+}
+
+/////////////
+
+func (f Filter) matches(g *wordleGame) bool {
+	if len(f.Statuses) > 0 && !containsStatus(f.Statuses, g.Status) {
+		return false
+	}
+	if len(f.Modes) > 0 && !containsMode(f.Modes, g.Mode) {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !g.Created.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !g.Created.Before(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+func containsStatus(statuses []GameStatusType, status GameStatusType) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func containsMode(modes []GameMode, mode GameMode) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *wordleGame) summary() Summary {
+	return Summary{
+		Id:          g.Id,
+		Status:      g.Status,
+		Mode:        g.Mode,
+		Owner:       g.Owner,
+		Attempts:    len(g.Attempts),
+		Created:     g.Created,
+		LastUpdated: g.LastUpdated,
+	}
+}
+
+func (e *Engine) load(ctx context.Context, id string) (*wordleGame, error) {
+	s, err := e.store()
+	if err != nil {
+		return nil, err
+	}
+	content, err := s.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	stored, ok := gameOf(content)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	g := stored.checkout()
+	g.engine = e
+	return g, nil
+}
+
+// Returns every game in the store, oldest first, and the ids of the entries
+// that could not be read as games. Entries removed while loading are left out.
+// The games are shared with the store and must not be changed.
+func (e *Engine) loadAll(ctx context.Context) ([]*wordleGame, []string, error) {
+	s, err := e.store()
+	if err != nil {
+		return nil, nil, err
+	}
+	ids, err := s.Keys(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	games := make([]*wordleGame, 0, len(ids))
+	unreadable := []string{}
+	for _, id := range ids {
+		content, err := s.Load(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if content == nil {
+			continue
+		}
+		g, ok := gameOf(content)
+		if !ok {
+			unreadable = append(unreadable, id)
+			continue
+		}
+		games = append(games, g)
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].Created.Before(games[j].Created) })
+	if len(unreadable) > 0 {
+		logging.Warn(ctx, "games could not be read", "ids", unreadable)
+	}
+
+	return games, unreadable, nil
+}
@@ -0,0 +1,214 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name   string
+		result GameStatusType
+		err    error
+	}{
+		{name: "InPlay", result: InPlay},
+		{name: "won", result: Won},
+		{name: "LOST", result: Lost},
+		{name: "resigned", result: Resigned},
+		{name: "", err: ErrInvalidStatus},
+		{name: "paused", err: ErrInvalidStatus},
+	}
+
+	for _, test := range tests {
+		status, err := ParseStatus(test.name)
+		assert.Equal(test.err, err, test.name)
+		if err == nil {
+			assert.Equal(test.result, status, test.name)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	start := time.Now()
+	ids := map[string]string{}
+	for _, name := range []string{"classic", "timed", "resigned"} {
+		opts := []Option{}
+		if name == "timed" {
+			opts = append(opts, WithMode(Timed))
+		}
+		g, err := Create(ctx, "happy", opts...)
+		require.NoError(err)
+		if name == "resigned" {
+			_, err = g.Resign(ctx)
+			require.NoError(err)
+		}
+		ids[name] = g.(*wordleGame).Id
+	}
+	mid := time.Now()
+	g, err := Create(ctx, "happy")
+	require.NoError(err)
+	ids["later"] = g.(*wordleGame).Id
+
+	tests := []struct {
+		filter Filter
+		result []string
+	}{
+		{filter: Filter{CreatedAfter: start}, result: []string{"classic", "timed", "resigned", "later"}},
+		{filter: Filter{CreatedAfter: start, CreatedBefore: mid}, result: []string{"classic", "timed", "resigned"}},
+		{filter: Filter{CreatedAfter: start, Modes: []GameMode{Timed}}, result: []string{"timed"}},
+		{filter: Filter{CreatedAfter: start, Statuses: []GameStatusType{Resigned, Won}}, result: []string{"resigned"}},
+		{filter: Filter{CreatedAfter: start, Statuses: []GameStatusType{InPlay}, Modes: []GameMode{Classic}}, result: []string{"classic", "later"}},
+		{filter: Filter{CreatedAfter: time.Now()}, result: []string{}},
+	}
+
+	for _, test := range tests {
+		list, err := List(ctx, test.filter)
+		require.NoError(err)
+		got := []string{}
+		for _, s := range list {
+			got = append(got, s.Id)
+		}
+		want := []string{}
+		for _, name := range test.result {
+			want = append(want, ids[name])
+		}
+		assert.Equal(want, got, test.filter)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	g, err := Create(ctx, "happy", WithOwner("player:p1"))
+	require.NoError(err)
+	id := g.(*wordleGame).Id
+
+	s, err := Inspect(ctx, id)
+	require.NoError(err)
+	out := map[string]interface{}{}
+	require.NoError(json.Unmarshal([]byte(s), &out))
+	assert.Equal("HAPPY", out["secretWord"])
+	assert.Equal("player:p1", out["owner"])
+
+	_, err = Inspect(ctx, "missing")
+	assert.ErrorIs(err, ErrNotFound)
+}
+
+func TestForceResignAndDelete(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	g, err := Create(ctx, "happy")
+	require.NoError(err)
+	id := g.(*wordleGame).Id
+
+	s, err := ForceResign(ctx, id)
+	require.NoError(err)
+	assert.Contains(s, `"gameStatus":"Resigned"`)
+	_, err = ForceResign(ctx, id)
+	assert.ErrorIs(err, ErrGameOver)
+
+	require.NoError(Delete(ctx, id))
+	assert.ErrorIs(Delete(ctx, id), ErrNotFound)
+	_, err = ForceResign(ctx, id)
+	assert.ErrorIs(err, ErrNotFound)
+}
+
+func TestStatisticsAndPurge(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	require.NoError(PurgeAll(ctx))
+	stats, err := Statistics(ctx)
+	require.NoError(err)
+	assert.Zero(stats.Games)
+	assert.Nil(stats.Oldest)
+
+	for _, mode := range []GameMode{Classic, Classic, SpeedRun} {
+		_, err := Create(ctx, "happy", WithMode(mode))
+		require.NoError(err)
+	}
+	g, err := Create(ctx, "happy")
+	require.NoError(err)
+	_, err = g.Play(ctx, "happy")
+	require.NoError(err)
+
+	stats, err = Statistics(ctx)
+	require.NoError(err)
+	assert.Equal(4, stats.Games)
+	assert.Equal(4, stats.Keys)
+	assert.Equal(map[string]int{"InPlay": 3, "Won": 1}, stats.ByStatus)
+	assert.Equal(map[string]int{"Classic": 3, "SpeedRun": 1}, stats.ByMode)
+	assert.Equal(map[string]int{"GameCreated": 4, "GuessSubmitted": 1, "GameWon": 1}, stats.ByEvent)
+	require.NotNil(stats.Oldest)
+	assert.False(stats.Newest.Before(*stats.Oldest))
+
+	require.NoError(PurgeAll(ctx))
+	stats, err = Statistics(ctx)
+	require.NoError(err)
+	assert.Zero(stats.Games)
+}
+
+func TestHistory(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	g, err := Create(ctx, "happy")
+	require.NoError(err)
+	id := g.(*wordleGame).Id
+	_, err = g.Play(ctx, "puppy")
+	require.NoError(err)
+	_, err = g.Resign(ctx)
+	require.NoError(err)
+
+	events, err := History(ctx, id)
+	require.NoError(err)
+	require.Len(events, 3)
+	assert.Equal(GameResigned, events[2].Type)
+
+	tests := []struct {
+		n      int
+		status string
+		used   float64
+		err    error
+	}{
+		{n: 1, status: "InPlay", used: 0},
+		{n: 2, status: "InPlay", used: 1},
+		{n: 3, status: "Resigned", used: 1},
+		{n: 0, err: ErrEventIndex},
+		{n: 4, err: ErrEventIndex},
+	}
+
+	for _, test := range tests {
+		s, err := InspectAt(ctx, id, test.n)
+		assert.Equal(test.err, err, test.n)
+		if err != nil {
+			continue
+		}
+		out := map[string]interface{}{}
+		require.NoError(json.Unmarshal([]byte(s), &out))
+		assert.Equal(test.status, out["gameStatus"], test.n)
+		assert.Equal(test.used, out["attemptsUsed"], test.n)
+		assert.Equal("HAPPY", out["secretWord"], test.n)
+		assert.NotContains(out, "events")
+	}
+
+	_, err = History(ctx, "missing")
+	assert.ErrorIs(err, ErrNotFound)
+}
@@ -0,0 +1,111 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+
+	"aluance.io/wordleserver/internal/solver"
+	"aluance.io/wordleserver/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Breakdown of a single attempt, judged against the dictionary
+type AttemptAnalysis struct {
+	Attempt             int     `json:"attempt"`
+	TryWord             string  `json:"tryWord"`
+	IsValidWord         bool    `json:"isValidWord"`
+	CandidatesBefore    int     `json:"candidatesBefore"`
+	CandidatesAfter     int     `json:"candidatesAfter"`
+	InformationGained   float64 `json:"informationGained"`   // bits actually gained
+	ExpectedInformation float64 `json:"expectedInformation"` // bits the guess was expected to gain
+	BestGuess           string  `json:"bestGuess"`
+	BestInformation     float64 `json:"bestInformation"` // bits the best guess was expected to gain
+	Skill               float64 `json:"skill"`           // expected information as a percentage of the best
+	Luck                float64 `json:"luck"`            // bits gained over (or under) the expectation
+}
+
+// Returns an analysis of every attempt. Only finished games whose secret word
+// may be revealed can be analysed, as the analysis gives away the remaining
+// candidates.
+func (g wordleGame) Analyze(ctx context.Context) (_ string, err error) {
+	_, span := tracing.Start(ctx, "game.Analyze", attribute.String("game.id", g.Id))
+	defer func() { tracing.End(span, err) }()
+
+	if g.Status == InPlay {
+		return g.statusReport(), ErrGameInPlay
+	}
+	if g.secretHidden(g.now()) {
+		return g.statusReport(), ErrPeriodOpen
+	}
+
+	analysis, err := g.analyze()
+	if err != nil {
+		return g.statusReport(), err
+	}
+
+	report := struct {
+		Id         string             `json:"id"`
+		Status     GameStatusType     `json:"gameStatus"`
+		SecretWord string             `json:"secretWord"`
+		Attempts   []*AttemptAnalysis `json:"attempts"`
+	}{
+		Id:         g.Id,
+		Status:     g.Status,
+		SecretWord: g.SecretWord,
+		Attempts:   analysis,
+	}
+
+	b, err := json.Marshal(report)
+	if err != nil {
+		return "{}", ErrSerialization
+	}
+
+	return string(b), nil
+}
+
+/////////////
+
+func (g wordleGame) analyze() ([]*AttemptAnalysis, error) {
+	s, err := g.eng().wordleSolver()
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := []*AttemptAnalysis{}
+	guesses := []solver.Guess{}
+	for i, a := range g.Attempts {
+		aa := &AttemptAnalysis{Attempt: i + 1, TryWord: a.TryWord, IsValidWord: a.IsValidWord}
+		analysis = append(analysis, aa)
+
+		candidates, best := s.Suggest(guesses, solver.Entropy, 1)
+		aa.CandidatesBefore = len(candidates)
+		aa.CandidatesAfter = len(candidates)
+
+		// Invalid words are not scored, so they tell the player nothing
+		if !a.IsValidWord {
+			continue
+		}
+
+		guess := solver.Guess{Word: a.TryWord, Pattern: patternOf(a.TryResult)}
+		guesses = append(guesses, guess)
+		aa.CandidatesAfter = len(s.Candidates(guesses))
+
+		aa.ExpectedInformation = s.Evaluate(a.TryWord, candidates).Entropy
+		if aa.CandidatesBefore > 0 && aa.CandidatesAfter > 0 {
+			aa.InformationGained = math.Log2(float64(aa.CandidatesBefore) / float64(aa.CandidatesAfter))
+		}
+		aa.Luck = aa.InformationGained - aa.ExpectedInformation
+
+		aa.Skill = 100
+		if len(best) > 0 {
+			aa.BestGuess = best[0].Word
+			aa.BestInformation = best[0].Entropy
+			if best[0].Entropy > 0 {
+				aa.Skill = math.Min(100, 100*aa.ExpectedInformation/best[0].Entropy)
+			}
+		}
+	}
+
+	return analysis, nil
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	game, err := Create(ctx, "happy")
+	require.NoError(err, "Create() returned error when creating Game")
+
+	// Not available while the game is in play
+	_, err = game.Analyze(ctx)
+	assert.ErrorIs(err, ErrGameInPlay)
+
+	for _, w := range []string{"bless", "zzzzz", "puppy", "happy"} {
+		game.Play(ctx, w)
+	}
+
+	s, err := game.Analyze(ctx)
+	require.NoError(err)
+
+	out := struct {
+		Status     string             `json:"gameStatus"`
+		SecretWord string             `json:"secretWord"`
+		Attempts   []*AttemptAnalysis `json:"attempts"`
+	}{}
+	require.NoError(json.Unmarshal([]byte(s), &out))
+	assert.Equal("Won", out.Status)
+	assert.Equal("HAPPY", out.SecretWord)
+	require.Len(out.Attempts, 4)
+
+	for i, a := range out.Attempts {
+		assert.Equal(i+1, a.Attempt)
+		assert.LessOrEqual(a.CandidatesAfter, a.CandidatesBefore, a.TryWord)
+		assert.GreaterOrEqual(a.CandidatesAfter, 1, "the secret word is always a candidate")
+		assert.GreaterOrEqual(a.Skill, 0.0)
+		assert.LessOrEqual(a.Skill, 100.0)
+		if i > 0 {
+			assert.Equal(out.Attempts[i-1].CandidatesAfter, a.CandidatesBefore)
+		}
+	}
+
+	// The invalid word gave nothing away
+	invalid := out.Attempts[1]
+	assert.False(invalid.IsValidWord)
+	assert.Equal(invalid.CandidatesBefore, invalid.CandidatesAfter)
+	assert.Zero(invalid.InformationGained)
+	assert.Empty(invalid.BestGuess)
+
+	// The winning guess leaves exactly one candidate
+	last := out.Attempts[3]
+	assert.Equal(1, last.CandidatesAfter)
+	assert.NotEmpty(last.BestGuess)
+	assert.InDelta(last.InformationGained-last.ExpectedInformation, last.Luck, 1e-9)
+}
@@ -0,0 +1,192 @@
+package game
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aluance.io/wordleserver/internal/store"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Keeps games in an in-process Redis server until the test is over
+func useRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	store.Use(store.NewRedisStore(client, store.REDIS_KEY_PREFIX, time.Hour))
+	t.Cleanup(func() {
+		store.Use(nil)
+		client.Close()
+	})
+}
+
+// Keeps games in an in-memory SQLite database until the test is over
+func useSqlite(t *testing.T) *sql.DB {
+	db, err := sql.Open(store.SQL_SQLITE, ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	s, err := store.NewSqlStore(context.Background(), db, store.SQL_SQLITE)
+	require.NoError(t, err)
+	store.Use(s)
+	t.Cleanup(func() {
+		store.Use(nil)
+		db.Close()
+	})
+	return db
+}
+
+// Keeps games in a bolt database until the test is over
+func useBolt(t *testing.T) {
+	shutdown, err := store.Setup(context.Background(), store.STORE_BOLT, filepath.Join(t.TempDir(), "wordle.bolt"), 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { shutdown() })
+}
+
+func TestRedisBackend(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+	useRedis(t)
+
+	g, err := Create(ctx, "happy", WithOwner("player:redis"))
+	require.NoError(err)
+	id := g.(*wordleGame).Id
+
+	// Two copies of the game, the second one out of date once the first plays
+	first, err := Retrieve(ctx, id)
+	require.NoError(err)
+	second, err := Retrieve(ctx, id)
+	require.NoError(err)
+	_, err = first.Play(ctx, "puppy")
+	require.NoError(err)
+	_, err = second.Play(ctx, "lucky")
+	assert.ErrorIs(err, ErrConflict)
+
+	again, err := Retrieve(ctx, id)
+	require.NoError(err)
+	assert.Equal(first.Version(), again.Version())
+	_, err = again.Play(ctx, "happy")
+	require.NoError(err)
+
+	won, err := Retrieve(ctx, id)
+	require.NoError(err)
+	v := won.(*wordleGame)
+	assert.Equal(Won, v.Status)
+	assert.Equal(2, v.ValidAttempts)
+	assert.Equal("HAPPY", v.SecretWord)
+
+	list, next, err := Games(ctx, Query{Owner: "player:redis"})
+	require.NoError(err)
+	assert.Empty(next)
+	require.Len(list, 1)
+	assert.Equal(id, list[0].Id)
+	assert.Equal(Won, list[0].Status)
+
+	events, err := History(ctx, id)
+	require.NoError(err)
+	assert.Len(events, 4)
+
+	require.NoError(Delete(ctx, id))
+	_, err = Inspect(ctx, id)
+	assert.ErrorIs(err, ErrNotFound)
+}
+
+func TestSqlBackend(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+	db := useSqlite(t)
+
+	ids := []string{}
+	for i := 0; i < 3; i++ {
+		g, err := Create(ctx, "happy", WithOwner("player:sql"))
+		require.NoError(err)
+		ids = append([]string{g.(*wordleGame).Id}, ids...)
+	}
+
+	first, err := Retrieve(ctx, ids[0])
+	require.NoError(err)
+	second, err := Retrieve(ctx, ids[0])
+	require.NoError(err)
+	_, err = first.Play(ctx, "puppy")
+	require.NoError(err)
+	_, err = second.Play(ctx, "lucky")
+	assert.ErrorIs(err, ErrConflict)
+	_, err = first.Play(ctx, "happy")
+	require.NoError(err)
+
+	won, err := Retrieve(ctx, ids[0])
+	require.NoError(err)
+	assert.Equal(Won, won.(*wordleGame).Status)
+	assert.Equal(first.Version(), won.Version())
+
+	// Games come back newest first, one page at a time
+	got := []string{}
+	q := Query{Owner: "player:sql", Limit: 1}
+	for i := 0; i < len(ids)+1; i++ {
+		list, next, err := Games(ctx, q)
+		require.NoError(err)
+		for _, s := range list {
+			got = append(got, s.Id)
+		}
+		if len(next) < 1 {
+			break
+		}
+		q.Cursor = next
+	}
+	assert.Equal(ids, got)
+
+	// The tables can be queried directly
+	var status, mode string
+	var attempts int
+	require.NoError(db.QueryRow(`SELECT g.status, g.mode, COUNT(a.number) FROM games g
+		JOIN attempts a ON a.game_id = g.id WHERE g.id = ? GROUP BY g.status, g.mode`, ids[0]).Scan(&status, &mode, &attempts))
+	assert.Equal("Won", status)
+	assert.Equal("Classic", mode)
+	assert.Equal(2, attempts)
+	var result string
+	require.NoError(db.QueryRow("SELECT result FROM attempts WHERE game_id = ? AND number = 2", ids[0]).Scan(&result))
+	assert.Equal("Green,Green,Green,Green,Green", result)
+}
+
+func TestBoltBackend(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+	useBolt(t)
+
+	g, err := Create(ctx, "happy", WithOwner("player:bolt"))
+	require.NoError(err)
+	id := g.(*wordleGame).Id
+
+	first, err := Retrieve(ctx, id)
+	require.NoError(err)
+	second, err := Retrieve(ctx, id)
+	require.NoError(err)
+	_, err = first.Play(ctx, "puppy")
+	require.NoError(err)
+	_, err = second.Play(ctx, "lucky")
+	assert.ErrorIs(err, ErrConflict)
+	_, err = second.Resign(ctx)
+	assert.ErrorIs(err, ErrConflict)
+
+	again, err := Retrieve(ctx, id)
+	require.NoError(err)
+	_, err = again.Resign(ctx)
+	require.NoError(err)
+
+	list, _, err := Games(ctx, Query{Owner: "player:bolt"})
+	require.NoError(err)
+	require.Len(list, 1)
+	assert.Equal(Resigned, list[0].Status)
+
+	stats, err := Statistics(ctx)
+	require.NoError(err)
+	assert.Equal(1, stats.Games)
+	assert.Equal(map[string]int{"GameCreated": 1, "GuessSubmitted": 1, "GameResigned": 1}, stats.ByEvent)
+}
@@ -0,0 +1,188 @@
+package game
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"aluance.io/wordleserver/internal/dictionary"
+	"aluance.io/wordleserver/internal/solver"
+	"aluance.io/wordleserver/internal/store"
+	"github.com/rs/xid"
+)
+
+// Tells the time to an engine
+type Clock interface {
+	Now() time.Time
+}
+
+// Words an engine plays with. *dictionary.Dictionary is one.
+type Dictionary interface {
+	GenerateWord(ctx context.Context, r *rand.Rand) (string, error)
+	DailyWord(ctx context.Context, day time.Time) (string, error)
+	IsWordValid(ctx context.Context, w string) bool
+	Words() ([]string, error)
+}
+
+// Creates and plays games with a dictionary, store, clock and random source
+// of its own, so the game can be embedded without the API or any global
+// state. The package functions use an engine on the shared dictionary and
+// the store in use.
+type Engine struct {
+	dictionary  func() (Dictionary, error)
+	store       func() (store.Store, error)
+	clock       Clock
+	clockMutex  sync.RWMutex
+	rng         *rand.Rand // the shared source when nil
+	seeded      bool       // game ids are drawn from rng
+	rngMutex    sync.Mutex
+	solver      *solver.Solver
+	solverMutex sync.Mutex
+}
+
+// Returns an engine that plays with the words in dict and keeps games in s.
+// Left nil, dict is a copy of the built-in dictionary, s a store in memory,
+// clock the system clock and rng a source seeded from the time. Given an rng,
+// the engine draws game ids from it too, so that the same seed plays the same
+// games again.
+func NewEngine(dict Dictionary, s store.Store, clock Clock, rng *rand.Rand) (*Engine, error) {
+	if dict == nil {
+		d, err := dictionary.Load("")
+		if err != nil {
+			return nil, err
+		}
+		dict = d
+	}
+	if s == nil {
+		s = store.NewMemoryStore()
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	seeded := rng != nil
+	if !seeded {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return &Engine{
+		dictionary: func() (Dictionary, error) { return dict, nil },
+		store:      func() (store.Store, error) { return s, nil },
+		clock:      clock,
+		rng:        rng,
+		seeded:     seeded,
+	}, nil
+}
+
+// Replaces the clock of the package functions. A nil clock is the system
+// clock.
+func SetClock(clock Clock) {
+	defaultEngine.setClock(clock)
+}
+
+// Replaces the random source the package functions pick words and game ids
+// with, so that the same seed plays the same games again. A nil r goes back
+// to the shared source of the dictionary and random ids.
+func SetRand(r *rand.Rand) {
+	defaultEngine.rngMutex.Lock()
+	defer defaultEngine.rngMutex.Unlock()
+	defaultEngine.rng = r
+	defaultEngine.seeded = r != nil
+}
+
+/////////////
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Engine of the package functions, on whatever dictionary and store are in
+// use when it is called
+var defaultEngine = &Engine{
+	dictionary: sharedDictionary,
+	store:      store.WordleStore,
+	clock:      systemClock{},
+}
+
+func sharedDictionary() (Dictionary, error) {
+	d, err := dictionary.Shared()
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Returns the engine the game was created or retrieved with
+func (g wordleGame) eng() *Engine {
+	if g.engine != nil {
+		return g.engine
+	}
+	return defaultEngine
+}
+
+func (g wordleGame) now() time.Time {
+	return g.eng().now()
+}
+
+func (e *Engine) now() time.Time {
+	e.clockMutex.RLock()
+	defer e.clockMutex.RUnlock()
+	return e.clock.Now()
+}
+
+func (e *Engine) setClock(clock Clock) {
+	e.clockMutex.Lock()
+	defer e.clockMutex.Unlock()
+	if clock == nil {
+		clock = systemClock{}
+	}
+	e.clock = clock
+}
+
+// Returns the id of a new game, drawn from the random source when it is
+// seeded
+func (e *Engine) newId() string {
+	e.rngMutex.Lock()
+	defer e.rngMutex.Unlock()
+	if !e.seeded {
+		return xid.New().String()
+	}
+
+	b := make([]byte, len(xid.NilID()))
+	e.rng.Read(b)
+	id, _ := xid.FromBytes(b)
+	return id.String()
+}
+
+// Returns a word picked with the random source of the engine
+func (e *Engine) generateWord(ctx context.Context, d Dictionary) (string, error) {
+	e.rngMutex.Lock()
+	defer e.rngMutex.Unlock()
+	return d.GenerateWord(ctx, e.rng)
+}
+
+// Returns the solver for the dictionary, creating it on first use.
+func (e *Engine) wordleSolver() (*solver.Solver, error) {
+	e.solverMutex.Lock()
+	defer e.solverMutex.Unlock()
+
+	if e.solver == nil {
+		d, err := e.dictionary()
+		if err != nil {
+			return nil, err
+		}
+		words, err := d.Words()
+		if err != nil {
+			return nil, err
+		}
+		for i := range words {
+			words[i] = strings.ToUpper(words[i])
+		}
+		e.solver = solver.New(words, scorePattern)
+	}
+
+	return e.solver, nil
+}
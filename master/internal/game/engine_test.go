@@ -0,0 +1,232 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/dictionary"
+	"aluance.io/wordleserver/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Clock that only moves when told to
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// Returns an engine on a small dictionary of its own
+func newTestEngine(t *testing.T, clock Clock, seed int64) *Engine {
+	d, err := dictionary.New(strings.NewReader("happy\npuppy\nlucky\nfunny\nsunny\nbunny\n"))
+	require.NoError(t, err)
+	e, err := NewEngine(d, store.NewMemoryStore(), clock, rand.New(rand.NewSource(seed)))
+	require.NoError(t, err)
+	return e
+}
+
+func TestEngine(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	clock := &testClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	e := newTestEngine(t, clock, 1)
+
+	g, err := e.Create(ctx, "", WithMode(Timed))
+	require.NoError(err)
+	id := g.(*wordleGame).Id
+	assert.Equal(clock.now, g.(*wordleGame).Created)
+
+	// The game is only in the store of the engine
+	_, err = Retrieve(ctx, id)
+	assert.Error(err)
+	retrieved, err := e.Retrieve(ctx, id)
+	require.NoError(err)
+	assert.Equal(g.Version(), retrieved.Version())
+
+	// Words are checked against the dictionary of the engine
+	_, err = retrieved.Play(ctx, "crane")
+	assert.Equal(ErrInvalidWord, err)
+	guess := "bunny"
+	if retrieved.(*wordleGame).SecretWord == "BUNNY" {
+		guess = "sunny"
+	}
+	_, err = retrieved.Play(ctx, guess)
+	require.NoError(err)
+
+	// Hints come from the same words
+	out, err := retrieved.Hint(ctx, "")
+	require.NoError(err)
+	hint := struct {
+		Suggestions []struct{ Word string }
+	}{}
+	require.NoError(json.Unmarshal([]byte(out), &hint))
+	require.NotEmpty(hint.Suggestions)
+	assert.Contains([]string{"HAPPY", "PUPPY", "LUCKY", "FUNNY", "SUNNY", "BUNNY"}, hint.Suggestions[0].Word)
+
+	// Time is told by the clock of the engine
+	clock.now = clock.now.Add(config.CONFIG_GAME_GUESSTIMEOUT + time.Second)
+	_, err = retrieved.Play(ctx, guess)
+	assert.Equal(ErrGameOver, err)
+	retrieved, err = e.Retrieve(ctx, id)
+	require.NoError(err)
+	assert.Equal(Lost, retrieved.(*wordleGame).Status)
+	assert.True(retrieved.(*wordleGame).TimedOut)
+
+	attempts, err := e.Solve(ctx, "funny", "")
+	require.NoError(err)
+	assert.Equal("FUNNY", attempts[len(attempts)-1].TryWord)
+	token, err := e.NewPuzzle(ctx, "lucky")
+	require.NoError(err)
+	_, err = e.NewPuzzle(ctx, "crane")
+	assert.Equal(ErrInvalidWord, err)
+	g, err = e.CreateFromPuzzle(ctx, token)
+	require.NoError(err)
+	assert.Equal("LUCKY", g.(*wordleGame).SecretWord)
+}
+
+func TestEngineRandom(t *testing.T) {
+	ctx := context.Background()
+
+	// Engines with the same seed pick the same words and ids
+	games := func(seed int64) []string {
+		e := newTestEngine(t, nil, seed)
+		out := []string{}
+		for i := 0; i < 10; i++ {
+			g, err := e.Create(ctx, "")
+			require.NoError(t, err)
+			out = append(out, g.(*wordleGame).Id+" "+g.(*wordleGame).SecretWord)
+		}
+		return out
+	}
+	assert.Equal(t, games(42), games(42))
+	assert.NotEqual(t, games(42), games(43))
+}
+
+func TestSetRand(t *testing.T) {
+	ctx := context.Background()
+	defer SetRand(nil)
+
+	// The package functions play the same games again after a seed
+	games := func() []string {
+		store.Use(store.NewMemoryStore())
+		defer store.Use(nil)
+		SetRand(rand.New(rand.NewSource(42)))
+		out := []string{}
+		for i := 0; i < 10; i++ {
+			g, err := Create(ctx, "")
+			require.NoError(t, err)
+			out = append(out, g.(*wordleGame).Id+" "+g.(*wordleGame).SecretWord)
+		}
+		return out
+	}
+	assert.Equal(t, games(), games())
+}
+
+func TestSetClock(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	clock := &testClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	SetClock(clock)
+	defer SetClock(nil)
+	store.Use(store.NewMemoryStore())
+	defer store.Use(nil)
+
+	g, err := Create(ctx, "happy", WithMode(Timed), WithOwner("qa"))
+	require.NoError(err)
+	id := g.(*wordleGame).Id
+	assert.Equal(clock.now, g.(*wordleGame).Created)
+	count, err := CountInPlay(ctx, "qa")
+	require.NoError(err)
+	assert.Equal(1, count)
+
+	// Games run out of time by the clock, not by the time it took
+	clock.now = clock.now.Add(config.CONFIG_GAME_GUESSTIMEOUT + time.Second)
+	count, err = CountInPlay(ctx, "qa")
+	require.NoError(err)
+	assert.Zero(count)
+	count, err = Sweep(ctx)
+	require.NoError(err)
+	assert.Equal(1, count)
+	assert.Equal(Lost, stored(t, id).Status)
+}
+
+func TestNewEngine(t *testing.T) {
+	ctx := context.Background()
+
+	e, err := NewEngine(nil, nil, nil, nil)
+	require.NoError(t, err)
+	g, err := e.Create(ctx, "")
+	require.NoError(t, err)
+	_, err = e.Retrieve(ctx, g.(*wordleGame).Id)
+	assert.NoError(t, err)
+}
+
+func TestEngineStores(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	clock := &testClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	e1, e2 := newTestEngine(t, clock, 1), newTestEngine(t, clock, 2)
+	g1, err := e1.Create(ctx, "happy", WithMode(Timed), WithOwner("qa"))
+	require.NoError(err)
+	id1 := g1.(*wordleGame).Id
+	g2, err := e2.Create(ctx, "lucky", WithOwner("qa"))
+	require.NoError(err)
+	id2 := g2.(*wordleGame).Id
+
+	// Each engine only sees the games in its own store
+	list, err := e1.List(ctx, Filter{})
+	require.NoError(err)
+	require.Len(list, 1)
+	assert.Equal(id1, list[0].Id)
+	page, _, err := e2.Games(ctx, Query{Owner: "qa"})
+	require.NoError(err)
+	require.Len(page, 1)
+	assert.Equal(id2, page[0].Id)
+	count, err := e1.CountInPlay(ctx, "qa")
+	require.NoError(err)
+	assert.Equal(1, count)
+	_, err = e1.Inspect(ctx, id2)
+	assert.Error(err)
+	assert.Equal(ErrNotFound, e1.Delete(ctx, id2))
+	_, err = e2.ForceResign(ctx, id2)
+	require.NoError(err)
+
+	// Sweeps end the games of the engine by its clock
+	clock.now = clock.now.Add(config.CONFIG_GAME_GUESSTIMEOUT + time.Second)
+	count, err = e1.Sweep(ctx)
+	require.NoError(err)
+	assert.Equal(1, count)
+	events, err := e1.History(ctx, id1)
+	require.NoError(err)
+	assert.Equal(GameLost, events[len(events)-1].Type)
+
+	// Games move between engines through snapshots
+	var snapshot bytes.Buffer
+	n, err := e1.Export(ctx, &snapshot, SNAPSHOT_JSONL)
+	require.NoError(err)
+	assert.Equal(1, n)
+	_, err = e2.Import(ctx, &snapshot, false)
+	require.NoError(err)
+	stats, err := e2.Statistics(ctx)
+	require.NoError(err)
+	assert.Equal(2, stats.Games)
+	require.NoError(e1.PurgeAll(ctx))
+	stats, err = e2.Statistics(ctx)
+	require.NoError(err)
+	assert.Equal(2, stats.Games)
+}
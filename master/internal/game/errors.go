@@ -3,11 +3,27 @@ package game
 import "errors"
 
 var (
-	ErrSerialization = errors.New("game serialization error")
-	ErrGameOver      = errors.New("game is finished")
-	ErrOutOfTurns    = errors.New("out of turns")
-	ErrNilResult     = errors.New("nil result provided")
-	ErrWordLength    = errors.New("invalid word length")
-	ErrInvalidWord   = errors.New("word is not in dictionary")
+	ErrSerialization  = errors.New("game serialization error")
+	ErrGameOver       = errors.New("game is finished")
+	ErrGameInPlay     = errors.New("game is still in play")
+	ErrOutOfTurns     = errors.New("out of turns")
+	ErrNilResult      = errors.New("nil result provided")
+	ErrWordLength     = errors.New("invalid word length")
+	ErrInvalidWord    = errors.New("word is not in dictionary")
+	ErrHintsDisabled  = errors.New("hints are disabled for this game")
+	ErrHintLimit      = errors.New("no hints left for this game")
+	ErrHintCooldown   = errors.New("hint requested too soon")
+	ErrInvalidMode    = errors.New("invalid game mode")
+	ErrDailyWord      = errors.New("daily games cannot have a chosen word")
+	ErrPeriodOpen     = errors.New("puzzle period has not closed")
+	ErrInvalidStatus  = errors.New("invalid game status")
+	ErrNotFound       = errors.New("game not found")
+	ErrHistory        = errors.New("invalid game history")
+	ErrEventIndex     = errors.New("no such event")
+	ErrConflict       = errors.New("game was changed by another request")
+	ErrKeyReused      = errors.New("idempotency key was used for another guess")
+	ErrSnapshot       = errors.New("invalid snapshot")
+	ErrSnapshotFormat = errors.New("invalid snapshot format")
+	ErrUnreadable     = errors.New("games in the store could not be read")
 	// ErrInvalidId     = errors.New("invalid id")
 )
@@ -0,0 +1,175 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"aluance.io/wordleserver/internal/config"
+)
+
+// Game event enum
+type EventType int
+
+const (
+	GameCreated    EventType = iota // Word is the secret word, Setup the options
+	GuessSubmitted                  // Word is the guess, Result its score
+	GuessRejected                   // Word is the guess, Reason why it was rejected
+	HintGiven
+	GameWon
+	GameLost // Reason is LOST_OUT_OF_TURNS or LOST_TIMED_OUT
+	GameResigned
+)
+
+// Reasons a game is lost
+const LOST_OUT_OF_TURNS = "outOfTurns"
+const LOST_TIMED_OUT = "timedOut"
+
+// Something that happened to a game. A game keeps all of its events in order
+// and its state is what applying them one after the other gives.
+type Event struct {
+	Type   EventType    `json:"type"`
+	Time   time.Time    `json:"time"`
+	Word   string       `json:"word,omitempty"`
+	Result []LetterHint `json:"result,omitempty"`
+	Reason string       `json:"reason,omitempty"`
+	Setup  *Setup       `json:"setup,omitempty"`
+	Key    string       `json:"key,omitempty"` // idempotency key of the play that caused it
+}
+
+// Options a game was created with
+type Setup struct {
+	Id            string    `json:"id"`
+	Mode          GameMode  `json:"mode"`
+	GuessTimeout  int       `json:"guessTimeout,omitempty"`
+	TimeLimit     int       `json:"timeLimit,omitempty"`
+	Puzzle        bool      `json:"puzzle,omitempty"`
+	PeriodEnd     time.Time `json:"periodEnd"`
+	HintsDisabled bool      `json:"hintsDisabled,omitempty"`
+	Owner         string    `json:"owner,omitempty"`
+}
+
+func (t EventType) String() string {
+	if s, ok := mapEventTypeToString[t]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+func (t EventType) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString(`"`)
+	buf.WriteString(mapEventTypeToString[t])
+	buf.WriteString(`"`)
+	return buf.Bytes(), nil
+}
+
+func (t *EventType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	*t = mapStringToEventType[s]
+	return nil
+}
+
+/////////////
+
+var mapEventTypeToString = map[EventType]string{
+	GameCreated:    "GameCreated",
+	GuessSubmitted: "GuessSubmitted",
+	GuessRejected:  "GuessRejected",
+	HintGiven:      "HintGiven",
+	GameWon:        "GameWon",
+	GameLost:       "GameLost",
+	GameResigned:   "GameResigned",
+}
+
+var mapStringToEventType = map[string]EventType{
+	"GameCreated":    GameCreated,
+	"GuessSubmitted": GuessSubmitted,
+	"GuessRejected":  GuessRejected,
+	"HintGiven":      HintGiven,
+	"GameWon":        GameWon,
+	"GameLost":       GameLost,
+	"GameResigned":   GameResigned,
+}
+
+// Returns the game that events describe
+func rebuild(events []Event) (*wordleGame, error) {
+	if len(events) < 1 || events[0].Type != GameCreated || events[0].Setup == nil {
+		return nil, ErrHistory
+	}
+
+	g := &wordleGame{}
+	for _, e := range events {
+		g.record(e)
+	}
+	return g, nil
+}
+
+// Applies e to the game and adds it to the history. This is the only place
+// the state of a game changes once it has been created.
+func (g *wordleGame) record(e Event) {
+	g.apply(e)
+	g.Events = append(g.Events, e)
+}
+
+func (g *wordleGame) apply(e Event) {
+	switch e.Type {
+	case GameCreated:
+		s := e.Setup
+		g.Id = s.Id
+		g.Mode = s.Mode
+		g.GuessTimeout = s.GuessTimeout
+		g.TimeLimit = s.TimeLimit
+		g.Puzzle = s.Puzzle
+		g.PeriodEnd = s.PeriodEnd
+		g.HintsDisabled = s.HintsDisabled
+		g.Owner = s.Owner
+		g.SecretWord = e.Word
+		g.Status = InPlay
+		g.Attempts = []*WordleAttempt{}
+		g.Created = e.Time
+	case GuessSubmitted:
+		g.Attempts = append(g.Attempts, &WordleAttempt{
+			TimeStamp:   e.Time,
+			TryWord:     e.Word,
+			IsValidWord: true,
+			TryResult:   append([]LetterHint{}, e.Result...),
+		})
+		g.ValidAttempts++
+	case GuessRejected:
+		g.Attempts = append(g.Attempts, &WordleAttempt{
+			TimeStamp: e.Time,
+			TryWord:   e.Word,
+			TryResult: make([]LetterHint, config.CONFIG_GAME_WORDLENGTH),
+		})
+	case HintGiven:
+		g.HintsUsed++
+		g.LastHint = e.Time
+	case GameWon:
+		g.Status = Won
+	case GameLost:
+		g.Status = Lost
+		g.TimedOut = e.Reason == LOST_TIMED_OUT
+	case GameResigned:
+		g.Status = Resigned
+	}
+	g.LastUpdated = e.Time
+	g.Revision++
+}
+
+// Returns the options of a game that has not been created yet
+func (g wordleGame) setup() *Setup {
+	return &Setup{
+		Id:            g.Id,
+		Mode:          g.Mode,
+		GuessTimeout:  g.GuessTimeout,
+		TimeLimit:     g.TimeLimit,
+		Puzzle:        g.Puzzle,
+		PeriodEnd:     g.PeriodEnd,
+		HintsDisabled: g.HintsDisabled,
+		Owner:         g.Owner,
+	}
+}
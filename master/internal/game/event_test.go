@@ -0,0 +1,125 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"aluance.io/wordleserver/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventTypeJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	for et, name := range mapEventTypeToString {
+		b, err := json.Marshal(et)
+		assert.NoError(err)
+		assert.Equal(`"`+name+`"`, string(b))
+
+		var out EventType
+		assert.NoError(json.Unmarshal(b, &out))
+		assert.Equal(et, out)
+		assert.Equal(name, et.String())
+	}
+	assert.Equal("unknown", EventType(99).String())
+}
+
+func TestRecordedEvents(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	game, err := Create(ctx, "happy", WithMode(Timed), WithOwner("player:p1"))
+	require.NoError(err)
+	v := game.(*wordleGame)
+	_, err = game.Play(ctx, "zzzzz")
+	assert.ErrorIs(err, ErrInvalidWord)
+	_, err = game.Play(ctx, "puppy")
+	require.NoError(err)
+	_, err = game.Hint(ctx, "")
+	require.NoError(err)
+	_, err = game.Play(ctx, "happy")
+	require.NoError(err)
+
+	types := []EventType{}
+	for _, e := range v.Events {
+		types = append(types, e.Type)
+	}
+	assert.Equal([]EventType{GameCreated, GuessRejected, GuessSubmitted, HintGiven, GuessSubmitted, GameWon}, types)
+	assert.Equal("HAPPY", v.Events[0].Word)
+	assert.Equal(ErrInvalidWord.Error(), v.Events[1].Reason)
+	assert.Equal([]LetterHint{Grey, Grey, Green, Green, Green}, v.Events[2].Result)
+
+	// The state is what the events describe, including once stored as JSON
+	b, err := json.Marshal(v)
+	require.NoError(err)
+	stored := wordleGame{}
+	require.NoError(json.Unmarshal(b, &stored))
+	for _, events := range [][]Event{v.Events, stored.Events} {
+		g, err := rebuild(events)
+		require.NoError(err)
+		rb, err := json.Marshal(g)
+		require.NoError(err)
+		assert.JSONEq(string(b), string(rb))
+	}
+}
+
+func TestRecordedLosses(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// Out of turns
+	game, err := Create(ctx, "happy")
+	require.NoError(err)
+	v := game.(*wordleGame)
+	for i := 0; i < config.CONFIG_GAME_MAXVALIDATTEMPTS; i++ {
+		game.Play(ctx, "puppy")
+	}
+	last := v.Events[len(v.Events)-1]
+	assert.Equal(GameLost, last.Type)
+	assert.Equal(LOST_OUT_OF_TURNS, last.Reason)
+	assert.False(v.TimedOut)
+
+	// Out of time, at the deadline
+	game, err = Create(ctx, "happy", WithMode(SpeedRun))
+	require.NoError(err)
+	v = game.(*wordleGame)
+	d := v.deadline()
+	assert.True(v.expire(ctx, d.Add(time.Minute)))
+	last = v.Events[len(v.Events)-1]
+	assert.Equal(GameLost, last.Type)
+	assert.Equal(LOST_TIMED_OUT, last.Reason)
+	assert.Equal(d, last.Time)
+
+	g, err := rebuild(v.Events)
+	require.NoError(err)
+	assert.True(g.TimedOut)
+	assert.Equal(d, g.LastUpdated)
+}
+
+func TestRebuild(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		events []Event
+		err    error
+	}{
+		{events: nil, err: ErrHistory},
+		{events: []Event{{Type: GuessSubmitted, Word: "HAPPY"}}, err: ErrHistory},
+		{events: []Event{{Type: GameCreated, Word: "HAPPY"}}, err: ErrHistory},
+		{events: []Event{{Type: GameCreated, Word: "HAPPY", Setup: &Setup{Id: "a"}}, {Type: GameResigned}}},
+	}
+
+	for _, test := range tests {
+		g, err := rebuild(test.events)
+		assert.Equal(test.err, err, test.events)
+		if err == nil {
+			assert.Equal(Resigned, g.Status)
+			assert.Len(g.Events, len(test.events))
+		}
+	}
+}
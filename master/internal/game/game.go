@@ -6,11 +6,32 @@ This package is intended to be exposed through a RESTful API.
 The primary interface is Game.
 
 Key functions:
-	Create(secretWord) - Returns a new game, where secretWord is the five-letter word to be guessed.
+	Create(ctx, secretWord) - Returns a new game, where secretWord is the five-letter word to be guessed.
+	CreateFromPuzzle(ctx, token) - Returns a new game for a token made by NewPuzzle(ctx, secretWord) (see puzzle.go).
 
-	Game.Play(tryWord)	- Attempt a guess by passing in a five-letter word. Returns hints for each letter in the guess.
-	Game.Resign() - End the game before winning or losing.
+	Game.Play(ctx, tryWord)	- Attempt a guess by passing in a five-letter word. Returns hints for each letter in the guess.
+	Game.Resign(ctx) - End the game before winning or losing.
 	Game.Describe() - Returns a represantation of the game object state (including the secret word).
+	Game.Hint(ctx, strategy) - Returns suggestions for the next guess (see hint.go).
+	Game.Analyze(ctx) - Returns a guess by guess analysis of a finished game (see analysis.go).
+	Game.Replay(ctx) - Returns the timeline of a finished game for playback (see replay.go).
+
+Timed and SpeedRun games (see mode.go) are lost when they run out of time. This
+is checked whenever a game is retrieved or played, and by the background
+sweeper started with StartSweeper.
+
+A play made with a context from WithIdempotencyKey can be repeated with the
+same key without using up another attempt (see idempotency.go).
+
+Every change to a game is recorded as an Event, and its state is what
+applying its events in order gives (see event.go).
+
+List, Inspect, History, InspectAt, ForceResign, Delete, PurgeAll and
+Statistics are meant for operators only, as they show secret words and owners
+(see admin.go).
+
+The context passed to each call carries the request id logged with every
+record and the span that the game, store and dictionary spans belong to.
 
 */
 
@@ -18,14 +39,17 @@ package game
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"strings"
 	"time"
 
 	"aluance.io/wordleserver/internal/config"
-	"aluance.io/wordleserver/internal/dictionary"
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/metrics"
 	"aluance.io/wordleserver/internal/store"
-	"github.com/rs/xid"
+	"aluance.io/wordleserver/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Game status enum
@@ -41,115 +65,244 @@ const (
 // Game interface
 type Game interface {
 	Describe() (string, error)
-	Play(tryWord string) (string, error)
-	Resign() (string, error)
+	Play(ctx context.Context, tryWord string) (string, error)
+	Resign(ctx context.Context) (string, error)
+	Hint(ctx context.Context, strategy string) (string, error)
+	Analyze(ctx context.Context) (string, error)
+	Replay(ctx context.Context) (string, error)
+	Version() int
+	Remembers(key string) bool
 	// State() (string, error)
 }
 
+// Option used to change the defaults of a new game
+type Option func(*wordleGame)
+
+// Turns off hints for the game
+func WithoutHints() Option {
+	return func(g *wordleGame) {
+		g.HintsDisabled = true
+	}
+}
+
+// Records the client that created the game. The owner is not shown to
+// players.
+func WithOwner(owner string) Option {
+	return func(g *wordleGame) {
+		g.Owner = owner
+	}
+}
+
 // Factory used to create a game
-func Create(secretWord string) (Game, error) {
+func Create(ctx context.Context, secretWord string, options ...Option) (Game, error) {
+	return defaultEngine.Create(ctx, secretWord, options...)
+}
+
+// Creates a game with the words and store of the engine. A random word is
+// picked when secretWord is empty.
+func (e *Engine) Create(ctx context.Context, secretWord string, options ...Option) (_ Game, err error) {
+	ctx, span := tracing.Start(ctx, "game.Create")
+	defer func() { tracing.End(span, err) }()
+
+	// Options are applied to a draft, which becomes the setup of the game
+	draft := &wordleGame{Id: e.newId(), Created: e.now()}
+	for _, opt := range options {
+		opt(draft)
+	}
+	span.SetAttributes(attribute.String("game.id", draft.Id), attribute.String("game.mode", draft.Mode.String()))
+
+	d, err := e.dictionary()
+	if err != nil {
+		return nil, err
+	}
+	if draft.Mode == Daily {
+		if len(secretWord) > 0 {
+			return nil, ErrDailyWord
+		}
+		if secretWord, err = d.DailyWord(ctx, draft.Created); err != nil {
+			return nil, err
+		}
+	}
 	if len(secretWord) < 1 {
-		var err error
-		if secretWord, err = dictionary.GenerateWord(); err != nil {
+		if secretWord, err = e.generateWord(ctx, d); err != nil {
 			return nil, err
 		}
 	}
 
-	sw, err := validateWord(secretWord, secretWord)
+	sw, err := e.validateWord(ctx, secretWord, secretWord)
 	if err != nil {
 		return nil, err
 	}
-	game := &wordleGame{}
-	game.Id = xid.New().String()
-	game.SecretWord = sw
-	game.Attempts = []*WordleAttempt{}
-	game.Status = InPlay
-	game.LastUpdated = time.Now()
+	game := &wordleGame{engine: e}
+	game.record(Event{Type: GameCreated, Time: draft.Created, Word: sw, Setup: draft.setup()})
 
-	s, err := store.WordleStore()
-	if err != nil {
-		return game, err
-	}
-	if err := s.Save(game.Id, game); err != nil {
+	if err := game.save(ctx); err != nil {
 		return game, err
 	}
+	metrics.GamesCreated.WithLabelValues(game.Mode.String()).Inc()
+	logging.Info(ctx, "game created", "id", game.Id, "mode", game.Mode.String(), "puzzle", game.Puzzle)
 
 	return game, nil
 }
 
-func Retrieve(id string) (Game, error) {
-	s, err := store.WordleStore()
+func Retrieve(ctx context.Context, id string) (Game, error) {
+	return defaultEngine.Retrieve(ctx, id)
+}
+
+// Returns the game with the given id from the store of the engine
+func (e *Engine) Retrieve(ctx context.Context, id string) (_ Game, err error) {
+	ctx, span := tracing.Start(ctx, "game.Retrieve", attribute.String("game.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	s, err := e.store()
 	if err != nil {
 		return nil, err
 	}
-	content, err := s.Load(id)
+	content, err := s.Load(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	game, ok := content.(Game)
+	stored, ok := gameOf(content)
 	if !ok {
+		logging.Warn(ctx, "game not found", "id", id)
 		return nil, ErrSerialization
 	}
+	game := stored.checkout()
+	game.engine = e
+
+	// Timed games are ended as soon as they are looked at
+	if game.expire(ctx, e.now()) {
+		if err := game.save(ctx); err != nil {
+			return game, err
+		}
+	}
 
 	return game, nil
 }
 
+// Returns how many games of owner are still in play
+func CountInPlay(ctx context.Context, owner string) (int, error) {
+	return defaultEngine.CountInPlay(ctx, owner)
+}
+
+// Returns how many games of owner are still in play in the store of the
+// engine
+func (e *Engine) CountInPlay(ctx context.Context, owner string) (int, error) {
+	s, err := e.store()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	now := e.now()
+	q := store.Query{Owner: owner, Statuses: []string{InPlay.String()}}
+	for {
+		page, err := s.Query(ctx, q)
+		if err != nil {
+			return 0, err
+		}
+		for _, id := range page.Ids {
+			g, err := e.load(ctx, id)
+			if err != nil || g.Status != InPlay {
+				continue
+			}
+			// Games out of time are lost, even if not yet swept
+			if d := g.deadline(); !d.IsZero() && !now.Before(d) {
+				continue
+			}
+			count++
+		}
+		if len(page.Next) < 1 {
+			return count, nil
+		}
+		q.Cursor = page.Next
+	}
+}
+
+// Returns the number of changes made to the game, which goes up by one with
+// every event
+func (g wordleGame) Version() int {
+	return g.Revision
+}
+
+// Columns and rows kept for the game by the SQL store
+func (g wordleGame) Record() store.Record {
+	r := store.Record{Mode: g.Mode.String(), Word: g.SecretWord, Attempts: make([]store.Attempt, len(g.Attempts))}
+	for i, a := range g.Attempts {
+		hints := make([]string, len(a.TryResult))
+		for j, h := range a.TryResult {
+			hints[j] = h.String()
+		}
+		r.Attempts[i] = store.Attempt{Word: a.TryWord, Valid: a.IsValidWord, Result: strings.Join(hints, ","), Time: a.TimeStamp}
+	}
+	return r
+}
+
 func (g wordleGame) Describe() (string, error) {
 	return g.statusReport(), nil
 }
 
-func (g *wordleGame) Play(tryWord string) (string, error) {
+func (g *wordleGame) Play(ctx context.Context, tryWord string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "game.Play", attribute.String("game.id", g.Id))
+	defer func() {
+		span.SetAttributes(attribute.Int("game.attempts", len(g.Attempts)), attribute.String("game.status", g.Status.String()))
+		tracing.End(span, err)
+	}()
+
+	key := idempotencyKeyOf(ctx)
+	if out, ok, err := g.repeat(ctx, key, tryWord); ok {
+		return out, err
+	}
+
+	if g.expire(ctx, g.now()) {
+		if err := g.save(ctx); err != nil {
+			return g.statusReport(), err
+		}
+	}
 	if g.Status != InPlay {
 		return g.statusReport(), ErrGameOver
 	}
-	if len(g.Attempts) >= config.CONFIG_GAME_MAXATTEMPTS ||
-		g.ValidAttempts >= config.CONFIG_GAME_MAXVALIDATTEMPTS {
-		g.Status = Lost
+	if g.outOfTurns() {
+		g.end(ctx, Event{Type: GameLost, Time: g.now(), Reason: LOST_OUT_OF_TURNS, Key: key})
+		if err := g.save(ctx); err != nil {
+			return g.statusReport(), err
+		}
 		return g.statusReport(), ErrOutOfTurns
 	}
 
-	attempt := g.addAttempt()
-	tw, err := validateWord(tryWord, g.SecretWord)
-	attempt.TryWord = tw
+	tw, err := g.eng().validateWord(ctx, tryWord, g.SecretWord)
 	if err != nil {
-		attempt.IsValidWord = false
+		g.record(Event{Type: GuessRejected, Time: g.now(), Word: tw, Reason: err.Error(), Key: key})
+		logging.Debug(ctx, "guess rejected", "id", g.Id, "attempt", len(g.Attempts), "error", err.Error())
 
-		if len(g.Attempts) >= config.CONFIG_GAME_MAXATTEMPTS ||
-			g.ValidAttempts >= config.CONFIG_GAME_MAXVALIDATTEMPTS {
-			g.Status = Lost
+		if g.outOfTurns() {
+			g.end(ctx, Event{Type: GameLost, Time: g.now(), Reason: LOST_OUT_OF_TURNS, Key: key})
 		}
-		if err == ErrWordLength {
+		if err := g.save(ctx); err != nil {
 			return g.statusReport(), err
 		}
 		return g.statusReport(), err
 	}
-	attempt.IsValidWord = true
-	g.ValidAttempts++
 
 	// Score the tryWord letters against the secret
-	score := attempt.TryResult
+	score := make([]LetterHint, config.CONFIG_GAME_WORDLENGTH)
 	if err := g.scoreWord(tw, &score); err != nil {
 		return g.statusReport(), err
 	}
+	g.record(Event{Type: GuessSubmitted, Time: g.now(), Word: tw, Result: score, Key: key})
+
+	logging.Debug(ctx, "guess played", "id", g.Id, "attempt", len(g.Attempts))
 
 	// Check for end of game conditions
-	if attempt.isWinner() {
-		g.Status = Won
-	} else if len(g.Attempts) >= config.CONFIG_GAME_MAXATTEMPTS ||
-		g.ValidAttempts >= config.CONFIG_GAME_MAXVALIDATTEMPTS {
-		g.Status = Lost
+	if g.Attempts[len(g.Attempts)-1].isWinner() {
+		g.end(ctx, Event{Type: GameWon, Time: g.now(), Key: key})
+	} else if g.outOfTurns() {
+		g.end(ctx, Event{Type: GameLost, Time: g.now(), Reason: LOST_OUT_OF_TURNS, Key: key})
 	}
 
-	g.LastUpdated = time.Now()
-
 	// Save to game store
-	gs, err := store.WordleStore()
-	if err != nil {
-		return g.statusReport(), err
-	}
-	err = gs.Save(g.Id, g)
-	if err != nil {
+	if err := g.save(ctx); err != nil {
 		return g.statusReport(), err
 	}
 
@@ -157,23 +310,30 @@ func (g *wordleGame) Play(tryWord string) (string, error) {
 	return g.statusReport(), nil
 }
 
-func (g *wordleGame) Resign() (string, error) {
-	g.Status = Resigned
-	g.LastUpdated = time.Now()
+func (g *wordleGame) Resign(ctx context.Context) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "game.Resign", attribute.String("game.id", g.Id))
+	defer func() { tracing.End(span, err) }()
+
+	g.end(ctx, Event{Type: GameResigned, Time: g.now()})
 
 	// Save to game store
-	gs, err := store.WordleStore()
-	if err != nil {
-		return g.statusReport(), err
-	}
-	err = gs.Save(g.Id, g)
-	if err != nil {
+	if err := g.save(ctx); err != nil {
 		return g.statusReport(), err
 	}
 
 	return g.statusReport(), nil
 }
 
+// Returns the status with the given name (case insensitive)
+func ParseStatus(s string) (GameStatusType, error) {
+	for name, t := range mapStringToGameStatus {
+		if strings.EqualFold(name, s) {
+			return t, nil
+		}
+	}
+	return InPlay, ErrInvalidStatus
+}
+
 func (t GameStatusType) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBufferString(`"`)
 	buf.WriteString(mapGameStatusToString[t])
@@ -217,27 +377,120 @@ func (t GameStatusType) String() string {
 type wordleGame struct {
 	Id            string           `json:"id"`
 	Status        GameStatusType   `json:"gameStatus"`
+	Mode          GameMode         `json:"mode"`
 	SecretWord    string           `json:"secretWord"`
 	Attempts      []*WordleAttempt `json:"attempts"`
 	ValidAttempts int              `json:"validAttempts"`
+	Created       time.Time        `json:"created"`
 	LastUpdated   time.Time        `json:"lastUpdated"`
+	GuessTimeout  int              `json:"guessTimeout,omitempty"` // seconds, Timed mode
+	TimeLimit     int              `json:"timeLimit,omitempty"`    // seconds, SpeedRun mode
+	TimedOut      bool             `json:"timedOut"`
+	Puzzle        bool             `json:"puzzle"`    // created from a puzzle token
+	PeriodEnd     time.Time        `json:"periodEnd"` // when the daily puzzle closes
+	HintsDisabled bool             `json:"hintsDisabled"`
+	HintsUsed     int              `json:"hintsUsed"`
+	LastHint      time.Time        `json:"lastHint"`
+	Owner         string           `json:"owner,omitempty"`
+	Events        []Event          `json:"events"`
+	Revision      int              `json:"version"`
+	stored        int              // version in the store when loaded or last saved
+	engine        *Engine          // created or retrieved with, the default engine when nil
+}
+
+// Records the end of the game. Only the first end of a game is counted.
+func (g *wordleGame) end(ctx context.Context, e Event) {
+	wasInPlay := g.Status == InPlay
+	g.record(e)
+	if wasInPlay && g.Status != InPlay {
+		metrics.GamesFinished.WithLabelValues(g.Mode.String(), g.Status.String()).Inc()
+		logging.Info(ctx, "game finished", "id", g.Id, "mode", g.Mode.String(), "status", g.Status.String(), "attempts", len(g.Attempts))
+	}
+}
+
+// Reports whether no more guesses may be made
+func (g wordleGame) outOfTurns() bool {
+	return len(g.Attempts) >= config.CONFIG_GAME_MAXATTEMPTS ||
+		g.ValidAttempts >= config.CONFIG_GAME_MAXVALIDATTEMPTS
+}
+
+// Saves a copy of the game, as long as nobody else has saved it since it was
+// loaded. Otherwise returns ErrConflict.
+func (g *wordleGame) save(ctx context.Context) error {
+	gs, err := g.eng().store()
+	if err != nil {
+		return err
+	}
+
+	if err := gs.CompareAndSwap(ctx, g.Id, g.stored, g.clone()); err != nil {
+		if err == store.ErrVersionConflict {
+			logging.Warn(ctx, "game changed by another request", "id", g.Id, "version", g.stored)
+			return ErrConflict
+		}
+		return err
+	}
+	g.stored = g.Revision
+
+	return nil
 }
 
-func (g *wordleGame) addAttempt() *WordleAttempt {
-	wa := new(WordleAttempt)
+// Returns a copy of the game that shares nothing with it, rebuilt from its
+// events
+func (g wordleGame) clone() *wordleGame {
+	c, err := rebuild(g.Events)
+	if err != nil {
+		return &g
+	}
+	c.stored = g.stored
+	c.engine = g.engine
+	return c
+}
 
-	wa.TimeStamp = time.Now()
-	wa.TryWord = ""
-	wa.IsValidWord = false
-	wa.TryResult = make([]LetterHint, config.CONFIG_GAME_WORDLENGTH)
+// Returns the game in content loaded from the store. Stores that keep games
+// outside the process hand back their JSON, which is rebuilt from its events.
+func gameOf(content interface{}) (*wordleGame, bool) {
+	switch c := content.(type) {
+	case *wordleGame:
+		return c, true
+	case json.RawMessage:
+		return gameFromJSON(c)
+	case []byte:
+		return gameFromJSON(c)
+	}
+	return nil, false
+}
 
-	g.Attempts = append(g.Attempts, wa)
-	g.LastUpdated = time.Now()
+func gameFromJSON(b []byte) (*wordleGame, bool) {
+	var stored struct {
+		Events []Event `json:"events"`
+	}
+	if err := json.Unmarshal(b, &stored); err != nil {
+		return nil, false
+	}
+	g, err := rebuild(stored.Events)
+	if err != nil {
+		return nil, false
+	}
+	return g, true
+}
 
-	return wa
+// Returns a copy of a game from the store that can be changed and saved
+func (g wordleGame) checkout() *wordleGame {
+	c := g.clone()
+	c.stored = c.Revision
+	return c
 }
 
 func (g wordleGame) statusReport() string {
+	return g.report(false)
+}
+
+// Report for operators, which always includes the secret word and owner
+func (g wordleGame) adminReport() string {
+	return g.report(true)
+}
+
+func (g wordleGame) report(admin bool) string {
 	b, err := json.Marshal(g)
 	if err != nil {
 		return "{}"
@@ -249,9 +502,21 @@ func (g wordleGame) statusReport() string {
 		return "{}"
 	}
 
+	now := g.now()
 	s["attemptsUsed"] = len(g.Attempts)
-	if g.Status == InPlay {
-		delete(s, "secretWord")
+	s["elapsedSeconds"] = g.elapsed(now).Seconds()
+	if d := g.deadline(); !d.IsZero() {
+		s["deadline"] = d
+		if g.Status == InPlay {
+			s["timeRemainingSeconds"] = d.Sub(now).Seconds()
+		}
+	}
+	delete(s, "events")
+	if !admin {
+		if g.secretHidden(now) {
+			delete(s, "secretWord")
+		}
+		delete(s, "owner")
 	}
 	if g.Status == Won {
 		s["winningAttempt"] = len(g.Attempts)
@@ -269,8 +534,12 @@ func (g wordleGame) scoreWord(tryWord string, result *[]LetterHint) error {
 	if result == nil {
 		return ErrNilResult
 	}
-	score := *result
+	scoreLetters(g.SecretWord, tryWord, *result)
 
+	return nil
+}
+
+func scoreLetters(secretWord string, tryWord string, score []LetterHint) {
 	// Rules for scoring:
 	// 1. If the correct letter is in the correct location, mark it green
 	// 2. If the letter is correct but in an incorrect location, mark it
@@ -280,20 +549,20 @@ func (g wordleGame) scoreWord(tryWord string, result *[]LetterHint) error {
 	// 4. Remaining unmarked letters must be marked grey.
 	//
 	for i := 0; i < config.CONFIG_GAME_WORDLENGTH; i++ {
-		if g.SecretWord[i] == byte(tryWord[i]) {
+		if secretWord[i] == byte(tryWord[i]) {
 			score[i] = Green // exact match
 			continue
-		} else if count := strings.Count(g.SecretWord, string(tryWord[i])); count > 0 {
+		} else if count := strings.Count(secretWord, string(tryWord[i])); count > 0 {
 			// Letter is definitely in the secret word. Check if there are other instances of the
 			// same letter that are or will be marked green or yellow elsewhere in the word.
-			if countLeft := strings.Count(g.SecretWord[:i+1], string(tryWord[i])); countLeft > 0 {
+			if countLeft := strings.Count(secretWord[:i+1], string(tryWord[i])); countLeft > 0 {
 				// If letter occured fewer times in tryWord than secret, mark is yellow
 				if strings.Count(tryWord[:i+1], string(tryWord[i])) <= countLeft {
 					score[i] = Yellow
 					continue
 				}
 			}
-			if countRight := strings.Count(g.SecretWord[i:], string(tryWord[i])); countRight > 0 {
+			if countRight := strings.Count(secretWord[i:], string(tryWord[i])); countRight > 0 {
 				if strings.Count(tryWord[i:], string(tryWord[i])) <= countRight {
 					score[i] = Yellow
 					continue
@@ -302,6 +571,4 @@ func (g wordleGame) scoreWord(tryWord string, result *[]LetterHint) error {
 		}
 		score[i] = Grey
 	}
-
-	return nil
 }
@@ -1,16 +1,19 @@
 package game
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCreate(t *testing.T) {
+	ctx := context.Background()
 	assert := assert.New(t)
 
 	tests := []struct {
@@ -24,7 +27,7 @@ func TestCreate(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		g, err := Create(test.secretWord)
+		g, err := Create(ctx, test.secretWord)
 		assert.IsType(test.err, err, fmt.Sprintf("\"%s\": %s", test.secretWord, err))
 		if err != nil {
 			assert.EqualError(err, test.err.Error())
@@ -44,6 +47,7 @@ func TestCreate(t *testing.T) {
 }
 
 func TestDescribe(t *testing.T) {
+	ctx := context.Background()
 	assert := assert.New(t)
 	require := require.New(t)
 
@@ -56,7 +60,7 @@ func TestDescribe(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		game, err := Create(test.createWord)
+		game, err := Create(ctx, test.createWord)
 		require.NoError(err, "Create() returned error when creating Game")
 		require.NotNil(game, "unable to create a Game object")
 
@@ -74,6 +78,7 @@ func TestDescribe(t *testing.T) {
 }
 
 func TestPlay(t *testing.T) {
+	ctx := context.Background()
 	assert := assert.New(t)
 	require := require.New(t)
 
@@ -93,11 +98,11 @@ func TestPlay(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		game, err := Create(test.createWord)
+		game, err := Create(ctx, test.createWord)
 		require.NoError(err, "Create() returned error when creating Game")
 		require.NotNil(game, "unable to create a Game object")
 
-		s, err := game.Play(test.tryWord)
+		s, err := game.Play(ctx, test.tryWord)
 		assert.IsType(test.err, err, fmt.Sprintf("\"%s\" unexpected error: %s", test.tryWord, err))
 		if err != nil {
 			assert.EqualError(err, test.err.Error(), "returned unexpected error")
@@ -132,6 +137,7 @@ func TestPlay(t *testing.T) {
 }
 
 func TestResign(t *testing.T) {
+	ctx := context.Background()
 	assert := assert.New(t)
 	require := require.New(t)
 
@@ -144,11 +150,11 @@ func TestResign(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		game, err := Create(test.createWord)
+		game, err := Create(ctx, test.createWord)
 		require.NoError(err, "Create() returned error when creating Game")
 		require.NotNil(game, "unable to create a Game object")
 
-		s, err := game.Resign()
+		s, err := game.Resign(ctx)
 		assert.ErrorIs(test.err, err, "returned unexpected error")
 		if err != nil {
 			assert.EqualError(err, test.err.Error())
@@ -168,6 +174,7 @@ func TestResign(t *testing.T) {
 }
 
 func TestAddAttempt(t *testing.T) {
+	ctx := context.Background()
 	assert := assert.New(t)
 	require := require.New(t)
 
@@ -178,14 +185,15 @@ func TestAddAttempt(t *testing.T) {
 		{createWord: "proxy", result: &WordleAttempt{TryWord: "", IsValidWord: false, TryResult: []LetterHint{0, 0, 0, 0, 0}}},
 	}
 	for _, test := range tests {
-		game, err := Create(test.createWord)
+		game, err := Create(ctx, test.createWord)
 		require.NoError(err, "Create() returned error when creating Game")
 		require.NotNil(game, "unable to create a Game object")
 
 		v, ok := game.(*wordleGame)
 		require.True(ok)
-		res := v.addAttempt()
+		v.record(Event{Type: GuessRejected, Time: time.Now()})
 		assert.NotZero(len(v.Attempts))
+		res := v.Attempts[len(v.Attempts)-1]
 		assert.Equal(res.TryWord, test.result.TryWord)
 		assert.Equal(res.IsValidWord, test.result.IsValidWord)
 		assert.Equal(res.TryResult, test.result.TryResult)
@@ -193,6 +201,7 @@ func TestAddAttempt(t *testing.T) {
 }
 
 func TestRetrieve(t *testing.T) {
+	ctx := context.Background()
 	assert := assert.New(t)
 	require := require.New(t)
 
@@ -206,7 +215,7 @@ func TestRetrieve(t *testing.T) {
 
 	// Create test games
 	for i, test := range tests {
-		game, err := Create(test.createWord)
+		game, err := Create(ctx, test.createWord)
 		require.NoError(err, "Create() returned error when creating Game")
 		require.NotNil(game, "unable to create a Game object")
 
@@ -217,7 +226,7 @@ func TestRetrieve(t *testing.T) {
 
 	// Test retrieving games
 	for _, test := range tests {
-		game, err := Retrieve(test.id)
+		game, err := Retrieve(ctx, test.id)
 		assert.IsType(test.err, err, "returned unexpected error")
 		if err != nil {
 			assert.EqualError(err, test.err.Error())
@@ -233,7 +242,36 @@ func TestRetrieve(t *testing.T) {
 	}
 }
 
+func TestCountInPlay(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	owner := "owner-" + t.Name()
+	count, err := CountInPlay(ctx, owner)
+	require.NoError(err)
+	assert.Zero(count)
+
+	games := []Game{}
+	for _, opts := range [][]Option{{WithOwner(owner)}, {WithOwner(owner), WithMode(SpeedRun)}, {WithOwner(owner)}, {}} {
+		game, err := Create(ctx, "happy", opts...)
+		require.NoError(err, "Create() returned error when creating Game")
+		games = append(games, game)
+	}
+	count, err = CountInPlay(ctx, owner)
+	require.NoError(err)
+	assert.Equal(3, count)
+
+	// Finished games and games out of time are not in play
+	games[0].Resign(ctx)
+	backdate(t, games[1], time.Hour)
+	count, err = CountInPlay(ctx, owner)
+	require.NoError(err)
+	assert.Equal(1, count)
+}
+
 func TestScoreWord(t *testing.T) {
+	ctx := context.Background()
 	assert := assert.New(t)
 	require := require.New(t)
 
@@ -255,7 +293,7 @@ func TestScoreWord(t *testing.T) {
 
 	for _, test := range tests {
 		// Create test game
-		game, err := Create(test.createWord)
+		game, err := Create(ctx, test.createWord)
 		require.NoError(err, "Create() returned error when creating Game")
 		require.NotNil(game, "unable to create a Game object")
 
@@ -273,3 +311,48 @@ func TestScoreWord(t *testing.T) {
 	}
 
 }
+
+func TestVersion(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	game, err := Create(ctx, "happy")
+	require.NoError(err, "Create() returned error when creating Game")
+	assert.Equal(1, game.Version())
+	id := game.(*wordleGame).Id
+
+	// Two requests load the same game
+	first, err := Retrieve(ctx, id)
+	require.NoError(err)
+	second, err := Retrieve(ctx, id)
+	require.NoError(err)
+	assert.NotSame(first, second)
+
+	_, err = first.Play(ctx, "puppy")
+	require.NoError(err)
+	assert.Equal(2, first.Version())
+
+	// The second is out of date and cannot be saved
+	_, err = second.Play(ctx, "bless")
+	assert.ErrorIs(err, ErrConflict)
+	_, err = second.Resign(ctx)
+	assert.ErrorIs(err, ErrConflict)
+
+	// Only the first guess was kept
+	latest, err := Retrieve(ctx, id)
+	require.NoError(err)
+	assert.Equal(2, latest.Version())
+	v := latest.(*wordleGame)
+	require.Len(v.Attempts, 1)
+	assert.Equal("PUPPY", v.Attempts[0].TryWord)
+	assert.Equal(InPlay, v.Status)
+
+	// Changing a loaded game does not change the stored one
+	v.Status = Won
+	assert.Equal(InPlay, stored(t, id).Status)
+
+	s, err := latest.Describe()
+	require.NoError(err)
+	assert.Contains(s, `"version":2`)
+}
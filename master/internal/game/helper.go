@@ -1,19 +1,21 @@
 package game
 
 import (
+	"context"
 	"strings"
 
 	"aluance.io/wordleserver/internal/config"
-	"aluance.io/wordleserver/internal/dictionary"
+	"aluance.io/wordleserver/internal/metrics"
 )
 
-func validateWord(s string, options ...interface{}) (string, error) {
+func (e *Engine) validateWord(ctx context.Context, s string, options ...interface{}) (string, error) {
 	optSecretWord := ""
 	if len(options) > 0 {
 		optSecretWord = strings.ToUpper(options[0].(string))
 	}
 
 	if len(s) != config.CONFIG_GAME_WORDLENGTH {
+		metrics.InvalidWords.WithLabelValues("length").Inc()
 		return s, ErrWordLength
 	}
 
@@ -24,7 +26,12 @@ func validateWord(s string, options ...interface{}) (string, error) {
 		return strings.ToUpper(s), nil // automatically valid
 	}
 
-	if !dictionary.IsWordValid(s) {
+	d, err := e.dictionary()
+	if err != nil {
+		return s, err
+	}
+	if !d.IsWordValid(ctx, s) {
+		metrics.InvalidWords.WithLabelValues("dictionary").Inc()
 		return s, ErrInvalidWord
 	}
 
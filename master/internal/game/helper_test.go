@@ -1,13 +1,43 @@
 package game
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"aluance.io/wordleserver/internal/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// Stores the game as if everything in it had happened d earlier, and returns
+// the stored copy
+func backdate(t *testing.T, g Game, d time.Duration) *wordleGame {
+	v := g.(*wordleGame)
+	events := make([]Event, len(v.Events))
+	for i, e := range v.Events {
+		e.Time = e.Time.Add(-d)
+		events[i] = e
+	}
+	past, err := rebuild(events)
+	require.NoError(t, err)
+
+	s, err := store.WordleStore()
+	require.NoError(t, err)
+	require.NoError(t, s.Save(context.Background(), past.Id, past))
+	return past.checkout()
+}
+
+// Returns the game as it is in the store
+func stored(t *testing.T, id string) *wordleGame {
+	g, err := defaultEngine.load(context.Background(), id)
+	require.NoError(t, err)
+	return g
+}
+
 func TestValidateWords(t *testing.T) {
+	ctx := context.Background()
 	assert := assert.New(t)
 
 	tests := []struct {
@@ -29,9 +59,9 @@ func TestValidateWords(t *testing.T) {
 		var res string
 		var err error
 		if len(test.secret) > 0 {
-			res, err = validateWord(test.s, test.secret)
+			res, err = defaultEngine.validateWord(ctx, test.s, test.secret)
 		} else {
-			res, err = validateWord(test.s)
+			res, err = defaultEngine.validateWord(ctx, test.s)
 		}
 		if test.err != nil {
 			assert.IsType(test.err, err)
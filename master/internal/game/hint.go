@@ -0,0 +1,114 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/solver"
+	"aluance.io/wordleserver/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Returns suggestions for the next guess, ranked with the named strategy
+// ("entropy" or "minmax"). Each hint counts against the allowance of the game
+// and is recorded so that assisted games can be told apart.
+func (g *wordleGame) Hint(ctx context.Context, strategy string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "game.Hint", attribute.String("game.id", g.Id), attribute.String("hint.strategy", strategy))
+	defer func() { tracing.End(span, err) }()
+
+	if g.Status != InPlay {
+		return g.statusReport(), ErrGameOver
+	}
+	if g.HintsDisabled {
+		return g.statusReport(), ErrHintsDisabled
+	}
+	if g.HintsUsed >= config.CONFIG_HINT_MAXPERGAME {
+		return g.statusReport(), ErrHintLimit
+	}
+	if g.now().Sub(g.LastHint) < config.CONFIG_HINT_COOLDOWN {
+		return g.statusReport(), ErrHintCooldown
+	}
+
+	st, err := solver.ParseStrategy(strategy)
+	if err != nil {
+		return g.statusReport(), err
+	}
+	s, err := g.eng().wordleSolver()
+	if err != nil {
+		return g.statusReport(), err
+	}
+	candidates, suggestions := s.Suggest(g.guesses(), st, config.CONFIG_HINT_SUGGESTIONS)
+
+	g.record(Event{Type: HintGiven, Time: g.now()})
+	logging.Info(ctx, "hint given", "id", g.Id, "hintsUsed", g.HintsUsed)
+
+	// Save to game store
+	if err := g.save(ctx); err != nil {
+		return g.statusReport(), err
+	}
+
+	return g.hintReport(st, len(candidates), suggestions), nil
+}
+
+/////////////
+
+var mapLetterHintToMark = map[LetterHint]solver.Mark{
+	Green:  solver.Correct,
+	Yellow: solver.Present,
+	Grey:   solver.Absent,
+}
+
+// Called for every pair of words when ranking, so avoids allocating
+func scorePattern(secretWord, tryWord string) solver.Pattern {
+	var score [config.CONFIG_GAME_WORDLENGTH]LetterHint
+	scoreLetters(secretWord, tryWord, score[:])
+	return patternOf(score[:])
+}
+
+func patternOf(hints []LetterHint) solver.Pattern {
+	var marks [config.CONFIG_GAME_WORDLENGTH]solver.Mark
+	for i, h := range hints {
+		marks[i] = mapLetterHintToMark[h]
+	}
+	return solver.PatternOf(marks[:len(hints)])
+}
+
+// Returns the valid attempts of the game in a form the solver understands
+func (g wordleGame) guesses() []solver.Guess {
+	guesses := []solver.Guess{}
+	for _, a := range g.Attempts {
+		if !a.IsValidWord {
+			continue
+		}
+		guesses = append(guesses, solver.Guess{Word: a.TryWord, Pattern: patternOf(a.TryResult)})
+	}
+
+	return guesses
+}
+
+func (g wordleGame) hintReport(st solver.Strategy, candidates int, suggestions []solver.Suggestion) string {
+	report := struct {
+		Id                  string              `json:"id"`
+		Strategy            solver.Strategy     `json:"strategy"`
+		CandidatesRemaining int                 `json:"candidatesRemaining"`
+		Suggestions         []solver.Suggestion `json:"suggestions"`
+		HintsUsed           int                 `json:"hintsUsed"`
+		HintsLeft           int                 `json:"hintsLeft"`
+	}{
+		Id:                  g.Id,
+		Strategy:            st,
+		CandidatesRemaining: candidates,
+		Suggestions:         suggestions,
+		HintsUsed:           g.HintsUsed,
+		HintsLeft:           config.CONFIG_HINT_MAXPERGAME - g.HintsUsed,
+	}
+
+	b, err := json.Marshal(report)
+	if err != nil {
+		return "{}"
+	}
+
+	return string(b)
+}
@@ -0,0 +1,119 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"aluance.io/wordleserver/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHint(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	game, err := Create(ctx, "happy")
+	require.NoError(err, "Create() returned error when creating Game")
+	v, ok := game.(*wordleGame)
+	require.True(ok)
+
+	_, err = game.Play(ctx, "puppy")
+	require.NoError(err)
+
+	// Unknown strategies are rejected without using up a hint
+	_, err = game.Hint(ctx, "random")
+	assert.Error(err)
+	assert.Zero(v.HintsUsed)
+
+	for i := 1; i <= config.CONFIG_HINT_MAXPERGAME; i++ {
+		v.LastHint = time.Time{} // skip the cooldown
+
+		s, err := game.Hint(ctx, "minmax")
+		require.NoError(err)
+
+		out := map[string]interface{}{}
+		require.NoError(json.Unmarshal([]byte(s), &out))
+		assert.Equal(v.Id, out["id"])
+		assert.Equal("minmax", out["strategy"])
+		assert.NotZero(out["candidatesRemaining"])
+		assert.EqualValues(i, out["hintsUsed"])
+		assert.EqualValues(config.CONFIG_HINT_MAXPERGAME-i, out["hintsLeft"])
+
+		suggestions := out["suggestions"].([]interface{})
+		assert.NotEmpty(suggestions)
+		assert.LessOrEqual(len(suggestions), config.CONFIG_HINT_SUGGESTIONS)
+	}
+
+	v.LastHint = time.Time{}
+	_, err = game.Hint(ctx, "")
+	assert.ErrorIs(err, ErrHintLimit)
+
+	// Hints used are part of the game description
+	s, err := game.Describe()
+	require.NoError(err)
+	out := map[string]interface{}{}
+	require.NoError(json.Unmarshal([]byte(s), &out))
+	assert.EqualValues(config.CONFIG_HINT_MAXPERGAME, out["hintsUsed"])
+}
+
+func TestHintRefused(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tests := []struct {
+		options []Option
+		setup   func(g *wordleGame)
+		err     error
+	}{
+		{options: []Option{WithoutHints()}, err: ErrHintsDisabled},
+		{setup: func(g *wordleGame) { g.LastHint = time.Now() }, err: ErrHintCooldown},
+		{setup: func(g *wordleGame) { g.HintsUsed = config.CONFIG_HINT_MAXPERGAME }, err: ErrHintLimit},
+		{setup: func(g *wordleGame) { g.Status = Resigned }, err: ErrGameOver},
+	}
+
+	for _, test := range tests {
+		game, err := Create(ctx, "happy", test.options...)
+		require.NoError(err, "Create() returned error when creating Game")
+		v, ok := game.(*wordleGame)
+		require.True(ok)
+		if test.setup != nil {
+			test.setup(v)
+		}
+		hintsUsed := v.HintsUsed
+
+		_, err = game.Hint(ctx, "")
+		assert.ErrorIs(err, test.err)
+		assert.Equal(hintsUsed, v.HintsUsed, "refused hints must not be counted")
+	}
+}
+
+func TestGuesses(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	game, err := Create(ctx, "happy")
+	require.NoError(err, "Create() returned error when creating Game")
+	v, ok := game.(*wordleGame)
+	require.True(ok)
+
+	game.Play(ctx, "zzzzz") // invalid words are not guesses
+	game.Play(ctx, "puppy")
+	game.Play(ctx, "bless")
+
+	guesses := v.guesses()
+	require.Len(guesses, 2)
+	assert.Equal("PUPPY", guesses[0].Word)
+	assert.Equal(scorePattern("HAPPY", "PUPPY"), guesses[0].Pattern)
+	assert.Equal(scorePattern("HAPPY", "BLESS"), guesses[1].Pattern)
+
+	// The secret word must survive the feedback it produced
+	s, err := defaultEngine.wordleSolver()
+	require.NoError(err)
+	assert.Contains(s.Candidates(guesses), "HAPPY")
+}
@@ -0,0 +1,91 @@
+package game
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/logging"
+)
+
+type contextKey int
+
+const idempotencyKey contextKey = iota
+
+// Returns a context that makes Play remember key with the guess. A play
+// repeated with the same key gets the original response rather than using
+// up another attempt.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey, key)
+}
+
+// Reports whether a recent play was made with key
+func (g wordleGame) Remembers(key string) bool {
+	_, ok := g.lastWithKey(key, g.now())
+	return ok
+}
+
+/////////////
+
+// Errors a rejected guess can be repeated with, by their reason
+var mapReasonToError = map[string]error{
+	ErrInvalidWord.Error(): ErrInvalidWord,
+	ErrWordLength.Error():  ErrWordLength,
+}
+
+func idempotencyKeyOf(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKey).(string)
+	return key
+}
+
+// Returns the index of the last event recorded with key, unless it is too
+// old to be remembered
+func (g wordleGame) lastWithKey(key string, now time.Time) (int, bool) {
+	if len(key) < 1 {
+		return -1, false
+	}
+	for i := len(g.Events) - 1; i >= 0; i-- {
+		if g.Events[i].Key == key {
+			return i, now.Sub(g.Events[i].Time) <= config.CONFIG_GAME_IDEMPOTENCYTTL
+		}
+	}
+	return -1, false
+}
+
+// Returns what the play made with key returned, as the game was right after
+// it. The bool is false when there was no such play.
+func (g wordleGame) repeat(ctx context.Context, key string, tryWord string) (string, bool, error) {
+	last, ok := g.lastWithKey(key, g.now())
+	if !ok {
+		return "", false, nil
+	}
+
+	var err error
+	for _, e := range g.Events[:last+1] {
+		if e.Key != key {
+			continue
+		}
+		switch e.Type {
+		case GuessSubmitted, GuessRejected:
+			if !strings.EqualFold(e.Word, tryWord) {
+				return g.statusReport(), true, ErrKeyReused
+			}
+			err = mapReasonToError[e.Reason]
+		case GameLost:
+			if e.Reason == LOST_OUT_OF_TURNS && err == nil {
+				err = ErrOutOfTurns
+			}
+		}
+	}
+
+	past, rerr := rebuild(g.Events[:last+1])
+	if rerr != nil {
+		return g.statusReport(), true, rerr
+	}
+	// The time left is told by the clock of the game
+	past.engine = g.engine
+	logging.Debug(ctx, "play repeated", "id", g.Id, "attempt", len(past.Attempts))
+
+	return past.statusReport(), true, err
+}
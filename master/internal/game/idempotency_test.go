@@ -0,0 +1,120 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"aluance.io/wordleserver/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotentPlay(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	game, err := Create(ctx, "happy")
+	require.NoError(err, "Create() returned error when creating Game")
+	id := game.(*wordleGame).Id
+
+	tests := []struct {
+		key      string
+		tryWord  string
+		attempts int // attempts in the response
+		stored   int // attempts stored once played
+		status   string
+		err      error
+	}{
+		{key: "k1", tryWord: "puppy", attempts: 1, stored: 1, status: "InPlay"},
+		{key: "k1", tryWord: "puppy", attempts: 1, stored: 1, status: "InPlay"}, // retried
+		{key: "k2", tryWord: "zzzzz", attempts: 2, stored: 2, status: "InPlay", err: ErrInvalidWord},
+		{key: "k1", tryWord: "PUPPY", attempts: 1, stored: 2, status: "InPlay"}, // retried again, later
+		{key: "k2", tryWord: "zzzzz", attempts: 2, stored: 2, status: "InPlay", err: ErrInvalidWord},
+		{key: "k1", tryWord: "bless", attempts: 2, stored: 2, status: "InPlay", err: ErrKeyReused},
+		{key: "", tryWord: "bless", attempts: 3, stored: 3, status: "InPlay"},
+		{key: "k3", tryWord: "happy", attempts: 4, stored: 4, status: "Won"},
+		{key: "k3", tryWord: "happy", attempts: 4, stored: 4, status: "Won"},
+		{key: "k4", tryWord: "happy", attempts: 4, stored: 4, status: "Won", err: ErrGameOver},
+	}
+
+	for i, test := range tests {
+		g, err := Retrieve(ctx, id)
+		require.NoError(err)
+		s, err := g.Play(WithIdempotencyKey(ctx, test.key), test.tryWord)
+		assert.Equal(test.err, err, i)
+
+		out := map[string]interface{}{}
+		require.NoError(json.Unmarshal([]byte(s), &out))
+		assert.EqualValues(test.attempts, out["attemptsUsed"], i)
+		assert.Equal(test.status, out["gameStatus"], i)
+		assert.Len(stored(t, id).Attempts, test.stored, i)
+	}
+
+	g := stored(t, id)
+	assert.True(g.Remembers("k1"))
+	assert.False(g.Remembers("k4"))
+	assert.False(g.Remembers(""))
+
+	// Keys are forgotten after a while
+	_, ok := g.lastWithKey("k1", time.Now().Add(config.CONFIG_GAME_IDEMPOTENCYTTL+time.Minute))
+	assert.False(ok)
+}
+
+func TestIdempotentRace(t *testing.T) {
+	ctx := WithIdempotencyKey(context.Background(), "race")
+	assert := assert.New(t)
+	require := require.New(t)
+
+	game, err := Create(ctx, "happy")
+	require.NoError(err, "Create() returned error when creating Game")
+	id := game.(*wordleGame).Id
+
+	// The original request and its retry load the game at the same time
+	first, err := Retrieve(ctx, id)
+	require.NoError(err)
+	retry, err := Retrieve(ctx, id)
+	require.NoError(err)
+
+	_, err = first.Play(ctx, "puppy")
+	require.NoError(err)
+	_, err = retry.Play(ctx, "puppy")
+	assert.ErrorIs(err, ErrConflict)
+
+	// Once reloaded, the retry gets the response of the original
+	retry, err = Retrieve(ctx, id)
+	require.NoError(err)
+	require.True(retry.Remembers("race"))
+	s, err := retry.Play(ctx, "puppy")
+	require.NoError(err)
+	assert.Contains(s, `"attemptsUsed":1`)
+	assert.Len(stored(t, id).Attempts, 1)
+}
+
+func TestIdempotentClock(t *testing.T) {
+	ctx := WithIdempotencyKey(context.Background(), "k1")
+	assert := assert.New(t)
+	require := require.New(t)
+
+	clock := &testClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	e := newTestEngine(t, clock, 1)
+	g, err := e.Create(ctx, "happy", WithMode(Timed))
+	require.NoError(err)
+	id := g.(*wordleGame).Id
+	_, err = g.Play(ctx, "puppy")
+	require.NoError(err)
+
+	// A retried play tells the time by the clock of the engine
+	clock.now = clock.now.Add(10 * time.Second)
+	retry, err := e.Retrieve(ctx, id)
+	require.NoError(err)
+	s, err := retry.Play(ctx, "puppy")
+	require.NoError(err)
+
+	out := map[string]interface{}{}
+	require.NoError(json.Unmarshal([]byte(s), &out))
+	assert.InDelta(10, out["elapsedSeconds"], 0.001)
+	assert.InDelta((config.CONFIG_GAME_GUESSTIMEOUT - 10*time.Second).Seconds(), out["timeRemainingSeconds"], 0.001)
+}
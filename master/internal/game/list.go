@@ -0,0 +1,63 @@
+package game
+
+import (
+	"context"
+	"time"
+
+	"aluance.io/wordleserver/internal/store"
+	"aluance.io/wordleserver/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Selects a page of games for Games. Empty or zero fields match any game.
+type Query struct {
+	Owner    string
+	Statuses []GameStatusType
+	Since    time.Time // last updated at or after
+	Cursor   string    // next cursor of the previous page
+	Limit    int
+}
+
+// Returns a page of games, newest first, and the cursor of the next page,
+// which is empty on the last one. Owners are left out of the summaries.
+func Games(ctx context.Context, q Query) ([]Summary, string, error) {
+	return defaultEngine.Games(ctx, q)
+}
+
+// Returns a page of the games of the engine, newest first
+func (e *Engine) Games(ctx context.Context, q Query) (_ []Summary, next string, err error) {
+	ctx, span := tracing.Start(ctx, "game.Games")
+	defer func() { tracing.End(span, err) }()
+
+	s, err := e.store()
+	if err != nil {
+		return nil, "", err
+	}
+	sq := store.Query{Owner: q.Owner, UpdatedSince: q.Since, Cursor: q.Cursor, Limit: q.Limit}
+	for _, status := range q.Statuses {
+		sq.Statuses = append(sq.Statuses, status.String())
+	}
+	page, err := s.Query(ctx, sq)
+	if err != nil {
+		return nil, "", err
+	}
+
+	list := make([]Summary, 0, len(page.Ids))
+	for _, id := range page.Ids {
+		g, err := e.load(ctx, id)
+		if err != nil {
+			continue // deleted since the query
+		}
+		summary := g.summary()
+		summary.Owner = ""
+		list = append(list, summary)
+	}
+	span.SetAttributes(attribute.Int("game.count", len(list)))
+
+	return list, page.Next, nil
+}
+
+// Fields of the game that can be queried in the store
+func (g wordleGame) Index() store.Entry {
+	return store.Entry{Owner: g.Owner, Status: g.Status.String(), Created: g.Created, Updated: g.LastUpdated}
+}
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGames(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	owner := "owner-" + t.Name()
+	ids := []string{}
+	for i := 0; i < 5; i++ {
+		g, err := Create(ctx, "happy", WithOwner(owner))
+		require.NoError(err)
+		ids = append([]string{g.(*wordleGame).Id}, ids...) // newest first
+	}
+	_, err := Create(ctx, "happy", WithOwner(owner+"-other"))
+	require.NoError(err)
+	g, err := Retrieve(ctx, ids[4])
+	require.NoError(err)
+	_, err = g.Play(ctx, "happy")
+	require.NoError(err)
+
+	tests := []struct {
+		query  Query
+		result []string
+	}{
+		{query: Query{Owner: owner}, result: ids},
+		{query: Query{Owner: owner, Statuses: []GameStatusType{Won}}, result: ids[4:]},
+		{query: Query{Owner: owner, Statuses: []GameStatusType{InPlay, Lost}}, result: ids[:4]},
+		{query: Query{Owner: owner, Since: time.Now().Add(time.Minute)}, result: []string{}},
+	}
+
+	for _, test := range tests {
+		list, next, err := Games(ctx, test.query)
+		require.NoError(err)
+		assert.Empty(next)
+		got := []string{}
+		for _, s := range list {
+			got = append(got, s.Id)
+			assert.Empty(s.Owner)
+		}
+		assert.Equal(test.result, got, test.query)
+	}
+
+	// Pages of two
+	got := []string{}
+	q := Query{Owner: owner, Limit: 2}
+	for {
+		list, next, err := Games(ctx, q)
+		require.NoError(err)
+		for _, s := range list {
+			got = append(got, s.Id)
+		}
+		if len(next) < 1 {
+			break
+		}
+		q.Cursor = next
+	}
+	assert.Equal(ids, got)
+}
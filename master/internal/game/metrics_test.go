@@ -0,0 +1,56 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"aluance.io/wordleserver/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameMetrics(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	created := metrics.GamesCreated.WithLabelValues("SpeedRun")
+	won := metrics.GamesFinished.WithLabelValues("SpeedRun", "Won")
+	resigned := metrics.GamesFinished.WithLabelValues("SpeedRun", "Resigned")
+	length := metrics.InvalidWords.WithLabelValues("length")
+	notWord := metrics.InvalidWords.WithLabelValues("dictionary")
+
+	before := map[string]float64{
+		"created":  testutil.ToFloat64(created),
+		"won":      testutil.ToFloat64(won),
+		"resigned": testutil.ToFloat64(resigned),
+		"length":   testutil.ToFloat64(length),
+		"notWord":  testutil.ToFloat64(notWord),
+	}
+
+	game, err := Create(ctx, "happy", WithMode(SpeedRun))
+	require.NoError(err, "Create() returned error when creating Game")
+	assert.Equal(before["created"]+1, testutil.ToFloat64(created))
+
+	game.Play(ctx, "hap")
+	game.Play(ctx, "zzzzz")
+	game.Play(ctx, "zzzzz")
+	assert.Equal(before["length"]+1, testutil.ToFloat64(length))
+	assert.Equal(before["notWord"]+2, testutil.ToFloat64(notWord))
+
+	game.Play(ctx, "happy")
+	assert.Equal(before["won"]+1, testutil.ToFloat64(won))
+
+	// A game only finishes once
+	game.Play(ctx, "happy")
+	game.Resign(ctx)
+	assert.Equal(before["won"]+1, testutil.ToFloat64(won))
+	assert.Equal(before["resigned"], testutil.ToFloat64(resigned))
+
+	game, err = Create(ctx, "happy", WithMode(SpeedRun))
+	require.NoError(err, "Create() returned error when creating Game")
+	game.Resign(ctx)
+	assert.Equal(before["created"]+2, testutil.ToFloat64(created))
+	assert.Equal(before["resigned"]+1, testutil.ToFloat64(resigned))
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"aluance.io/wordleserver/internal/config"
+)
+
+// Game mode enum
+type GameMode int
+
+const (
+	Classic  GameMode = iota // no time limits
+	Timed                    // each guess must be made within GuessTimeout
+	SpeedRun                 // the whole game must be finished within TimeLimit
+	Daily                    // everyone plays the word of the day
+)
+
+var mapGameModeToString = map[GameMode]string{
+	Classic:  "Classic",
+	Timed:    "Timed",
+	SpeedRun: "SpeedRun",
+	Daily:    "Daily",
+}
+
+var mapStringToGameMode = map[string]GameMode{
+	"Classic":  Classic,
+	"Timed":    Timed,
+	"SpeedRun": SpeedRun,
+	"Daily":    Daily,
+}
+
+// Returns the mode with the given name (case insensitive). An empty name
+// selects Classic.
+func ParseMode(s string) (GameMode, error) {
+	if len(s) < 1 {
+		return Classic, nil
+	}
+	for name, m := range mapStringToGameMode {
+		if strings.EqualFold(name, s) {
+			return m, nil
+		}
+	}
+	return Classic, ErrInvalidMode
+}
+
+// Sets the mode of the game, using the configured time limits
+func WithMode(mode GameMode) Option {
+	return func(g *wordleGame) {
+		g.Mode = mode
+		switch mode {
+		case Timed:
+			g.GuessTimeout = int(config.CONFIG_GAME_GUESSTIMEOUT / time.Second)
+		case SpeedRun:
+			g.TimeLimit = int(config.CONFIG_GAME_TIMELIMIT / time.Second)
+		case Daily:
+			day := g.Created.UTC().Truncate(24 * time.Hour)
+			g.PeriodEnd = day.Add(24 * time.Hour)
+		}
+	}
+}
+
+func (m GameMode) String() string {
+	if s, ok := mapGameModeToString[m]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+func (m GameMode) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString(`"`)
+	buf.WriteString(mapGameModeToString[m])
+	buf.WriteString(`"`)
+	return buf.Bytes(), nil
+}
+
+func (m *GameMode) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	*m = mapStringToGameMode[s]
+	return nil
+}
+
+/////////////
+
+// Returns when the game runs out of time, or the zero time for untimed games
+func (g wordleGame) deadline() time.Time {
+	switch g.Mode {
+	case Timed:
+		// Invalid words do not reset the clock
+		last := g.Created
+		for _, a := range g.Attempts {
+			if a.IsValidWord {
+				last = a.TimeStamp
+			}
+		}
+		return last.Add(time.Duration(g.GuessTimeout) * time.Second)
+	case SpeedRun:
+		return g.Created.Add(time.Duration(g.TimeLimit) * time.Second)
+	}
+
+	return time.Time{}
+}
+
+// Reports whether the secret word must be left out of reports. It is always
+// hidden during play, and for daily games it can be kept hidden until the day
+// is over so that finished players cannot spoil it for others.
+func (g wordleGame) secretHidden(now time.Time) bool {
+	if g.Status == InPlay {
+		return true
+	}
+
+	return g.Mode == Daily && config.Current().HideDailySecret && now.Before(g.PeriodEnd)
+}
+
+// Returns the time spent on the game so far, or in total once it is over
+func (g wordleGame) elapsed(now time.Time) time.Duration {
+	if g.Status != InPlay {
+		now = g.LastUpdated
+	}
+	return now.Sub(g.Created)
+}
+
+// Ends the game as lost if it has run out of time. Returns true when the game
+// was changed and needs to be saved.
+func (g *wordleGame) expire(ctx context.Context, now time.Time) bool {
+	if g.Status != InPlay {
+		return false
+	}
+
+	d := g.deadline()
+	if d.IsZero() || now.Before(d) {
+		return false
+	}
+
+	g.end(ctx, Event{Type: GameLost, Time: d, Reason: LOST_TIMED_OUT})
+
+	return true
+}
@@ -0,0 +1,186 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/dictionary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMode(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		s      string
+		result GameMode
+		err    error
+	}{
+		{s: "", result: Classic, err: nil},
+		{s: "classic", result: Classic, err: nil},
+		{s: "Timed", result: Timed, err: nil},
+		{s: "speedrun", result: SpeedRun, err: nil},
+		{s: "marathon", result: Classic, err: ErrInvalidMode},
+	}
+
+	for _, test := range tests {
+		m, err := ParseMode(test.s)
+		if test.err != nil {
+			assert.ErrorIs(err, test.err)
+			continue // This test returned a valid error so move to the next test
+		}
+		assert.NoError(err)
+		assert.Equal(test.result, m)
+
+		b, err := m.MarshalJSON()
+		assert.NoError(err)
+		var back GameMode
+		assert.NoError(back.UnmarshalJSON(b))
+		assert.Equal(m, back)
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// Classic games never run out of time
+	game, err := Create(ctx, "happy")
+	require.NoError(err, "Create() returned error when creating Game")
+	v := game.(*wordleGame)
+	assert.True(v.deadline().IsZero())
+	assert.False(v.expire(ctx, time.Now().Add(24*time.Hour)))
+
+	// Speed runs have a fixed deadline
+	game, err = Create(ctx, "happy", WithMode(SpeedRun))
+	require.NoError(err, "Create() returned error when creating Game")
+	v = game.(*wordleGame)
+	assert.Equal(v.Created.Add(config.CONFIG_GAME_TIMELIMIT), v.deadline())
+	game.Play(ctx, "bless")
+	assert.Equal(v.Created.Add(config.CONFIG_GAME_TIMELIMIT), v.deadline())
+
+	// Timed games get a new deadline with every valid guess
+	game, err = Create(ctx, "happy", WithMode(Timed))
+	require.NoError(err, "Create() returned error when creating Game")
+	v = game.(*wordleGame)
+	assert.Equal(v.Created.Add(config.CONFIG_GAME_GUESSTIMEOUT), v.deadline())
+	game.Play(ctx, "bless")
+	first := v.deadline()
+	assert.Equal(v.Attempts[0].TimeStamp.Add(config.CONFIG_GAME_GUESSTIMEOUT), first)
+	game.Play(ctx, "zzzzz")
+	assert.Equal(first, v.deadline(), "invalid words must not reset the clock")
+}
+
+func TestExpire(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	game, err := Create(ctx, "happy", WithMode(Timed))
+	require.NoError(err, "Create() returned error when creating Game")
+	v := game.(*wordleGame)
+
+	// Pretend the game was started long ago
+	v.Created = v.Created.Add(-2 * config.CONFIG_GAME_GUESSTIMEOUT)
+
+	s, err := game.Play(ctx, "happy")
+	assert.ErrorIs(err, ErrGameOver)
+	assert.Equal(Lost, v.Status)
+	assert.True(v.TimedOut)
+	assert.Empty(v.Attempts)
+	assert.Equal(v.deadline(), v.LastUpdated)
+
+	out := map[string]interface{}{}
+	require.NoError(json.Unmarshal([]byte(s), &out))
+	assert.Equal("Lost", out["gameStatus"])
+	assert.Equal(true, out["timedOut"])
+	assert.InDelta(config.CONFIG_GAME_GUESSTIMEOUT.Seconds(), out["elapsedSeconds"], 0.001)
+	assert.NotContains(out, "timeRemainingSeconds")
+
+	// Finished games are left alone
+	assert.False(v.expire(ctx, time.Now()))
+}
+
+func TestRetrieveExpired(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	game, err := Create(ctx, "happy", WithMode(SpeedRun))
+	require.NoError(err, "Create() returned error when creating Game")
+	v := game.(*wordleGame)
+
+	s, err := game.Describe()
+	require.NoError(err)
+	out := map[string]interface{}{}
+	require.NoError(json.Unmarshal([]byte(s), &out))
+	assert.Equal("SpeedRun", out["mode"])
+	assert.Contains(out, "deadline")
+	assert.Greater(out["timeRemainingSeconds"], 0.0)
+
+	backdate(t, v, 2*config.CONFIG_GAME_TIMELIMIT)
+
+	game, err = Retrieve(ctx, v.Id)
+	require.NoError(err)
+	assert.Equal(Lost, game.(*wordleGame).Status)
+}
+
+func TestDaily(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	_, err := Create(ctx, "happy", WithMode(Daily))
+	assert.ErrorIs(err, ErrDailyWord)
+
+	game, err := Create(ctx, "", WithMode(Daily))
+	require.NoError(err, "Create() returned error when creating Game")
+	v := game.(*wordleGame)
+
+	word, err := dictionary.DailyWord(ctx, v.Created)
+	require.NoError(err)
+	assert.Equal(strings.ToUpper(word), v.SecretWord)
+	assert.True(v.PeriodEnd.After(v.Created))
+	assert.LessOrEqual(v.PeriodEnd.Sub(v.Created), 24*time.Hour)
+	assert.True(v.deadline().IsZero())
+
+	// Everyone plays the same word
+	other, err := Create(ctx, "", WithMode(Daily))
+	require.NoError(err, "Create() returned error when creating Game")
+	assert.Equal(v.SecretWord, other.(*wordleGame).SecretWord)
+}
+
+func TestSecretHidden(t *testing.T) {
+	assert := assert.New(t)
+
+	saved := config.Current()
+	defer config.Set(saved)
+
+	now := time.Now()
+	tests := []struct {
+		game   wordleGame
+		hide   bool
+		result bool
+	}{
+		{game: wordleGame{Status: InPlay}, hide: false, result: true},
+		{game: wordleGame{Status: Won}, hide: true, result: false},
+		{game: wordleGame{Status: Won, Mode: Daily, PeriodEnd: now.Add(time.Hour)}, hide: true, result: true},
+		{game: wordleGame{Status: Won, Mode: Daily, PeriodEnd: now.Add(time.Hour)}, hide: false, result: false},
+		{game: wordleGame{Status: Lost, Mode: Daily, PeriodEnd: now.Add(-time.Hour)}, hide: true, result: false},
+	}
+
+	for _, test := range tests {
+		s := config.Current()
+		s.HideDailySecret = test.hide
+		config.Set(s)
+
+		assert.Equal(test.result, test.game.secretHidden(now), test)
+		assert.Equal(!test.result, strings.Contains(test.game.statusReport(), "secretWord"), test)
+	}
+}
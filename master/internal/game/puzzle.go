@@ -0,0 +1,54 @@
+package game
+
+import (
+	"context"
+
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/puzzle"
+	"aluance.io/wordleserver/internal/tracing"
+)
+
+// Returns a token that can be shared to challenge someone to guess
+// secretWord. Unlike words given to Create, the word must be in the
+// dictionary.
+func NewPuzzle(ctx context.Context, secretWord string) (string, error) {
+	return defaultEngine.NewPuzzle(ctx, secretWord)
+}
+
+// Returns a puzzle token for a word in the dictionary of the engine
+func (e *Engine) NewPuzzle(ctx context.Context, secretWord string) (string, error) {
+	sw, err := e.validateWord(ctx, secretWord)
+	if err != nil {
+		return "", err
+	}
+
+	return puzzle.Seal(sw)
+}
+
+// Factory used to create a game from a puzzle token. The secret word is only
+// revealed once the game is over, as with any other game.
+func CreateFromPuzzle(ctx context.Context, token string, options ...Option) (Game, error) {
+	return defaultEngine.CreateFromPuzzle(ctx, token, options...)
+}
+
+// Creates a game from a puzzle token with the engine
+func (e *Engine) CreateFromPuzzle(ctx context.Context, token string, options ...Option) (_ Game, err error) {
+	ctx, span := tracing.Start(ctx, "game.CreateFromPuzzle")
+	defer func() { tracing.End(span, err) }()
+
+	sw, err := puzzle.Open(token)
+	if err != nil {
+		logging.Warn(ctx, "invalid puzzle token")
+		return nil, err
+	}
+
+	return e.Create(ctx, sw, append(options, fromPuzzle())...)
+}
+
+/////////////
+
+func fromPuzzle() Option {
+	return func(g *wordleGame) {
+		g.Puzzle = true
+	}
+}
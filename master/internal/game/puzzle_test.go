@@ -0,0 +1,73 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"aluance.io/wordleserver/internal/puzzle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPuzzle(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	tests := []struct {
+		secretWord string
+		err        error
+	}{
+		{secretWord: "", err: ErrWordLength},
+		{secretWord: "happiness", err: ErrWordLength},
+		{secretWord: "blagu", err: ErrInvalidWord},
+		{secretWord: "happy", err: nil},
+		{secretWord: "HaPpY", err: nil},
+	}
+
+	for _, test := range tests {
+		token, err := NewPuzzle(ctx, test.secretWord)
+		if test.err != nil {
+			assert.ErrorIs(err, test.err, test.secretWord)
+			continue // This test returned a valid error so move to the next test
+		}
+		assert.NoError(err)
+		assert.NotContains(strings.ToUpper(token), "HAPPY")
+
+		word, err := puzzle.Open(token)
+		assert.NoError(err)
+		assert.Equal("HAPPY", word)
+	}
+}
+
+func TestCreateFromPuzzle(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	token, err := NewPuzzle(ctx, "happy")
+	require.NoError(err)
+
+	game, err := CreateFromPuzzle(ctx, token, WithoutHints())
+	require.NoError(err)
+	v := game.(*wordleGame)
+	assert.Equal("HAPPY", v.SecretWord)
+	assert.True(v.Puzzle)
+	assert.True(v.HintsDisabled)
+
+	// The word stays hidden until the game is over
+	s, err := game.Describe()
+	require.NoError(err)
+	assert.NotContains(s, "HAPPY")
+
+	s, err = game.Play(ctx, "happy")
+	require.NoError(err)
+	out := map[string]interface{}{}
+	require.NoError(json.Unmarshal([]byte(s), &out))
+	assert.Equal("Won", out["gameStatus"])
+	assert.Equal(true, out["puzzle"])
+
+	_, err = CreateFromPuzzle(ctx, "bogus")
+	assert.ErrorIs(err, puzzle.ErrInvalidToken)
+}
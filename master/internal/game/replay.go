@@ -0,0 +1,88 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+
+	"aluance.io/wordleserver/internal/solver"
+	"aluance.io/wordleserver/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// One attempt of a replay, in the order it was made
+type ReplayStep struct {
+	Attempt          int          `json:"attempt"`
+	TryWord          string       `json:"tryWord"`
+	IsValidWord      bool         `json:"isValidWord"`
+	TryResult        []LetterHint `json:"tryResult"`
+	CandidatesLeft   int          `json:"candidatesLeft"`
+	ElapsedSeconds   float64      `json:"elapsedSeconds"`   // since the game was created
+	ThinkTimeSeconds float64      `json:"thinkTimeSeconds"` // since the previous attempt
+}
+
+// Returns the timeline of a finished game for playback. Like the analysis,
+// it is only available once the secret word may be revealed.
+func (g wordleGame) Replay(ctx context.Context) (_ string, err error) {
+	_, span := tracing.Start(ctx, "game.Replay", attribute.String("game.id", g.Id))
+	defer func() { tracing.End(span, err) }()
+
+	if g.Status == InPlay {
+		return g.statusReport(), ErrGameInPlay
+	}
+	if g.secretHidden(g.now()) {
+		return g.statusReport(), ErrPeriodOpen
+	}
+
+	s, err := g.eng().wordleSolver()
+	if err != nil {
+		return g.statusReport(), err
+	}
+
+	report := struct {
+		Id             string         `json:"id"`
+		Status         GameStatusType `json:"gameStatus"`
+		Mode           GameMode       `json:"mode"`
+		SecretWord     string         `json:"secretWord"`
+		TimedOut       bool           `json:"timedOut"`
+		Candidates     int            `json:"candidates"` // before the first attempt
+		ElapsedSeconds float64        `json:"elapsedSeconds"`
+		Steps          []ReplayStep   `json:"steps"`
+	}{
+		Id:             g.Id,
+		Status:         g.Status,
+		Mode:           g.Mode,
+		SecretWord:     g.SecretWord,
+		TimedOut:       g.TimedOut,
+		Candidates:     len(s.Candidates(nil)),
+		ElapsedSeconds: g.elapsed(g.now()).Seconds(),
+		Steps:          []ReplayStep{},
+	}
+
+	guesses := []solver.Guess{}
+	left := report.Candidates
+	last := g.Created
+	for i, a := range g.Attempts {
+		// Invalid words are not scored, so they leave the candidates as they were
+		if a.IsValidWord {
+			guesses = append(guesses, solver.Guess{Word: a.TryWord, Pattern: patternOf(a.TryResult)})
+			left = len(s.Candidates(guesses))
+		}
+		report.Steps = append(report.Steps, ReplayStep{
+			Attempt:          i + 1,
+			TryWord:          a.TryWord,
+			IsValidWord:      a.IsValidWord,
+			TryResult:        a.TryResult,
+			CandidatesLeft:   left,
+			ElapsedSeconds:   a.TimeStamp.Sub(g.Created).Seconds(),
+			ThinkTimeSeconds: a.TimeStamp.Sub(last).Seconds(),
+		})
+		last = a.TimeStamp
+	}
+
+	b, err := json.Marshal(report)
+	if err != nil {
+		return "{}", ErrSerialization
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,80 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	game, err := Create(ctx, "happy")
+	require.NoError(err, "Create() returned error when creating Game")
+
+	// Not available while the game is in play
+	_, err = game.Replay(ctx)
+	assert.ErrorIs(err, ErrGameInPlay)
+
+	for _, w := range []string{"bless", "zzzzz", "puppy", "happy"} {
+		game.Play(ctx, w)
+	}
+
+	s, err := game.Replay(ctx)
+	require.NoError(err)
+
+	out := struct {
+		Status     string       `json:"gameStatus"`
+		SecretWord string       `json:"secretWord"`
+		Candidates int          `json:"candidates"`
+		Steps      []ReplayStep `json:"steps"`
+	}{}
+	require.NoError(json.Unmarshal([]byte(s), &out))
+	assert.Equal("Won", out.Status)
+	assert.Equal("HAPPY", out.SecretWord)
+	require.Len(out.Steps, 4)
+
+	left := out.Candidates
+	elapsed := 0.0
+	for i, step := range out.Steps {
+		assert.Equal(i+1, step.Attempt)
+		assert.LessOrEqual(step.CandidatesLeft, left, step.TryWord)
+		assert.GreaterOrEqual(step.CandidatesLeft, 1, "the secret word is always a candidate")
+		assert.GreaterOrEqual(step.ElapsedSeconds, elapsed)
+		assert.InDelta(step.ElapsedSeconds-elapsed, step.ThinkTimeSeconds, 0.000001)
+		left, elapsed = step.CandidatesLeft, step.ElapsedSeconds
+	}
+
+	// The invalid word left the candidates alone
+	assert.False(out.Steps[1].IsValidWord)
+	assert.Equal(out.Steps[0].CandidatesLeft, out.Steps[1].CandidatesLeft)
+
+	// The winning guess leaves exactly one candidate
+	assert.Equal([]LetterHint{Green, Green, Green, Green, Green}, out.Steps[3].TryResult)
+	assert.Equal(1, out.Steps[3].CandidatesLeft)
+}
+
+func TestReplayDaily(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	game, err := Create(ctx, "", WithMode(Daily))
+	require.NoError(err, "Create() returned error when creating Game")
+	_, err = game.Resign(ctx)
+	require.NoError(err)
+
+	// Hidden until the day is over
+	_, err = game.Replay(ctx)
+	assert.ErrorIs(err, ErrPeriodOpen)
+
+	game.(*wordleGame).PeriodEnd = time.Now().Add(-time.Minute)
+	_, err = game.Replay(ctx)
+	assert.NoError(err)
+}
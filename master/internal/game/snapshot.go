@@ -0,0 +1,142 @@
+package game
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Formats written by Export
+const SNAPSHOT_JSONL = "jsonl"
+const SNAPSHOT_GZIP = "gzip"
+
+// Writes every game in the store to w as JSON Lines, one game per line and
+// oldest first, compressed with gzip when format is SNAPSHOT_GZIP. Nothing is
+// written when an entry of the store cannot be read as a game. Returns how
+// many games were written.
+func Export(ctx context.Context, w io.Writer, format string) (int, error) {
+	return defaultEngine.Export(ctx, w, format)
+}
+
+// Writes every game in the store of the engine to w
+func (e *Engine) Export(ctx context.Context, w io.Writer, format string) (n int, err error) {
+	ctx, span := tracing.Start(ctx, "game.Export", attribute.String("game.format", format))
+	defer func() {
+		span.SetAttributes(attribute.Int("game.count", n))
+		tracing.End(span, err)
+	}()
+
+	if format != SNAPSHOT_JSONL && format != SNAPSHOT_GZIP {
+		return 0, ErrSnapshotFormat
+	}
+	games, unreadable, err := e.loadAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(unreadable) > 0 {
+		return 0, ErrUnreadable
+	}
+
+	if format == SNAPSHOT_GZIP {
+		zw := gzip.NewWriter(w)
+		defer func() {
+			if cerr := zw.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = zw
+	}
+	enc := json.NewEncoder(w)
+	for _, g := range games {
+		if err := enc.Encode(g); err != nil {
+			return n, err
+		}
+		n++
+	}
+	logging.Info(ctx, "games exported", "count", n, "format", format)
+
+	return n, nil
+}
+
+// Saves the games written by Export to r, in either format, replacing games
+// with the same id. When purge is set every other game is removed. Nothing is
+// changed unless every game in r is valid. Returns how many games were saved.
+func Import(ctx context.Context, r io.Reader, purge bool) (int, error) {
+	return defaultEngine.Import(ctx, r, purge)
+}
+
+// Saves the games written by Export to the store of the engine
+func (e *Engine) Import(ctx context.Context, r io.Reader, purge bool) (n int, err error) {
+	ctx, span := tracing.Start(ctx, "game.Import")
+	defer func() {
+		span.SetAttributes(attribute.Int("game.count", n))
+		tracing.End(span, err)
+	}()
+
+	games, err := readSnapshot(r)
+	if err != nil {
+		return 0, err
+	}
+
+	s, err := e.store()
+	if err != nil {
+		return 0, err
+	}
+	if purge {
+		if err := s.PurgeAll(ctx); err != nil {
+			return 0, err
+		}
+	}
+	for _, g := range games {
+		if err := s.Save(ctx, g.Id, g); err != nil {
+			return n, err
+		}
+		n++
+	}
+	logging.Info(ctx, "games imported", "count", n)
+
+	return n, nil
+}
+
+/////////////
+
+// Returns the games in a snapshot, rebuilt from their events
+func readSnapshot(r io.Reader) ([]*wordleGame, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, ErrSnapshot
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		r = br
+	}
+
+	games := []*wordleGame{}
+	dec := json.NewDecoder(r)
+	for {
+		var stored struct {
+			Events []Event `json:"events"`
+		}
+		if err := dec.Decode(&stored); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, ErrSnapshot
+		}
+		g, err := rebuild(stored.Events)
+		if err != nil || len(g.Id) < 1 {
+			return nil, ErrSnapshot
+		}
+		games = append(games, g)
+	}
+
+	return games, nil
+}
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+
+	"aluance.io/wordleserver/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	require.NoError(PurgeAll(ctx))
+	for _, word := range []string{"happy", "puppy"} {
+		g, err := Create(ctx, word)
+		require.NoError(err)
+		_, err = g.Play(ctx, "lucky")
+		require.NoError(err)
+	}
+
+	var plain bytes.Buffer
+	n, err := Export(ctx, &plain, SNAPSHOT_JSONL)
+	require.NoError(err)
+	assert.Equal(2, n)
+	lines := strings.Split(strings.TrimSpace(plain.String()), "\n")
+	require.Len(lines, 2)
+	assert.Contains(lines[0], `"secretWord":"HAPPY"`)
+	assert.Contains(lines[1], `"secretWord":"PUPPY"`)
+
+	var zipped bytes.Buffer
+	n, err = Export(ctx, &zipped, SNAPSHOT_GZIP)
+	require.NoError(err)
+	assert.Equal(2, n)
+	zr, err := gzip.NewReader(&zipped)
+	require.NoError(err)
+	var unzipped bytes.Buffer
+	_, err = unzipped.ReadFrom(zr)
+	require.NoError(err)
+	assert.Equal(plain.String(), unzipped.String())
+
+	_, err = Export(ctx, &plain, "xml")
+	assert.Equal(ErrSnapshotFormat, err)
+}
+
+func TestExportUnreadable(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	store.Use(store.NewMemoryStore())
+	defer store.Use(nil)
+	_, err := Create(ctx, "happy")
+	require.NoError(err)
+	s, err := store.WordleStore()
+	require.NoError(err)
+	require.NoError(s.Save(ctx, "broken", []byte(`{"events":[]}`)))
+
+	// A snapshot is never missing games
+	for _, format := range []string{SNAPSHOT_JSONL, SNAPSHOT_GZIP} {
+		var out bytes.Buffer
+		n, err := Export(ctx, &out, format)
+		assert.Equal(ErrUnreadable, err, format)
+		assert.Zero(n, format)
+		assert.Zero(out.Len(), format)
+	}
+
+	stats, err := Statistics(ctx)
+	require.NoError(err)
+	assert.Equal(2, stats.Keys)
+	assert.Equal(1, stats.Games)
+	assert.Equal([]string{"broken"}, stats.Unreadable)
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// Export from the memory store
+	require.NoError(PurgeAll(ctx))
+	g, err := Create(ctx, "happy", WithOwner("player:p1"))
+	require.NoError(err)
+	_, err = g.Play(ctx, "puppy")
+	require.NoError(err)
+	id := g.(*wordleGame).Id
+	var snapshot bytes.Buffer
+	_, err = Export(ctx, &snapshot, SNAPSHOT_GZIP)
+	require.NoError(err)
+
+	// and import into SQLite, next to a game that is already there
+	useSqlite(t)
+	other, err := Create(ctx, "lucky")
+	require.NoError(err)
+
+	tests := []struct {
+		snapshot string
+		purge    bool
+		result   int
+		games    int
+		err      error
+	}{
+		{snapshot: "not json", err: ErrSnapshot, games: 1},
+		{snapshot: `{"events":[]}`, err: ErrSnapshot, games: 1},
+		{snapshot: "\x1f\x8bnot gzip", err: ErrSnapshot, games: 1},
+		{snapshot: snapshot.String(), result: 1, games: 2},
+		{snapshot: snapshot.String(), result: 1, games: 2}, // again, replacing it
+		{snapshot: snapshot.String(), purge: true, result: 1, games: 1},
+		{snapshot: "", result: 0, games: 1},
+	}
+
+	for i, test := range tests {
+		n, err := Import(ctx, strings.NewReader(test.snapshot), test.purge)
+		assert.Equal(test.err, err, i)
+		assert.Equal(test.result, n, i)
+		stats, err := Statistics(ctx)
+		require.NoError(err)
+		assert.Equal(test.games, stats.Games, i)
+	}
+
+	// The imported game carries on where it was
+	_, err = Retrieve(ctx, other.(*wordleGame).Id)
+	assert.Error(err)
+	imported, err := Retrieve(ctx, id)
+	require.NoError(err)
+	assert.Equal(g.Version(), imported.Version())
+	_, err = imported.Play(ctx, "happy")
+	require.NoError(err)
+	list, _, err := Games(ctx, Query{Owner: "player:p1"})
+	require.NoError(err)
+	require.Len(list, 1)
+	assert.Equal(Won, list[0].Status)
+}
@@ -0,0 +1,60 @@
+package game
+
+import (
+	"context"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/solver"
+	"aluance.io/wordleserver/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Lets the solver play against secretWord with the named strategy, taking its
+// best suggestion each turn, and returns its attempts. It stops when it wins or
+// has made as many valid attempts as a game allows.
+func Solve(ctx context.Context, secretWord string, strategy string) ([]*WordleAttempt, error) {
+	return defaultEngine.Solve(ctx, secretWord, strategy)
+}
+
+// Lets the solver play against secretWord with the words of the engine
+func (e *Engine) Solve(ctx context.Context, secretWord string, strategy string) (attempts []*WordleAttempt, err error) {
+	ctx, span := tracing.Start(ctx, "game.Solve", attribute.String("hint.strategy", strategy))
+	defer func() {
+		span.SetAttributes(attribute.Int("game.attempts", len(attempts)))
+		tracing.End(span, err)
+	}()
+
+	st, err := solver.ParseStrategy(strategy)
+	if err != nil {
+		return nil, err
+	}
+	sw, err := e.validateWord(ctx, secretWord)
+	if err != nil {
+		return nil, err
+	}
+	s, err := e.wordleSolver()
+	if err != nil {
+		return nil, err
+	}
+
+	attempts = []*WordleAttempt{}
+	guesses := []solver.Guess{}
+	for len(attempts) < config.CONFIG_GAME_MAXVALIDATTEMPTS {
+		_, suggestions := s.Suggest(guesses, st, 1)
+		if len(suggestions) < 1 {
+			break
+		}
+		word := suggestions[0].Word
+		score := make([]LetterHint, config.CONFIG_GAME_WORDLENGTH)
+		scoreLetters(sw, word, score)
+
+		a := &WordleAttempt{TryWord: word, IsValidWord: true, TryResult: score}
+		attempts = append(attempts, a)
+		guesses = append(guesses, solver.Guess{Word: word, Pattern: patternOf(score)})
+		if a.isWinner() {
+			break
+		}
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,42 @@
+package game
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/solver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolve(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		word     string
+		strategy string
+		err      error
+	}{
+		{word: "happy", strategy: "entropy"},
+		{word: "lucky", strategy: "minmax"},
+		{word: "puppy"},
+		{word: "xxxxx", err: ErrInvalidWord},
+		{word: "toolong", err: ErrWordLength},
+		{word: "happy", strategy: "random", err: solver.ErrStrategy},
+	}
+
+	for _, test := range tests {
+		attempts, err := Solve(ctx, test.word, test.strategy)
+		assert.Equal(t, test.err, err, test.word)
+		if err != nil {
+			continue
+		}
+		require.NotEmpty(t, attempts, test.word)
+		assert.LessOrEqual(t, len(attempts), config.CONFIG_GAME_MAXVALIDATTEMPTS, test.word)
+		last := attempts[len(attempts)-1]
+		assert.True(t, last.isWinner(), test.word)
+		assert.Equal(t, strings.ToUpper(test.word), last.TryWord)
+	}
+}
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"context"
+	"time"
+
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/store"
+)
+
+// Starts ending timed games that have run out of time every interval. Games
+// are also expired when they are next retrieved, so the sweeper only keeps
+// the store tidy. Call the returned function to stop it; it returns once any
+// sweep in progress has finished.
+func StartSweeper(interval time.Duration) (stop func()) {
+	return defaultEngine.StartSweeper(interval)
+}
+
+// Starts ending the timed games of the engine that have run out of time
+// every interval
+func (e *Engine) StartSweeper(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				e.Sweep(context.Background())
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// Ends every timed game in the store that has run out of time and returns
+// how many were ended.
+func Sweep(ctx context.Context) (int, error) {
+	return defaultEngine.Sweep(ctx)
+}
+
+// Ends every timed game in the store of the engine that has run out of time.
+// Only the games in play are looked at.
+func (e *Engine) Sweep(ctx context.Context) (int, error) {
+	s, err := e.store()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	now := e.now()
+	q := store.Query{Statuses: []string{InPlay.String()}}
+	for {
+		page, err := s.Query(ctx, q)
+		if err != nil {
+			return count, err
+		}
+		for _, id := range page.Ids {
+			g, err := e.load(ctx, id)
+			if err != nil || !g.expire(ctx, now) {
+				continue
+			}
+			if err := g.save(ctx); err != nil {
+				if err == ErrConflict {
+					continue // changed since, it will be looked at again
+				}
+				return count, err
+			}
+			count++
+		}
+		if len(page.Next) < 1 {
+			break
+		}
+		q.Cursor = page.Next
+	}
+	if count > 0 {
+		logging.Info(ctx, "sweep ended timed games", "count", count)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,113 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweep(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tests := []struct {
+		mode    GameMode
+		age     time.Duration
+		expired bool
+	}{
+		{mode: Classic, age: time.Hour, expired: false},
+		{mode: Timed, age: 0, expired: false},
+		{mode: Timed, age: 2 * config.CONFIG_GAME_GUESSTIMEOUT, expired: true},
+		{mode: SpeedRun, age: 0, expired: false},
+		{mode: SpeedRun, age: 2 * config.CONFIG_GAME_TIMELIMIT, expired: true},
+	}
+
+	games := []*wordleGame{}
+	for _, test := range tests {
+		game, err := Create(ctx, "happy", WithMode(test.mode))
+		require.NoError(err, "Create() returned error when creating Game")
+		games = append(games, backdate(t, game, test.age))
+	}
+
+	count, err := Sweep(ctx)
+	assert.NoError(err)
+	assert.GreaterOrEqual(count, 2)
+
+	for i, test := range tests {
+		if test.expired {
+			assert.Equal(Lost, stored(t, games[i].Id).Status, test.mode)
+		} else {
+			assert.Equal(InPlay, stored(t, games[i].Id).Status, test.mode)
+		}
+	}
+
+	// Nothing left to do
+	count, err = Sweep(ctx)
+	assert.NoError(err)
+	assert.Zero(count)
+}
+
+func TestStartSweeper(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	game, err := Create(ctx, "happy", WithMode(SpeedRun))
+	require.NoError(err, "Create() returned error when creating Game")
+	v := backdate(t, game, 2*config.CONFIG_GAME_TIMELIMIT)
+
+	stop := StartSweeper(time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	stop()
+
+	v = stored(t, v.Id)
+	assert.True(v.TimedOut)
+	assert.Equal(Lost, v.Status)
+}
+
+// Store that counts the games loaded from it and cannot list its keys
+type sweptStore struct {
+	store.Store
+	loaded map[string]int
+}
+
+func (s *sweptStore) Keys(ctx context.Context) ([]string, error) {
+	return nil, errors.New("keys listed")
+}
+
+func (s *sweptStore) Load(ctx context.Context, id string) (interface{}, error) {
+	s.loaded[id]++
+	return s.Store.Load(ctx, id)
+}
+
+func TestSweepInPlay(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	clock := &testClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	s := &sweptStore{Store: store.NewMemoryStore(), loaded: map[string]int{}}
+	e, err := NewEngine(nil, s, clock, nil)
+	require.NoError(err)
+	won, err := e.Create(ctx, "happy", WithMode(Timed))
+	require.NoError(err)
+	_, err = won.Play(ctx, "happy")
+	require.NoError(err)
+	timed, err := e.Create(ctx, "happy", WithMode(Timed))
+	require.NoError(err)
+
+	// Only the games in play are looked at
+	clock.now = clock.now.Add(config.CONFIG_GAME_GUESSTIMEOUT + time.Second)
+	count, err := e.Sweep(ctx)
+	require.NoError(err)
+	assert.Equal(1, count)
+	assert.Zero(s.loaded[won.(*wordleGame).Id])
+	assert.Equal(1, s.loaded[timed.(*wordleGame).Id])
+}
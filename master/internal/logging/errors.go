@@ -0,0 +1,7 @@
+package logging
+
+import "errors"
+
+var (
+	ErrLevel = errors.New("invalid log level")
+)
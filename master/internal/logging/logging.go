@@ -0,0 +1,151 @@
+/*
+Package logging writes structured JSON logs.
+
+Every record logged with a context carries the request id stored in that
+context by WithRequestId, and the ids of the span in that context if any, so
+that the log lines of the api, game and store packages can be tied to the
+request that caused them. The level can be changed
+at any time with SetLevel. Attributes that could hold a secret word are
+redacted, whatever the caller passes in.
+
+Key functions:
+	Debug(ctx, msg, args...), Info(...), Warn(...), Error(...) - Log a message with key/value pairs.
+	SetLevel(name) - Changes the lowest level that is logged.
+	WithRequestId(ctx, id) - Returns a context whose log records carry the request id.
+
+*/
+
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Value written in place of redacted attributes
+const REDACTED = "[REDACTED]"
+
+// Attribute keys and query parameters whose values are never logged
+var redactedKeys = map[string]bool{
+	"secretWord": true,
+	"word":       true,
+}
+
+type contextKey int
+
+const requestIdKey contextKey = iota
+
+var level = new(slog.LevelVar)
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	SetOutput(os.Stdout)
+}
+
+// Sends the logs to w
+func SetOutput(w io.Writer) {
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level, ReplaceAttr: redact})
+	logger.Store(slog.New(&contextHandler{h}))
+}
+
+// Changes the lowest level logged to one of "debug", "info", "warn" or "error"
+func SetLevel(name string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return ErrLevel
+	}
+	level.Set(l)
+
+	return nil
+}
+
+// Returns the name of the lowest level logged
+func Level() string {
+	return strings.ToLower(level.Level().String())
+}
+
+// Returns a context whose log records carry the request id
+func WithRequestId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey, id)
+}
+
+// Returns the request id carried by the context, if any
+func RequestId(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIdKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// Returns the query with the values of secret parameters replaced
+func RedactQuery(q url.Values) url.Values {
+	out := url.Values{}
+	for k, v := range q {
+		if redactedKeys[k] {
+			out[k] = []string{REDACTED}
+		} else {
+			out[k] = v
+		}
+	}
+
+	return out
+}
+
+func Logger() *slog.Logger {
+	return logger.Load()
+}
+
+func Debug(ctx context.Context, msg string, args ...interface{}) {
+	Logger().DebugContext(ctx, msg, args...)
+}
+
+func Info(ctx context.Context, msg string, args ...interface{}) {
+	Logger().InfoContext(ctx, msg, args...)
+}
+
+func Warn(ctx context.Context, msg string, args ...interface{}) {
+	Logger().WarnContext(ctx, msg, args...)
+}
+
+func Error(ctx context.Context, msg string, args ...interface{}) {
+	Logger().ErrorContext(ctx, msg, args...)
+}
+
+/////////////
+
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if redactedKeys[a.Key] {
+		a.Value = slog.StringValue(REDACTED)
+	}
+	return a
+}
+
+// Adds the request id and any trace of the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestId(ctx); len(id) > 0 {
+		r.AddAttrs(slog.String("requestId", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("traceId", sc.TraceID().String()), slog.String("spanId", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{h.Handler.WithGroup(name)}
+}
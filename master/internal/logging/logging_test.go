@@ -0,0 +1,150 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Returns the records written while fn runs
+func capture(t *testing.T, fn func()) []map[string]interface{} {
+	buf := new(bytes.Buffer)
+	SetOutput(buf)
+	defer SetOutput(os.Stdout)
+
+	fn()
+
+	records := []map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if len(line) < 1 {
+			continue
+		}
+		r := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(line), &r), line)
+		records = append(records, r)
+	}
+
+	return records
+}
+
+func TestSetLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	defer SetLevel("info")
+
+	tests := []struct {
+		name   string
+		result string
+		err    error
+	}{
+		{name: "debug", result: "debug", err: nil},
+		{name: "WARN", result: "warn", err: nil},
+		{name: "error", result: "error", err: nil},
+		{name: "info", result: "info", err: nil},
+		{name: "loud", result: "info", err: ErrLevel},
+	}
+
+	for _, test := range tests {
+		err := SetLevel(test.name)
+		if test.err != nil {
+			assert.ErrorIs(err, test.err)
+		} else {
+			assert.NoError(err)
+		}
+		assert.Equal(test.result, Level())
+	}
+}
+
+func TestLevelFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	defer SetLevel("info")
+	ctx := context.Background()
+
+	SetLevel("warn")
+	records := capture(t, func() {
+		Debug(ctx, "debug")
+		Info(ctx, "info")
+		Warn(ctx, "warn")
+		Error(ctx, "error")
+	})
+	if assert.Len(records, 2) {
+		assert.Equal("warn", records[0]["msg"])
+		assert.Equal("ERROR", records[1]["level"])
+	}
+
+	// Changing the level takes effect straight away
+	SetLevel("debug")
+	records = capture(t, func() {
+		Debug(ctx, "debug")
+	})
+	assert.Len(records, 1)
+}
+
+func TestRequestId(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := WithRequestId(context.Background(), "req-1")
+	assert.Equal("req-1", RequestId(ctx))
+	assert.Empty(RequestId(context.Background()))
+
+	records := capture(t, func() {
+		Info(ctx, "with id", "id", "game-1")
+		Logger().With("component", "test").InfoContext(ctx, "derived logger")
+		Info(context.Background(), "without id")
+	})
+	if assert.Len(records, 3) {
+		assert.Equal("req-1", records[0]["requestId"])
+		assert.Equal("game-1", records[0]["id"])
+		assert.Equal("req-1", records[1]["requestId"])
+		assert.Equal("test", records[1]["component"])
+		assert.NotContains(records[2], "requestId")
+	}
+}
+
+func TestTraceIds(t *testing.T) {
+	assert := assert.New(t)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01, 0x02},
+		SpanID:  trace.SpanID{0x03},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	records := capture(t, func() {
+		Info(ctx, "in span")
+		Info(context.Background(), "no span")
+	})
+	if assert.Len(records, 2) {
+		assert.Equal(sc.TraceID().String(), records[0]["traceId"])
+		assert.Equal(sc.SpanID().String(), records[0]["spanId"])
+		assert.NotContains(records[1], "traceId")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	assert := assert.New(t)
+
+	records := capture(t, func() {
+		Info(context.Background(), "created", "secretWord", "HAPPY", "word", "happy", "tryWord", "BLESS")
+	})
+	if assert.Len(records, 1) {
+		assert.Equal(REDACTED, records[0]["secretWord"])
+		assert.Equal(REDACTED, records[0]["word"])
+		assert.Equal("BLESS", records[0]["tryWord"])
+	}
+
+	q := url.Values{"word": {"happy"}, "mode": {"timed"}}
+	out := RedactQuery(q)
+	assert.Equal(REDACTED, out.Get("word"))
+	assert.Equal("timed", out.Get("mode"))
+	assert.Equal("happy", q.Get("word"), "the original must not change")
+}
@@ -0,0 +1,116 @@
+/*
+Package metrics collects the Prometheus metrics of the server.
+
+Metrics are kept in a registry of their own rather than the global default,
+and are served by Handler. Other packages update the exported collectors
+directly; the size of the store and of the dictionary are read when the
+metrics are scraped.
+
+*/
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+
+	"aluance.io/wordleserver/internal/dictionary"
+	"aluance.io/wordleserver/internal/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "wordle"
+
+var (
+	HttpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HttpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to handle HTTP requests, by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	GamesCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_created_total",
+		Help:      "Games created, by mode.",
+	}, []string{"mode"})
+
+	GamesFinished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_finished_total",
+		Help:      "Games finished, by mode and final status (Won, Lost or Resigned).",
+	}, []string{"mode", "status"})
+
+	InvalidWords = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "invalid_words_total",
+		Help:      "Words rejected during validation, by reason (length or dictionary).",
+	}, []string{"reason"})
+
+	RateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Requests refused for going over a limit, by scope (ip, player, game or inplay).",
+	}, []string{"scope"})
+
+	StoreSize = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "store_size",
+		Help:      "Number of entries in the game store.",
+	}, storeSize)
+
+	DictionarySize = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dictionary_size",
+		Help:      "Number of words in the dictionary.",
+	}, dictionarySize)
+)
+
+var registry = prometheus.NewRegistry()
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HttpRequests,
+		HttpDuration,
+		GamesCreated,
+		GamesFinished,
+		InvalidWords,
+		RateLimited,
+		StoreSize,
+		DictionarySize,
+	)
+}
+
+// Returns the handler serving the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+/////////////
+
+func storeSize() float64 {
+	s, err := store.WordleStore()
+	if err != nil {
+		return 0
+	}
+	keys, err := s.Keys(context.Background())
+	if err != nil {
+		return 0
+	}
+
+	return float64(len(keys))
+}
+
+func dictionarySize() float64 {
+	return float64(dictionary.Size())
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"aluance.io/wordleserver/internal/dictionary"
+	"aluance.io/wordleserver/internal/store"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSize(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s, err := store.WordleStore()
+	require.NoError(err)
+	require.NoError(s.PurgeAll(ctx))
+	assert.Equal(0.0, testutil.ToFloat64(StoreSize))
+
+	require.NoError(s.Save(ctx, "1a2b3c4d5e", "content"))
+	require.NoError(s.Save(ctx, "2a4b6c8d0e", "content"))
+	assert.Equal(2.0, testutil.ToFloat64(StoreSize))
+
+	require.NoError(s.Delete(ctx, "1a2b3c4d5e"))
+	assert.Equal(1.0, testutil.ToFloat64(StoreSize))
+}
+
+func TestDictionarySize(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(float64(dictionary.Size()), testutil.ToFloat64(DictionarySize))
+	assert.NotZero(testutil.ToFloat64(DictionarySize))
+}
+
+func TestHandler(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	InvalidWords.WithLabelValues("length").Inc()
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/metrics", nil)
+	require.NoError(err)
+	Handler().ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code)
+
+	body, err := io.ReadAll(w.Body)
+	require.NoError(err)
+	for _, name := range []string{"wordle_invalid_words_total", "wordle_store_size", "wordle_dictionary_size", "go_goroutines"} {
+		assert.Contains(string(body), name)
+	}
+}
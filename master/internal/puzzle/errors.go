@@ -0,0 +1,8 @@
+package puzzle
+
+import "errors"
+
+var (
+	ErrInvalidToken = errors.New("invalid puzzle token")
+	ErrInvalidWord  = errors.New("invalid puzzle word")
+)
@@ -0,0 +1,121 @@
+/*
+Package puzzle turns secret words into opaque tokens that can be shared.
+
+Tokens are sealed with AES-256-GCM, so the word cannot be read from a token and
+a token that has been tampered with is rejected. Every token has a random
+nonce: sealing the same word twice gives two different tokens.
+
+The key is derived from the environment variable named by
+config.CONFIG_PUZZLE_KEY_ENV. When it is not set a random key is used, and
+tokens only stay valid until the server restarts.
+
+Key functions:
+	Seal(word) - Returns a token for the word.
+	Open(token) - Returns the word sealed in a token.
+
+*/
+
+package puzzle
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"sync"
+
+	"aluance.io/wordleserver/internal/config"
+)
+
+// Version of the token format, sealed with the word
+const tokenVersion = 1
+
+// Returns a token for the word
+func Seal(word string) (string, error) {
+	s, err := defaultSealer()
+	if err != nil {
+		return "", err
+	}
+	return s.seal(word)
+}
+
+// Returns the word sealed in the token
+func Open(token string) (string, error) {
+	s, err := defaultSealer()
+	if err != nil {
+		return "", err
+	}
+	return s.open(token)
+}
+
+/////////////
+
+type sealer struct {
+	aead cipher.AEAD
+}
+
+var singleSealer *sealer
+var sealerOnce sync.Once
+var sealerErr error
+
+func defaultSealer() (*sealer, error) {
+	sealerOnce.Do(func() {
+		secret := []byte(os.Getenv(config.CONFIG_PUZZLE_KEY_ENV))
+		if len(secret) < 1 {
+			secret = make([]byte, 32)
+			if _, sealerErr = rand.Read(secret); sealerErr != nil {
+				return
+			}
+		}
+		singleSealer, sealerErr = newSealer(secret)
+	})
+
+	return singleSealer, sealerErr
+}
+
+func newSealer(secret []byte) (*sealer, error) {
+	key := sha256.Sum256(secret)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sealer{aead: aead}, nil
+}
+
+func (s *sealer) seal(word string) (string, error) {
+	if len(word) < 1 {
+		return "", ErrInvalidWord
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	plain := append([]byte{tokenVersion}, word...)
+	sealed := s.aead.Seal(nonce, nonce, plain, nil)
+
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (s *sealer) open(token string) (string, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return "", ErrInvalidToken
+	}
+
+	size := s.aead.NonceSize()
+	plain, err := s.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil || len(plain) < 2 || plain[0] != tokenVersion {
+		return "", ErrInvalidToken
+	}
+
+	return string(plain[1:]), nil
+}
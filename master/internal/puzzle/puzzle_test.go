@@ -0,0 +1,85 @@
+package puzzle
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealOpen(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		word string
+		err  error
+	}{
+		{word: "", err: ErrInvalidWord},
+		{word: "HAPPY", err: nil},
+		{word: "happy", err: nil},
+	}
+
+	for _, test := range tests {
+		token, err := Seal(test.word)
+		if test.err != nil {
+			assert.ErrorIs(err, test.err)
+			continue // This test returned a valid error so move to the next test
+		}
+		assert.NoError(err)
+		assert.NotContains(strings.ToUpper(token), strings.ToUpper(test.word))
+
+		word, err := Open(token)
+		assert.NoError(err)
+		assert.Equal(test.word, word)
+
+		// Every token is different
+		again, err := Seal(test.word)
+		assert.NoError(err)
+		assert.NotEqual(token, again)
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	token, err := Seal("HAPPY")
+	require.NoError(err)
+
+	// Flip a character in the middle of the token
+	b := []byte(token)
+	if b[len(b)/2] == 'A' {
+		b[len(b)/2] = 'B'
+	} else {
+		b[len(b)/2] = 'A'
+	}
+
+	other, err := newSealer([]byte("another key"))
+	require.NoError(err)
+	foreign, err := other.seal("HAPPY")
+	require.NoError(err)
+
+	tests := []string{"", "not base64!", "c2hvcnQ", string(b), token[:len(token)-2], foreign}
+	for _, test := range tests {
+		_, err := Open(test)
+		assert.ErrorIs(err, ErrInvalidToken, test)
+	}
+}
+
+func TestNewSealer(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// The same secret opens tokens of another instance
+	a, err := newSealer([]byte("shared secret"))
+	require.NoError(err)
+	b, err := newSealer([]byte("shared secret"))
+	require.NoError(err)
+
+	token, err := a.seal("PROXY")
+	require.NoError(err)
+	word, err := b.open(token)
+	assert.NoError(err)
+	assert.Equal("PROXY", word)
+}
@@ -0,0 +1,105 @@
+/*
+Package ratelimit keeps a token bucket for every key, such as a client
+address or a game id.
+
+A bucket holds up to burst tokens and is refilled at a steady rate. Each
+request takes a token; when none are left the request is refused and the
+caller is told how long to wait. Buckets that have filled up again are
+forgotten, so keys that stop sending requests do not use memory.
+
+Key functions:
+	New(perMinute, burst) - Returns a limiter, or one that allows everything when perMinute is 0.
+	Limiter.Allow(key, now) - Takes a token from the bucket of key.
+
+*/
+
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// How often buckets that have filled up again are looked for
+const PRUNE_INTERVAL = time.Minute
+
+type Limiter struct {
+	limit   rate.Limit
+	burst   int
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	pruned  time.Time
+}
+
+// Returns a limiter refilling each bucket with perMinute tokens a minute, up
+// to burst. A limiter with no rate allows every request.
+func New(perMinute float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = int(math.Max(1, math.Ceil(perMinute)))
+	}
+
+	return &Limiter{
+		limit:   rate.Limit(perMinute / 60),
+		burst:   burst,
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Reports whether a request for key may go ahead. If not, it also returns how
+// long to wait before the next request would be allowed.
+func (l *Limiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	if l == nil || l.limit <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.pruned) >= PRUNE_INTERVAL {
+		l.prune(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.buckets[key] = b
+	}
+	b.seen = now
+
+	r := b.limiter.ReserveN(now, 1)
+	if d := r.DelayFrom(now); d > 0 {
+		r.CancelAt(now)
+		return false, d
+	}
+
+	return true, 0
+}
+
+// Returns the number of keys with a bucket
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
+
+/////////////
+
+type bucket struct {
+	limiter *rate.Limiter
+	seen    time.Time
+}
+
+// Forgets the buckets that would be full by now, as a new bucket is the same
+func (l *Limiter) prune(now time.Time) {
+	refill := time.Duration(float64(l.burst) / float64(l.limit) * float64(time.Second))
+	for k, b := range l.buckets {
+		if now.Sub(b.seen) >= refill {
+			delete(l.buckets, k)
+		}
+	}
+	l.pruned = now
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllow(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	l := New(60, 3) // one token a second
+
+	// The burst is allowed straight away
+	for i := 0; i < 3; i++ {
+		ok, wait := l.Allow("a", now)
+		assert.True(ok, i)
+		assert.Zero(wait, i)
+	}
+
+	// Then the caller has to wait for the next token
+	ok, wait := l.Allow("a", now)
+	assert.False(ok)
+	assert.Equal(time.Second, wait)
+
+	// Refused requests do not use tokens
+	ok, wait = l.Allow("a", now.Add(500*time.Millisecond))
+	assert.False(ok)
+	assert.Equal(500*time.Millisecond, wait)
+
+	ok, _ = l.Allow("a", now.Add(time.Second))
+	assert.True(ok)
+
+	// Keys have buckets of their own
+	ok, _ = l.Allow("b", now)
+	assert.True(ok)
+}
+
+func TestDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	var none *Limiter
+	for _, l := range []*Limiter{none, New(0, 0)} {
+		for i := 0; i < 100; i++ {
+			ok, wait := l.Allow("a", time.Now())
+			assert.True(ok)
+			assert.Zero(wait)
+		}
+	}
+}
+
+func TestBurstDefault(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	l := New(2.5, 0)
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("a", now)
+		assert.True(ok, i)
+	}
+	ok, _ := l.Allow("a", now)
+	assert.False(ok)
+}
+
+func TestPrune(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	l := New(60, 3) // full again three seconds after the last request
+
+	l.Allow("a", now)
+	l.Allow("b", now.Add(59*time.Second))
+	assert.Equal(2, l.Len())
+
+	// Only the bucket of "a" has been idle long enough to be full
+	l.Allow("c", now.Add(61*time.Second))
+	assert.Equal(2, l.Len())
+
+	// A forgotten key starts with a full bucket
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("a", now.Add(61*time.Second))
+		assert.True(ok, i)
+	}
+}
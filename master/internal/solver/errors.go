@@ -0,0 +1,7 @@
+package solver
+
+import "errors"
+
+var (
+	ErrStrategy = errors.New("unknown strategy")
+)
@@ -0,0 +1,204 @@
+/*
+Package solver ranks Wordle guesses against a word list.
+
+The solver does not know the scoring rules of the game. Instead it is given a
+Scorer, which must return the same feedback Pattern that a player would see
+for a guess against a secret word. Keeping the rules with the caller guarantees
+that the candidates computed here never disagree with the hints a game hands
+out.
+
+Key functions:
+	New(words, scorer) - Returns a solver over the given list of allowed guesses.
+
+	Solver.Candidates(guesses) - Returns the words still consistent with the guesses made so far.
+	Solver.Rank(candidates, strategy, limit) - Returns the best next guesses.
+	Solver.Suggest(guesses, strategy, limit) - Candidates and Rank in one call.
+	Solver.Evaluate(word, candidates) - Returns the score of one particular guess.
+
+*/
+
+package solver
+
+import (
+	"math"
+	"sync"
+)
+
+// Feedback for a single letter
+type Mark int
+
+const (
+	Absent  Mark = iota // letter is not in the word
+	Present             // letter is in the word, wrong position
+	Correct             // letter is in the correct position
+)
+
+// Pattern is the feedback for a whole word, encoded as a base-3 number with
+// one digit per letter.
+type Pattern int
+
+// Scorer returns the Pattern shown when guess is played against secret.
+type Scorer func(secret, guess string) Pattern
+
+// A guess that was played, together with the feedback it received.
+type Guess struct {
+	Word    string
+	Pattern Pattern
+}
+
+// A ranked guess
+type Suggestion struct {
+	Word        string  `json:"word"`
+	Entropy     float64 `json:"entropy"`     // expected information in bits
+	WorstCase   int     `json:"worstCase"`   // size of the largest remaining group
+	IsCandidate bool    `json:"isCandidate"` // the guess could itself be the answer
+}
+
+type Solver struct {
+	words   []string
+	score   Scorer
+	space   int // number of distinct patterns
+	mu      sync.Mutex
+	opening []Suggestion              // every word evaluated against every word
+	opener  map[Strategy][]Suggestion // opening sorted by strategy
+}
+
+// Returns a solver that suggests guesses from words, scored with score.
+func New(words []string, score Scorer) *Solver {
+	s := &Solver{
+		words:  append([]string{}, words...),
+		score:  score,
+		opener: map[Strategy][]Suggestion{},
+	}
+
+	length := 0
+	if len(words) > 0 {
+		length = len(words[0])
+	}
+	s.space = int(math.Pow(3, float64(length)))
+
+	return s
+}
+
+// Returns the pattern for a list of marks
+func PatternOf(marks []Mark) Pattern {
+	p := Pattern(0)
+	for _, m := range marks {
+		p = p*3 + Pattern(m)
+	}
+	return p
+}
+
+// Returns the words that would have produced the feedback of every guess.
+func (s *Solver) Candidates(guesses []Guess) []string {
+	candidates := []string{}
+	for _, w := range s.words {
+		if s.consistent(w, guesses) {
+			candidates = append(candidates, w)
+		}
+	}
+
+	return candidates
+}
+
+// Returns up to limit suggestions (all of them when limit < 1), best first.
+func (s *Solver) Rank(candidates []string, strategy Strategy, limit int) []Suggestion {
+	return truncate(s.rank(candidates, strategy), limit)
+}
+
+// Returns the remaining candidates and up to limit ranked suggestions for
+// the next guess.
+func (s *Solver) Suggest(guesses []Guess, strategy Strategy, limit int) ([]string, []Suggestion) {
+	if len(guesses) > 0 {
+		candidates := s.Candidates(guesses)
+		return candidates, s.Rank(candidates, strategy, limit)
+	}
+
+	// The opening ranking never changes, so it is only computed once.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.opening == nil {
+		s.opening = s.evaluate(s.words)
+	}
+	if _, ok := s.opener[strategy]; !ok {
+		s.opener[strategy] = strategy.sort(append([]Suggestion{}, s.opening...))
+	}
+
+	return append([]string{}, s.words...), truncate(s.opener[strategy], limit)
+}
+
+// Returns how well word splits the candidates.
+func (s *Solver) Evaluate(word string, candidates []string) Suggestion {
+	sg := Suggestion{Word: word}
+	if len(candidates) < 1 {
+		return sg
+	}
+
+	buckets := make([]int, s.space)
+	for _, c := range candidates {
+		if c == word {
+			sg.IsCandidate = true
+		}
+		p := s.score(c, word)
+		if p >= 0 && int(p) < s.space {
+			buckets[p]++
+		}
+	}
+
+	total := float64(len(candidates))
+	for _, n := range buckets {
+		if n < 1 {
+			continue
+		}
+		if n > sg.WorstCase {
+			sg.WorstCase = n
+		}
+		prob := float64(n) / total
+		sg.Entropy -= prob * math.Log2(prob)
+	}
+
+	return sg
+}
+
+/////////////
+
+func (s *Solver) consistent(word string, guesses []Guess) bool {
+	for _, g := range guesses {
+		if s.score(word, g.Word) != g.Pattern {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Solver) rank(candidates []string, strategy Strategy) []Suggestion {
+	return strategy.sort(s.evaluate(candidates))
+}
+
+func (s *Solver) evaluate(candidates []string) []Suggestion {
+	if len(candidates) < 1 {
+		return []Suggestion{}
+	}
+
+	// With one or two candidates left, nothing beats guessing one of them.
+	pool := s.words
+	if len(candidates) <= 2 {
+		pool = candidates
+	}
+
+	evaluated := make([]Suggestion, 0, len(pool))
+	for _, w := range pool {
+		evaluated = append(evaluated, s.Evaluate(w, candidates))
+	}
+
+	return evaluated
+}
+
+func truncate(ranked []Suggestion, limit int) []Suggestion {
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	return append([]Suggestion{}, ranked...)
+}
@@ -0,0 +1,171 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testWords = []string{"CRANE", "SLATE", "TRACE", "CRATE", "REACT", "HAPPY", "PUPPY", "BLESS"}
+
+// Standard Wordle scoring, good enough to exercise the solver
+func testScorer(secret, guess string) Pattern {
+	marks := make([]Mark, len(guess))
+	left := map[byte]int{}
+	for i := range guess {
+		if secret[i] == guess[i] {
+			marks[i] = Correct
+		} else {
+			left[secret[i]]++
+		}
+	}
+	for i := range guess {
+		if marks[i] != Correct && left[guess[i]] > 0 {
+			marks[i] = Present
+			left[guess[i]]--
+		}
+	}
+	return PatternOf(marks)
+}
+
+func TestPatternOf(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		marks  []Mark
+		result Pattern
+	}{
+		{marks: []Mark{}, result: 0},
+		{marks: []Mark{Absent, Absent, Absent, Absent, Absent}, result: 0},
+		{marks: []Mark{Absent, Absent, Absent, Absent, Present}, result: 1},
+		{marks: []Mark{Absent, Absent, Absent, Present, Absent}, result: 3},
+		{marks: []Mark{Correct, Correct, Correct, Correct, Correct}, result: 242},
+	}
+
+	for _, test := range tests {
+		assert.Equal(test.result, PatternOf(test.marks), test.marks)
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	assert := assert.New(t)
+
+	s := New(testWords, testScorer)
+
+	tests := []struct {
+		guesses []Guess
+		result  []string
+	}{
+		{guesses: []Guess{}, result: testWords},
+		{guesses: []Guess{{Word: "HAPPY", Pattern: testScorer("PUPPY", "HAPPY")}}, result: []string{"PUPPY"}},
+		{guesses: []Guess{{Word: "CRANE", Pattern: testScorer("TRACE", "CRANE")}}, result: []string{"TRACE"}},
+		{guesses: []Guess{{Word: "BLESS", Pattern: testScorer("CRATE", "BLESS")}}, result: []string{"CRANE", "TRACE", "CRATE", "REACT"}},
+		{guesses: []Guess{{Word: "BLESS", Pattern: PatternOf([]Mark{Correct, Correct, Correct, Correct, Absent})}}, result: []string{}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(test.result, s.Candidates(test.guesses), test.guesses)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	assert := assert.New(t)
+
+	s := New(testWords, testScorer)
+
+	// Every candidate gives a different pattern: 2 bits, worst case 1
+	sg := s.Evaluate("CRATE", []string{"CRANE", "SLATE", "TRACE", "CRATE"})
+	assert.InDelta(2.0, sg.Entropy, 1e-9)
+	assert.Equal(1, sg.WorstCase)
+	assert.True(sg.IsCandidate)
+
+	// No letters in common: no information at all
+	sg = s.Evaluate("PUPPY", []string{"CRANE", "SLATE", "TRACE", "CRATE"})
+	assert.InDelta(0.0, sg.Entropy, 1e-9)
+	assert.Equal(4, sg.WorstCase)
+	assert.False(sg.IsCandidate)
+
+	sg = s.Evaluate("PUPPY", []string{})
+	assert.Zero(sg.Entropy)
+	assert.Zero(sg.WorstCase)
+}
+
+func TestRank(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := New(testWords, testScorer)
+	candidates := []string{"CRANE", "TRACE", "CRATE", "REACT"}
+
+	for _, st := range []Strategy{Entropy, MinMax} {
+		ranked := s.Rank(candidates, st, 0)
+		require.Len(ranked, len(testWords), st)
+		for i := 1; i < len(ranked); i++ {
+			assert.False(st.less(ranked[i], ranked[i-1]), "%s: %v ranked after %v", st, ranked[i-1], ranked[i])
+		}
+		assert.NotEqual("BLESS", ranked[0].Word)
+
+		assert.Len(s.Rank(candidates, st, 3), 3)
+	}
+
+	// With two candidates left only they are suggested
+	ranked := s.Rank([]string{"HAPPY", "PUPPY"}, Entropy, 0)
+	require.Len(ranked, 2)
+	assert.True(ranked[0].IsCandidate)
+	assert.Equal(1, ranked[0].WorstCase)
+
+	assert.Empty(s.Rank([]string{}, Entropy, 0))
+}
+
+func TestSuggest(t *testing.T) {
+	assert := assert.New(t)
+
+	s := New(testWords, testScorer)
+
+	candidates, opening := s.Suggest([]Guess{}, Entropy, 2)
+	assert.Equal(testWords, candidates)
+	assert.Len(opening, 2)
+
+	// The cached opening must not be affected by callers
+	opening[0].Word = "XXXXX"
+	_, again := s.Suggest([]Guess{}, Entropy, 2)
+	assert.NotEqual("XXXXX", again[0].Word)
+
+	candidates, ranked := s.Suggest([]Guess{{Word: "HAPPY", Pattern: testScorer("PUPPY", "HAPPY")}}, MinMax, 5)
+	assert.Equal([]string{"PUPPY"}, candidates)
+	if assert.Len(ranked, 1) {
+		assert.Equal("PUPPY", ranked[0].Word)
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		s      string
+		result Strategy
+		err    error
+	}{
+		{s: "", result: Entropy, err: nil},
+		{s: "entropy", result: Entropy, err: nil},
+		{s: "minmax", result: MinMax, err: nil},
+		{s: "random", result: Entropy, err: ErrStrategy},
+	}
+
+	for _, test := range tests {
+		st, err := ParseStrategy(test.s)
+		if test.err != nil {
+			assert.ErrorIs(err, test.err)
+			continue // This test returned a valid error so move to the next test
+		}
+		assert.NoError(err)
+		assert.Equal(test.result, st)
+
+		b, err := st.MarshalJSON()
+		assert.NoError(err)
+		var back Strategy
+		assert.NoError(back.UnmarshalJSON(b))
+		assert.Equal(st, back)
+	}
+}
@@ -0,0 +1,92 @@
+package solver
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// Enum for the ways of ranking guesses
+type Strategy int
+
+const (
+	Entropy Strategy = iota // maximize the expected information
+	MinMax                  // minimize the worst case
+)
+
+var mapStrategyToString = map[Strategy]string{
+	Entropy: "entropy",
+	MinMax:  "minmax",
+}
+
+var mapStringToStrategy = map[string]Strategy{
+	"entropy": Entropy,
+	"minmax":  MinMax,
+}
+
+// Returns the strategy with the given name. An empty name selects Entropy.
+func ParseStrategy(s string) (Strategy, error) {
+	if len(s) < 1 {
+		return Entropy, nil
+	}
+	if st, ok := mapStringToStrategy[s]; ok {
+		return st, nil
+	}
+	return Entropy, ErrStrategy
+}
+
+func (st Strategy) String() string {
+	if s, ok := mapStrategyToString[st]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+func (st Strategy) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString(`"`)
+	buf.WriteString(mapStrategyToString[st])
+	buf.WriteString(`"`)
+	return buf.Bytes(), nil
+}
+
+func (st *Strategy) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	*st = mapStringToStrategy[s]
+	return nil
+}
+
+// Reports whether a ranks ahead of b. Ties are broken by the other measure,
+// then by preferring possible answers, then alphabetically.
+func (st Strategy) less(a, b Suggestion) bool {
+	if st == MinMax {
+		if a.WorstCase != b.WorstCase {
+			return a.WorstCase < b.WorstCase
+		}
+		if a.Entropy != b.Entropy {
+			return a.Entropy > b.Entropy
+		}
+	} else {
+		if a.Entropy != b.Entropy {
+			return a.Entropy > b.Entropy
+		}
+		if a.WorstCase != b.WorstCase {
+			return a.WorstCase < b.WorstCase
+		}
+	}
+	if a.IsCandidate != b.IsCandidate {
+		return a.IsCandidate
+	}
+	return a.Word < b.Word
+}
+
+func (st Strategy) sort(ranked []Suggestion) []Suggestion {
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return st.less(ranked[i], ranked[j])
+	})
+
+	return ranked
+}
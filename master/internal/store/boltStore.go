@@ -0,0 +1,544 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/tracing"
+	bolt "go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// How long to wait for another process to let go of a bolt database
+const BOLT_OPEN_TIMEOUT = time.Second
+
+// Stores that implement Backuper can write a consistent copy of everything
+// they hold while in use
+type Backuper interface {
+	Backup(ctx context.Context, w io.Writer) (int64, error)
+}
+
+// Returns a store that keeps content as JSON in a bolt database. Load returns
+// the JSON as a json.RawMessage.
+//
+// Each kind of data has a bucket of its own, keyed by id: games holds the
+// JSON, versions the version of content implementing Versioned and index the
+// entry of content implementing Indexer. Those entries are also kept in order
+// of creation in the created bucket, and in a bucket per owner and per status
+// in the owners and statuses buckets. Every change is one transaction.
+func NewBoltStore(db *bolt.DB) (Store, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range boltBuckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+// Writes a copy of the bolt database at path to backup. The database must not
+// be in use by a running server; use the admin API to back that up.
+func BackupBolt(ctx context.Context, path string, backup string) (int64, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: BOLT_OPEN_TIMEOUT, ReadOnly: true})
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var n int64
+	err = writeFile(backup, func(w io.Writer) error {
+		return db.View(func(tx *bolt.Tx) error {
+			if err := checkBuckets(tx); err != nil {
+				return err
+			}
+			n, err = tx.WriteTo(w)
+			return err
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	logging.Info(ctx, "store backed up", "path", path, "backup", backup, "bytes", n)
+
+	return n, nil
+}
+
+// Replaces the bolt database at path with a copy of backup, once it has been
+// checked. The database must not be in use by a running server.
+func RestoreBolt(ctx context.Context, backup string, path string) error {
+	b, err := bolt.Open(backup, 0600, &bolt.Options{Timeout: BOLT_OPEN_TIMEOUT, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+	if err := b.View(checkBuckets); err != nil {
+		return err
+	}
+
+	// Holding the database makes sure no server is using it meanwhile
+	if _, err := os.Stat(path); err == nil {
+		db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: BOLT_OPEN_TIMEOUT})
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+	}
+
+	err = writeFile(path, func(w io.Writer) error {
+		return b.View(func(tx *bolt.Tx) error {
+			_, err := tx.WriteTo(w)
+			return err
+		})
+	})
+	if err != nil {
+		return err
+	}
+	logging.Info(ctx, "store restored", "path", path, "backup", backup)
+
+	return nil
+}
+
+func (s *boltStore) Save(ctx context.Context, id string, content interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "store.Save", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return err
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return s.put(tx, id, content)
+	})
+	if err != nil {
+		return err
+	}
+	logging.Debug(ctx, "store save", "id", id)
+
+	return nil
+}
+
+func (s *boltStore) CompareAndSwap(ctx context.Context, id string, version int, content interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "store.CompareAndSwap", attribute.String("store.id", id), attribute.Int("store.version", version))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return err
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		current := 0
+		if tx.Bucket(boltGames).Get([]byte(id)) != nil {
+			v := tx.Bucket(boltVersions).Get([]byte(id))
+			if v == nil {
+				return ErrVersionConflict
+			}
+			current, _ = strconv.Atoi(string(v))
+		}
+		if current != version {
+			logging.Debug(ctx, "store version conflict", "id", id, "version", version, "current", current)
+			return ErrVersionConflict
+		}
+		return s.put(tx, id, content)
+	})
+	if err != nil {
+		return err
+	}
+	logging.Debug(ctx, "store save", "id", id)
+
+	return nil
+}
+
+func (s *boltStore) Load(ctx context.Context, id string) (_ interface{}, err error) {
+	ctx, span := tracing.Start(ctx, "store.Load", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	err = s.db.View(func(tx *bolt.Tx) error {
+		// The value is only valid during the transaction
+		if v := tx.Bucket(boltGames).Get([]byte(id)); v != nil {
+			data = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		logging.Debug(ctx, "store miss", "id", id)
+		return nil, nil
+	}
+
+	return json.RawMessage(data), nil
+}
+
+func (s *boltStore) Exists(ctx context.Context, id string) (_ bool, err error) {
+	_, span := tracing.Start(ctx, "store.Exists", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return false, err
+	}
+
+	found := false
+	err = s.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(boltGames).Get([]byte(id)) != nil
+		return nil
+	})
+	return found, err
+}
+
+func (s *boltStore) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "store.Delete", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return err
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(boltGames).Get([]byte(id)) == nil {
+			return ErrInvalidId
+		}
+		if err := s.unindex(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Bucket(boltVersions).Delete([]byte(id)); err != nil {
+			return err
+		}
+		return tx.Bucket(boltGames).Delete([]byte(id))
+	})
+	if err != nil {
+		return err
+	}
+	logging.Debug(ctx, "store delete", "id", id)
+
+	return nil
+}
+
+func (s *boltStore) PurgeAll(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "store.PurgeAll")
+	defer func() { tracing.End(span, err) }()
+
+	count := 0
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		count = tx.Bucket(boltGames).Stats().KeyN
+		for _, name := range boltBuckets {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logging.Info(ctx, "store purged", "count", count)
+
+	return nil
+}
+
+func (s *boltStore) Keys(ctx context.Context) (_ []string, err error) {
+	_, span := tracing.Start(ctx, "store.Keys")
+	defer func() { tracing.End(span, err) }()
+
+	keys := []string{}
+	err = s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltGames).ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s *boltStore) Query(ctx context.Context, q Query) (_ Page, err error) {
+	_, span := tracing.Start(ctx, "store.Query")
+	defer func() { tracing.End(span, err) }()
+
+	from, err := q.start()
+	if err != nil {
+		return Page{}, err
+	}
+
+	candidates := map[string]Entry{}
+	err = s.db.View(func(tx *bolt.Tx) error {
+		// Only the owner's entries, or those of the statuses, need to be read
+		indexes := []*bolt.Bucket{tx.Bucket(boltCreated)}
+		if len(q.Owner) > 0 {
+			indexes = []*bolt.Bucket{tx.Bucket(boltOwners).Bucket([]byte(q.Owner))}
+		} else if len(q.Statuses) > 0 {
+			indexes = []*bolt.Bucket{}
+			for _, status := range q.Statuses {
+				indexes = append(indexes, tx.Bucket(boltStatuses).Bucket([]byte(status)))
+			}
+		}
+		for _, index := range indexes {
+			if err := readBolt(tx, index, from, q.collector(candidates)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Page{}, err
+	}
+
+	page, err := q.page(candidates)
+	if err != nil {
+		return Page{}, err
+	}
+	span.SetAttributes(attribute.Int("store.count", len(page.Ids)))
+
+	return page, nil
+}
+
+func (s *boltStore) Backup(ctx context.Context, w io.Writer) (n int64, err error) {
+	ctx, span := tracing.Start(ctx, "store.Backup")
+	defer func() { tracing.End(span, err) }()
+
+	err = s.db.View(func(tx *bolt.Tx) error {
+		n, err = tx.WriteTo(w)
+		return err
+	})
+	if err != nil {
+		return n, err
+	}
+	logging.Info(ctx, "store backed up", "bytes", n)
+
+	return n, nil
+}
+
+func (s *boltStore) Ping(ctx context.Context) error {
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return checkBuckets(tx)
+	})
+	if err != nil {
+		logging.Warn(ctx, "bolt database not usable", "error", err.Error())
+		return ErrNotReady
+	}
+
+	return nil
+}
+
+/////////////////
+
+type boltStore struct {
+	db *bolt.DB
+}
+
+var boltGames = []byte("games")
+var boltVersions = []byte("versions")
+var boltIndex = []byte("index")
+var boltCreated = []byte("created")
+var boltOwners = []byte("owners")
+var boltStatuses = []byte("statuses")
+var boltBuckets = [][]byte{boltGames, boltVersions, boltIndex, boltCreated, boltOwners, boltStatuses}
+
+// Opens the bolt database at path, creating it if needed
+func openBolt(path string) (*bolt.DB, Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: BOLT_OPEN_TIMEOUT})
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := NewBoltStore(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return db, s, nil
+}
+
+// Writes content and everything kept about it. Expects to run in a writable
+// transaction.
+func (s *boltStore) put(tx *bolt.Tx, id string, content interface{}) error {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	key := []byte(id)
+
+	if err := tx.Bucket(boltGames).Put(key, data); err != nil {
+		return err
+	}
+	versions := tx.Bucket(boltVersions)
+	if v, ok := content.(Versioned); ok {
+		err = versions.Put(key, []byte(strconv.Itoa(v.Version())))
+	} else {
+		err = versions.Delete(key)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.unindex(tx, id); err != nil {
+		return err
+	}
+	v, ok := content.(Indexer)
+	if !ok {
+		return nil
+	}
+	e := v.Index()
+	entry, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(boltIndex).Put(key, entry); err != nil {
+		return err
+	}
+	for _, index := range boltIndexes(e) {
+		b := tx.Bucket(index.bucket)
+		if len(index.name) > 0 {
+			if b, err = b.CreateBucketIfNotExists([]byte(index.name)); err != nil {
+				return err
+			}
+		}
+		if err := b.Put([]byte(indexKey(e.Created, id)), []byte{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *boltStore) unindex(tx *bolt.Tx, id string) error {
+	key := []byte(id)
+	index := tx.Bucket(boltIndex)
+	v := index.Get(key)
+	if v == nil {
+		return nil
+	}
+	e := Entry{}
+	if err := json.Unmarshal(v, &e); err != nil {
+		return err
+	}
+	if err := index.Delete(key); err != nil {
+		return err
+	}
+
+	for _, index := range boltIndexes(e) {
+		parent := tx.Bucket(index.bucket)
+		b := parent
+		if len(index.name) > 0 {
+			if b = parent.Bucket([]byte(index.name)); b == nil {
+				continue
+			}
+		}
+		if err := b.Delete([]byte(indexKey(e.Created, id))); err != nil {
+			return err
+		}
+		if k, _ := b.Cursor().First(); k == nil && b != parent {
+			if err := parent.DeleteBucket([]byte(index.name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Bucket of an index, or the bucket in it named name when there is a name
+type boltIndexBucket struct {
+	bucket []byte
+	name   string
+}
+
+// Returns the indexes an entry is kept in
+func boltIndexes(e Entry) []boltIndexBucket {
+	indexes := []boltIndexBucket{{bucket: boltCreated}}
+	if len(e.Status) > 0 {
+		indexes = append(indexes, boltIndexBucket{bucket: boltStatuses, name: e.Status})
+	}
+	if len(e.Owner) > 0 {
+		indexes = append(indexes, boltIndexBucket{bucket: boltOwners, name: e.Owner})
+	}
+	return indexes
+}
+
+// Reads an index newest first from after from, giving c the entries until it
+// has enough
+func readBolt(tx *bolt.Tx, b *bolt.Bucket, from string, c *collector) error {
+	if b == nil {
+		return nil
+	}
+
+	index := tx.Bucket(boltIndex)
+	cursor := b.Cursor()
+	k, _ := cursor.Last()
+	if len(from) > 0 {
+		if k, _ = cursor.Seek([]byte(from)); k == nil {
+			k, _ = cursor.Last()
+		} else {
+			k, _ = cursor.Prev()
+		}
+	}
+	for ; k != nil; k, _ = cursor.Prev() {
+		id := idOfIndexKey(string(k))
+		e := Entry{}
+		if err := json.Unmarshal(index.Get([]byte(id)), &e); err != nil {
+			return err
+		}
+		if !c.add(id, e) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Reports ErrNotReady unless every bucket of the store is there
+func checkBuckets(tx *bolt.Tx) error {
+	for _, name := range boltBuckets {
+		if tx.Bucket(name) == nil {
+			return ErrNotReady
+		}
+	}
+	return nil
+}
+
+// Writes a file through a temporary file next to it, so that path is either
+// left as it was or completely written
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,266 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Returns a bolt store in a file of its own
+func newTestBoltStore(t *testing.T) (Store, string) {
+	path := filepath.Join(t.TempDir(), "wordle.bolt")
+	db, s, err := openBolt(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return s, path
+}
+
+func TestBoltSaveAndLoad(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	s, _ := newTestBoltStore(t)
+
+	tests := []struct {
+		id      string
+		content interface{}
+		result  string
+		err     error
+	}{
+		{id: "a", content: "first", result: `"first"`},
+		{id: "b", content: redisContent{Rev: 2}, result: `{"rev":2,"entry":{"Owner":"","Status":"","Created":"0001-01-01T00:00:00Z","Updated":"0001-01-01T00:00:00Z"}}`},
+		{id: "a", content: "replaced", result: `"replaced"`},
+		{id: "", content: "cause an error", err: ErrInvalidId},
+	}
+
+	for _, test := range tests {
+		err := s.Save(ctx, test.id, test.content)
+		assert.Equal(test.err, err, test.id)
+		if err != nil {
+			continue
+		}
+		content, err := s.Load(ctx, test.id)
+		require.NoError(err)
+		assert.Equal(json.RawMessage(test.result), content, test.id)
+	}
+
+	content, err := s.Load(ctx, "missing")
+	assert.NoError(err)
+	assert.Nil(content)
+
+	ok, err := s.Exists(ctx, "a")
+	require.NoError(err)
+	assert.True(ok)
+	ok, err = s.Exists(ctx, "missing")
+	require.NoError(err)
+	assert.False(ok)
+
+	keys, err := s.Keys(ctx)
+	require.NoError(err)
+	assert.Equal([]string{"a", "b"}, keys)
+
+	require.NoError(s.Delete(ctx, "a"))
+	assert.Equal(ErrInvalidId, s.Delete(ctx, "a"))
+	keys, err = s.Keys(ctx)
+	require.NoError(err)
+	assert.Equal([]string{"b"}, keys)
+
+	require.NoError(s.PurgeAll(ctx))
+	keys, err = s.Keys(ctx)
+	require.NoError(err)
+	assert.Empty(keys)
+
+	require.NoError(s.Ping(ctx))
+}
+
+func TestBoltCompareAndSwap(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	s, _ := newTestBoltStore(t)
+
+	tests := []struct {
+		id      string
+		version int
+		content interface{}
+		err     error
+	}{
+		{id: "a", version: 1, content: redisContent{Rev: 1}, err: ErrVersionConflict}, // nothing stored yet
+		{id: "a", version: 0, content: redisContent{Rev: 1}},
+		{id: "a", version: 0, content: redisContent{Rev: 1}, err: ErrVersionConflict},
+		{id: "a", version: 1, content: redisContent{Rev: 2}},
+		{id: "a", version: 1, content: redisContent{Rev: 2}, err: ErrVersionConflict}, // stale
+		{id: "a", version: 2, content: redisContent{Rev: 3}},
+		{id: "b", version: 0, content: "not versioned"},
+		{id: "b", version: 0, content: redisContent{Rev: 1}, err: ErrVersionConflict},
+		{id: "", version: 0, content: redisContent{Rev: 1}, err: ErrInvalidId},
+	}
+
+	for i, test := range tests {
+		err := s.CompareAndSwap(ctx, test.id, test.version, test.content)
+		assert.Equal(test.err, err, i)
+	}
+
+	content, err := s.Load(ctx, "a")
+	require.NoError(err)
+	out := redisContent{}
+	require.NoError(json.Unmarshal(content.(json.RawMessage), &out))
+	assert.Equal(3, out.Rev)
+}
+
+func TestBoltQuery(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	s, _ := newTestBoltStore(t)
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := map[string]Entry{
+		"a": {Owner: "p1", Status: "InPlay", Created: base, Updated: base.Add(time.Hour)},
+		"b": {Owner: "p1", Status: "Won", Created: base.Add(time.Minute), Updated: base.Add(time.Minute)},
+		"c": {Owner: "p2", Status: "InPlay", Created: base.Add(2 * time.Minute), Updated: base.Add(2 * time.Minute)},
+		"d": {Owner: "p1", Status: "Lost", Created: base.Add(3 * time.Minute), Updated: base.Add(3 * time.Minute)},
+		"e": {Owner: "p1", Status: "InPlay", Created: base.Add(3 * time.Minute), Updated: base.Add(3 * time.Minute)},
+	}
+	for id, e := range entries {
+		require.NoError(s.Save(ctx, id, redisContent{Entry: e}))
+	}
+	require.NoError(s.Save(ctx, "f", "not indexed"))
+
+	tests := []struct {
+		query  Query
+		result []string
+	}{
+		{query: Query{}, result: []string{"e", "d", "c", "b", "a"}},
+		{query: Query{Owner: "p1"}, result: []string{"e", "d", "b", "a"}},
+		{query: Query{Owner: "p3"}, result: []string{}},
+		{query: Query{Owner: "p1", Statuses: []string{"InPlay", "Won"}}, result: []string{"e", "b", "a"}},
+		{query: Query{Statuses: []string{"InPlay"}}, result: []string{"e", "c", "a"}},
+		{query: Query{Statuses: []string{"Won", "Lost", "Resigned"}}, result: []string{"d", "b"}},
+		{query: Query{CreatedSince: base.Add(2 * time.Minute)}, result: []string{"e", "d", "c"}},
+		{query: Query{UpdatedSince: base.Add(30 * time.Minute)}, result: []string{"a"}},
+	}
+
+	for _, test := range tests {
+		page, err := s.Query(ctx, test.query)
+		require.NoError(err)
+		assert.Equal(test.result, page.Ids, test.query)
+		assert.Empty(page.Next)
+	}
+
+	// Pages follow on from each other, whichever indexes are read
+	pages := []struct {
+		query  Query
+		result []string
+	}{
+		{query: Query{Owner: "p1", Limit: 2}, result: []string{"e", "d", "b", "a"}},
+		{query: Query{Statuses: []string{"InPlay", "Won"}, Limit: 1}, result: []string{"e", "c", "b", "a"}},
+		{query: Query{Limit: 3}, result: []string{"e", "d", "c", "b", "a"}},
+	}
+	for _, test := range pages {
+		got := []string{}
+		q := test.query
+		for i := 0; i < 5; i++ {
+			page, err := s.Query(ctx, q)
+			require.NoError(err)
+			got = append(got, page.Ids...)
+			if len(page.Next) < 1 {
+				break
+			}
+			q.Cursor = page.Next
+		}
+		assert.Equal(test.result, got, test.query)
+	}
+
+	// The index follows saves and deletes, and owners and statuses with
+	// nothing left go
+	e := entries["c"]
+	e.Owner = "p1"
+	e.Status = "Won"
+	require.NoError(s.Save(ctx, "c", redisContent{Entry: e}))
+	require.NoError(s.Delete(ctx, "a"))
+	page, err := s.Query(ctx, Query{Owner: "p1"})
+	require.NoError(err)
+	assert.Equal([]string{"e", "d", "c", "b"}, page.Ids)
+	page, err = s.Query(ctx, Query{Owner: "p2"})
+	require.NoError(err)
+	assert.Empty(page.Ids)
+	page, err = s.Query(ctx, Query{Statuses: []string{"InPlay"}})
+	require.NoError(err)
+	assert.Equal([]string{"e"}, page.Ids)
+	require.NoError(s.(*boltStore).db.View(func(tx *bolt.Tx) error {
+		assert.Nil(tx.Bucket(boltOwners).Bucket([]byte("p2")))
+		assert.Equal(4, tx.Bucket(boltCreated).Stats().KeyN)
+		return nil
+	}))
+	require.NoError(s.Delete(ctx, "e"))
+	require.NoError(s.(*boltStore).db.View(func(tx *bolt.Tx) error {
+		assert.Nil(tx.Bucket(boltStatuses).Bucket([]byte("InPlay")))
+		return nil
+	}))
+
+	_, err = s.Query(ctx, Query{Cursor: "not a cursor"})
+	assert.ErrorIs(err, ErrInvalidCursor)
+}
+
+func TestBoltBackupAndRestore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wordle.bolt")
+	backup := filepath.Join(dir, "backup.bolt")
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	db, s, err := openBolt(path)
+	require.NoError(err)
+	require.NoError(s.Save(ctx, "a", redisContent{Rev: 1, Entry: Entry{Owner: "p1", Created: created}}))
+
+	// A store in use is backed up through the store, not the file
+	_, err = BackupBolt(ctx, path, backup)
+	assert.Error(err)
+	var buf bytes.Buffer
+	n, err := s.(Backuper).Backup(ctx, &buf)
+	require.NoError(err)
+	assert.Equal(int64(buf.Len()), n)
+	require.NoError(os.WriteFile(backup, buf.Bytes(), 0600))
+
+	require.NoError(s.Save(ctx, "b", "saved after the backup"))
+	assert.Error(RestoreBolt(ctx, backup, path), "in use")
+	require.NoError(db.Close())
+
+	// Offline copies go both ways
+	n, err = BackupBolt(ctx, path, filepath.Join(dir, "offline.bolt"))
+	require.NoError(err)
+	assert.Positive(n)
+	require.NoError(RestoreBolt(ctx, backup, path))
+
+	db, s, err = openBolt(path)
+	require.NoError(err)
+	defer db.Close()
+	keys, err := s.Keys(ctx)
+	require.NoError(err)
+	assert.Equal([]string{"a"}, keys)
+	page, err := s.Query(ctx, Query{Owner: "p1"})
+	require.NoError(err)
+	assert.Equal([]string{"a"}, page.Ids)
+
+	// Anything but a bolt store is refused
+	junk := filepath.Join(dir, "junk")
+	require.NoError(os.WriteFile(junk, []byte("not a database"), 0600))
+	assert.Error(RestoreBolt(ctx, junk, path))
+	_, err = os.Stat(junk)
+	assert.NoError(err)
+}
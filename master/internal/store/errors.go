@@ -3,5 +3,10 @@ package store
 import "errors"
 
 var (
-	ErrInvalidId = errors.New("invalid id")
+	ErrInvalidId       = errors.New("invalid id")
+	ErrNotReady        = errors.New("store not ready")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrVersionConflict = errors.New("stored version has changed")
+	ErrBackend         = errors.New("unknown store backend")
+	ErrSchemaVersion   = errors.New("database schema is newer than this server")
 )
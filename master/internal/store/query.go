@@ -0,0 +1,217 @@
+package store
+
+import (
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Largest page returned by Query, whatever the limit asked for
+const MAX_PAGE_SIZE = 100
+
+// Fields of stored content that can be queried
+type Entry struct {
+	Owner   string
+	Status  string
+	Created time.Time
+	Updated time.Time
+}
+
+// Content that implements Indexer can be found with Query. Other content can
+// only be looked up by id.
+type Indexer interface {
+	Index() Entry
+}
+
+// Selects a page of ids, newest first. Empty or zero fields match anything.
+//
+// The SQL, Redis and bolt stores keep entries in order of creation, in one
+// index of all of them, one per owner and one per status. A query reads the
+// index of its owner, or of each of its statuses, or the index of all, from
+// the cursor on and stops once it has a page; the times are checked on the
+// way. The memory store sorts the entries of the owner or statuses instead.
+type Query struct {
+	Owner        string
+	Statuses     []string
+	CreatedSince time.Time
+	UpdatedSince time.Time
+	Cursor       string // Next of the previous page, or empty for the first
+	Limit        int    // up to MAX_PAGE_SIZE, or 0 for MAX_PAGE_SIZE
+}
+
+// One page of query results
+type Page struct {
+	Ids  []string
+	Next string // cursor for the following page, empty on the last
+}
+
+/////////////
+
+func (q Query) matches(e Entry) bool {
+	if len(q.Owner) > 0 && e.Owner != q.Owner {
+		return false
+	}
+	if len(q.Statuses) > 0 {
+		found := false
+		for _, s := range q.Statuses {
+			if s == e.Status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !q.CreatedSince.IsZero() && e.Created.Before(q.CreatedSince) {
+		return false
+	}
+	if !q.UpdatedSince.IsZero() && e.Updated.Before(q.UpdatedSince) {
+		return false
+	}
+	return true
+}
+
+// Returns the page of candidates that q selects, newest first
+func (q Query) page(candidates map[string]Entry) (Page, error) {
+	var after *cursor
+	if len(q.Cursor) > 0 {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return Page{}, err
+		}
+		after = &c
+	}
+
+	type match struct {
+		id      string
+		created time.Time
+	}
+	matches := []match{}
+	for id, e := range candidates {
+		if !q.matches(e) || (after != nil && !after.before(e.Created, id)) {
+			continue
+		}
+		matches = append(matches, match{id: id, created: e.Created})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].created.Equal(matches[j].created) {
+			return matches[i].created.After(matches[j].created)
+		}
+		return matches[i].id > matches[j].id
+	})
+
+	page := Page{Ids: []string{}}
+	limit := q.limit()
+	for i, m := range matches {
+		if i == limit {
+			last := matches[i-1]
+			page.Next = encodeCursor(last.created, last.id)
+			break
+		}
+		page.Ids = append(page.Ids, m.id)
+	}
+
+	return page, nil
+}
+
+// Returns the index key of the cursor, from which to read on, or an empty
+// key to read from the newest entry
+func (q Query) start() (string, error) {
+	if len(q.Cursor) < 1 {
+		return "", nil
+	}
+	c, err := decodeCursor(q.Cursor)
+	if err != nil {
+		return "", err
+	}
+	return indexKey(c.created, c.id), nil
+}
+
+// Returns a collector adding the entries q selects to candidates
+func (q Query) collector(candidates map[string]Entry) *collector {
+	return &collector{q: q, candidates: candidates}
+}
+
+func (q Query) limit() int {
+	if q.Limit < 1 || q.Limit > MAX_PAGE_SIZE {
+		return MAX_PAGE_SIZE
+	}
+	return q.Limit
+}
+
+// Gathers the entries q selects from one index read newest first. Reading
+// one more than a page from each index q reads is enough to fill the page and
+// know whether there is another.
+type collector struct {
+	q          Query
+	candidates map[string]Entry
+	found      int
+}
+
+// Adds the entry if q selects it, and reports whether to read on
+func (c *collector) add(id string, e Entry) bool {
+	if !c.q.CreatedSince.IsZero() && e.Created.Before(c.q.CreatedSince) {
+		return false
+	}
+	if !c.q.matches(e) {
+		return true
+	}
+	c.candidates[id] = e
+	c.found++
+
+	return c.found <= c.q.limit()
+}
+
+// Returns the key of an entry in an index, which sorts by creation time and
+// then by id
+func indexKey(created time.Time, id string) string {
+	// Flipping the sign bit sorts times before 1970 first
+	n := strconv.FormatUint(uint64(created.UnixNano())^(1<<63), 10)
+	return strings.Repeat("0", 20-len(n)) + n + "." + id
+}
+
+// Returns the id in an index key
+func idOfIndexKey(key string) string {
+	if len(key) < 21 {
+		return ""
+	}
+	return key[21:]
+}
+
+// Position in the results, after the entry created at created with id
+type cursor struct {
+	created time.Time
+	id      string
+}
+
+// Reports whether an entry comes after the cursor, newest first
+func (c cursor) before(created time.Time, id string) bool {
+	if !created.Equal(c.created) {
+		return created.Before(c.created)
+	}
+	return id < c.id
+}
+
+func encodeCursor(created time.Time, id string) string {
+	s := strconv.FormatInt(created.UnixNano(), 10) + "." + id
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func decodeCursor(s string) (cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(b), ".", 2)
+	if len(parts) != 2 || len(parts[1]) < 1 {
+		return cursor{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	return cursor{created: time.Unix(0, nanos), id: parts[1]}, nil
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	assert := assert.New(t)
+
+	created := time.Date(2026, 1, 1, 12, 0, 0, 42, time.UTC)
+	c, err := decodeCursor(encodeCursor(created, "id.with.dots"))
+	assert.NoError(err)
+	assert.True(created.Equal(c.created))
+	assert.Equal("id.with.dots", c.id)
+
+	for _, s := range []string{"", "!!", "bm9kb3Q", "eC55"} {
+		_, err := decodeCursor(s)
+		assert.ErrorIs(err, ErrInvalidCursor, s)
+	}
+}
+
+func TestQueryLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(MAX_PAGE_SIZE, Query{}.limit())
+	assert.Equal(5, Query{Limit: 5}.limit())
+	assert.Equal(MAX_PAGE_SIZE, Query{Limit: MAX_PAGE_SIZE + 1}.limit())
+}
+
+func TestIndexKey(t *testing.T) {
+	assert := assert.New(t)
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	keys := []string{
+		indexKey(time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC), "z"),
+		indexKey(time.Unix(0, 0), "a"),
+		indexKey(base, "a"),
+		indexKey(base, "b"),
+		indexKey(base.Add(time.Nanosecond), "a"),
+		indexKey(base.Add(time.Hour), "a.b"),
+	}
+
+	// Keys sort like the entries, oldest first
+	for i := 1; i < len(keys); i++ {
+		assert.Less(keys[i-1], keys[i], i)
+	}
+	assert.Equal("a.b", idOfIndexKey(keys[len(keys)-1]))
+	assert.Empty(idOfIndexKey("short"))
+}
+
+func TestCollector(t *testing.T) {
+	assert := assert.New(t)
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	candidates := map[string]Entry{}
+	c := Query{Statuses: []string{"Won"}, CreatedSince: base, Limit: 2}.collector(candidates)
+
+	assert.True(c.add("a", Entry{Status: "Won", Created: base.Add(3 * time.Minute)}))
+	assert.True(c.add("b", Entry{Status: "Lost", Created: base.Add(2 * time.Minute)}))
+	assert.True(c.add("c", Entry{Status: "Won", Created: base.Add(time.Minute)}))
+	assert.False(c.add("d", Entry{Status: "Won", Created: base}), "one more than a page")
+	assert.Len(candidates, 3)
+
+	// Entries older than asked for end the reading
+	c = Query{CreatedSince: base}.collector(map[string]Entry{})
+	assert.False(c.add("e", Entry{Created: base.Add(-time.Minute)}))
+}
@@ -0,0 +1,562 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/tracing"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Start of every key the Redis store uses
+const REDIS_KEY_PREFIX = "wordle:"
+
+// Returns a store that keeps content as JSON in Redis, under keys starting
+// with prefix. Content expires ttl after it was last saved, or never when ttl
+// is 0. Load returns the JSON as a json.RawMessage.
+//
+// Each entry is a hash holding the JSON, its version and its index entry. A
+// set holds every id. Sorted sets index the entries implementing Indexer by
+// the order they were created in: one holds them all, another the entries of
+// each owner and another those of each status.
+//
+// Scripts only touch the hash of the entry, so that the store works with Redis
+// Cluster; the sets are changed around them. An entry is added to its sets
+// before it is saved and again after, and only then dropped from the sets it
+// left, so a set may hold an entry it should not but never misses one. Entries
+// that are gone are dropped from the sets when they are next read.
+func NewRedisStore(client redis.UniversalClient, prefix string, ttl time.Duration) Store {
+	return &redisStore{client: client, prefix: prefix, ttl: ttl}
+}
+
+func (s *redisStore) Save(ctx context.Context, id string, content interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "store.Save", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return err
+	}
+
+	if _, err := s.write(ctx, id, false, 0, content); err != nil {
+		return err
+	}
+	logging.Debug(ctx, "store save", "id", id)
+
+	return nil
+}
+
+func (s *redisStore) CompareAndSwap(ctx context.Context, id string, version int, content interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "store.CompareAndSwap", attribute.String("store.id", id), attribute.Int("store.version", version))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return err
+	}
+
+	saved, err := s.write(ctx, id, true, version, content)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		logging.Debug(ctx, "store version conflict", "id", id, "version", version)
+		return ErrVersionConflict
+	}
+	logging.Debug(ctx, "store save", "id", id)
+
+	return nil
+}
+
+func (s *redisStore) Load(ctx context.Context, id string) (_ interface{}, err error) {
+	ctx, span := tracing.Start(ctx, "store.Load", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return nil, err
+	}
+
+	data, err := s.client.HGet(ctx, s.gameKey(id), "data").Result()
+	if err == redis.Nil {
+		logging.Debug(ctx, "store miss", "id", id)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(data), nil
+}
+
+func (s *redisStore) Exists(ctx context.Context, id string) (_ bool, err error) {
+	_, span := tracing.Start(ctx, "store.Exists", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return false, err
+	}
+
+	n, err := s.client.Exists(ctx, s.gameKey(id)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (s *redisStore) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "store.Delete", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return err
+	}
+
+	result, err := redisDelete.Run(ctx, s.client, []string{s.gameKey(id)}).Slice()
+	if err != nil {
+		return err
+	}
+	old, deleted := redisResult(result)
+	if err := s.reindex(ctx, id, old, redisEntry{}); err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrInvalidId
+	}
+	logging.Debug(ctx, "store delete", "id", id)
+
+	return nil
+}
+
+func (s *redisStore) PurgeAll(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "store.PurgeAll")
+	defer func() { tracing.End(span, err) }()
+
+	count, err := s.client.SCard(ctx, s.idsKey()).Result()
+	if err != nil {
+		return err
+	}
+	keys, err := s.scan(ctx, s.prefix+"*")
+	if err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		// Keys in different slots of a cluster cannot be deleted together
+		pipe := s.client.Pipeline()
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+	}
+	logging.Info(ctx, "store purged", "count", count)
+
+	return nil
+}
+
+func (s *redisStore) Keys(ctx context.Context) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "store.Keys")
+	defer func() { tracing.End(span, err) }()
+
+	ids, err := s.client.SMembers(ctx, s.idsKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := s.client.Pipeline()
+	exists := make([]*redis.IntCmd, len(ids))
+	for i, id := range ids {
+		exists[i] = pipe.Exists(ctx, s.gameKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(ids))
+	stale := []interface{}{}
+	for i, id := range ids {
+		if exists[i].Val() > 0 {
+			keys = append(keys, id)
+		} else {
+			stale = append(stale, id)
+		}
+	}
+	s.forget(ctx, s.idsKey(), stale)
+
+	return keys, nil
+}
+
+func (s *redisStore) Query(ctx context.Context, q Query) (_ Page, err error) {
+	ctx, span := tracing.Start(ctx, "store.Query")
+	defer func() { tracing.End(span, err) }()
+
+	from, err := q.start()
+	if err != nil {
+		return Page{}, err
+	}
+
+	// Only the owner's entries, or those of the statuses, need to be read
+	indexes := []string{s.createdKey()}
+	if len(q.Owner) > 0 {
+		indexes = []string{s.ownerKey(q.Owner)}
+	} else if len(q.Statuses) > 0 {
+		indexes = []string{}
+		for _, status := range q.Statuses {
+			indexes = append(indexes, s.statusKey(status))
+		}
+	}
+	candidates := map[string]Entry{}
+	for _, index := range indexes {
+		if err := s.read(ctx, index, from, q.collector(candidates)); err != nil {
+			return Page{}, err
+		}
+	}
+
+	page, err := q.page(candidates)
+	if err != nil {
+		return Page{}, err
+	}
+	span.SetAttributes(attribute.Int("store.count", len(page.Ids)))
+
+	return page, nil
+}
+
+func (s *redisStore) Ping(ctx context.Context) error {
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		logging.Warn(ctx, "redis not reachable", "error", err.Error())
+		return ErrNotReady
+	}
+
+	return nil
+}
+
+/////////////////
+
+type redisStore struct {
+	client redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+// Saves content along with its version and index entry. When check is set,
+// content is only saved if the stored version is version. Returns 1 and the
+// index entry that was replaced when saved, 0 otherwise.
+var redisSave = redis.NewScript(`
+local key = KEYS[1]
+local check, expected, ttl = ARGV[1], ARGV[2], tonumber(ARGV[10])
+
+local old = redis.call('HMGET', key, 'version', 'indexed', 'owner', 'status', 'created')
+if check == '1' then
+	local current = '0'
+	if redis.call('EXISTS', key) == 1 then
+		current = old[1] or ''
+	end
+	if current ~= expected then
+		return {0}
+	end
+end
+
+redis.call('DEL', key)
+redis.call('HSET', key, 'data', ARGV[3], 'version', ARGV[4], 'indexed', ARGV[5],
+	'owner', ARGV[6], 'status', ARGV[7], 'created', ARGV[8], 'updated', ARGV[9])
+if ttl > 0 then
+	redis.call('PEXPIRE', key, ttl)
+end
+return {1, old[2] or '', old[3] or '', old[4] or '', old[5] or ''}
+`)
+
+// Removes an entry. Returns 1 and its index entry when there was one, 0
+// otherwise.
+var redisDelete = redis.NewScript(`
+local key = KEYS[1]
+
+local old = redis.call('HMGET', key, 'indexed', 'owner', 'status', 'created')
+if redis.call('DEL', key) == 0 then
+	return {0}
+end
+return {1, old[1] or '', old[2] or '', old[3] or '', old[4] or ''}
+`)
+
+// Returns a client for url after checking the server answers. A cluster url
+// names one or more of its nodes, the others being found from them.
+func dialRedis(ctx context.Context, url string, cluster bool) (redis.UniversalClient, error) {
+	var client redis.UniversalClient
+	if cluster {
+		opts, err := redis.ParseClusterURL(url)
+		if err != nil {
+			return nil, err
+		}
+		client = redis.NewClusterClient(opts)
+	} else {
+		opts, err := redis.ParseURL(url)
+		if err != nil {
+			return nil, err
+		}
+		client = redis.NewClient(opts)
+	}
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (s *redisStore) write(ctx context.Context, id string, check bool, version int, content interface{}) (bool, error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return false, err
+	}
+
+	stored := ""
+	if v, ok := content.(Versioned); ok {
+		stored = strconv.Itoa(v.Version())
+	}
+	indexed := "0"
+	e := Entry{}
+	if v, ok := content.(Indexer); ok {
+		indexed = "1"
+		e = v.Index()
+	}
+	checked := "0"
+	if check {
+		checked = "1"
+	}
+
+	entry := redisEntry{stored: true, indexed: indexed == "1", Entry: e}
+	if err := s.reindex(ctx, id, redisEntry{}, entry); err != nil {
+		return false, err
+	}
+
+	args := []interface{}{
+		checked, strconv.Itoa(version),
+		data, stored, indexed, e.Owner, e.Status, nanosOf(e.Created), nanosOf(e.Updated),
+		s.ttl.Milliseconds(),
+	}
+	result, err := redisSave.Run(ctx, s.client, []string{s.gameKey(id)}, args...).Slice()
+	if err != nil {
+		return false, err
+	}
+	old, saved := redisResult(result)
+	if !saved {
+		return false, nil
+	}
+
+	return true, s.reindex(ctx, id, old, entry)
+}
+
+// What the sets hold about an entry
+type redisEntry struct {
+	stored  bool
+	indexed bool
+	Entry
+}
+
+// Reads what a script returns: whether it changed the entry and the index
+// entry it replaced
+func redisResult(result []interface{}) (redisEntry, bool) {
+	if len(result) < 5 || result[0] != int64(1) {
+		return redisEntry{}, false
+	}
+
+	// Every saved entry has indexed set, so an empty one means there was none
+	old := redisEntry{
+		stored:  result[1] != "",
+		indexed: result[1] == "1",
+		Entry:   Entry{Owner: stringOf(result[2]), Status: stringOf(result[3]), Created: timeOf(result[4])},
+	}
+	return old, true
+}
+
+// Moves id from the sets of old to those of e. A zero e removes it from all.
+func (s *redisStore) reindex(ctx context.Context, id string, old redisEntry, e redisEntry) error {
+	remove := s.sets(id, old)
+	add := s.sets(id, e)
+	for set, member := range add {
+		if remove[set] == member {
+			delete(remove, set)
+		}
+	}
+	if len(remove) < 1 && len(add) < 1 {
+		return nil
+	}
+
+	pipe := s.client.Pipeline()
+	for set, member := range remove {
+		s.remove(ctx, pipe, set, member)
+	}
+	for set, member := range add {
+		if set == s.idsKey() {
+			pipe.SAdd(ctx, set, member)
+		} else {
+			pipe.ZAdd(ctx, set, redis.Z{Member: member})
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Returns the sets an entry belongs in, with its member in each
+func (s *redisStore) sets(id string, e redisEntry) map[string]string {
+	sets := map[string]string{}
+	if !e.stored {
+		return sets
+	}
+	sets[s.idsKey()] = id
+	if !e.indexed {
+		return sets
+	}
+	key := indexKey(e.Created, id)
+	sets[s.createdKey()] = key
+	if len(e.Status) > 0 {
+		sets[s.statusKey(e.Status)] = key
+	}
+	if len(e.Owner) > 0 {
+		sets[s.ownerKey(e.Owner)] = key
+	}
+	return sets
+}
+
+// Reads an index newest first from after from, giving c the entries until it
+// has enough. Members of entries that are gone are dropped, and those of
+// entries that have moved to other sets skipped.
+func (s *redisStore) read(ctx context.Context, index string, from string, c *collector) error {
+	max := "+"
+	if len(from) > 0 {
+		max = "(" + from
+	}
+	count := c.q.limit() + 1
+
+	for {
+		members, err := s.client.ZRevRangeByLex(ctx, index, &redis.ZRangeBy{Max: max, Min: "-", Count: int64(count)}).Result()
+		if err != nil {
+			return err
+		}
+
+		pipe := s.client.Pipeline()
+		fields := make([]*redis.SliceCmd, len(members))
+		for i, member := range members {
+			fields[i] = pipe.HMGet(ctx, s.gameKey(idOfIndexKey(member)), "indexed", "owner", "status", "created", "updated")
+		}
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return err
+		}
+
+		stale := []interface{}{}
+		more := true
+		for i, member := range members {
+			id := idOfIndexKey(member)
+			v := fields[i].Val()
+			if len(v) < 5 || v[0] == nil {
+				stale = append(stale, member)
+				continue
+			}
+			e := redisEntry{
+				stored:  true,
+				indexed: v[0] == "1",
+				Entry:   Entry{Owner: stringOf(v[1]), Status: stringOf(v[2]), Created: timeOf(v[3]), Updated: timeOf(v[4])},
+			}
+			if s.sets(id, e)[index] != member {
+				continue
+			}
+			if more = c.add(id, e.Entry); !more {
+				break
+			}
+		}
+		s.forget(ctx, index, stale)
+
+		if !more || len(members) < count {
+			return nil
+		}
+		max = "(" + members[len(members)-1]
+	}
+}
+
+// Drops the members of entries that are gone from a set
+func (s *redisStore) forget(ctx context.Context, set string, members []interface{}) {
+	if len(members) < 1 {
+		return
+	}
+	pipe := s.client.Pipeline()
+	for _, member := range members {
+		s.remove(ctx, pipe, set, member.(string))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logging.Warn(ctx, "cannot drop expired ids", "count", len(members), "error", err.Error())
+	}
+}
+
+// Returns the keys matching pattern. Each master of a cluster is scanned for
+// the keys of its own slots.
+func (s *redisStore) scan(ctx context.Context, pattern string) ([]string, error) {
+	var mu sync.Mutex
+	keys := []string{}
+	scan := func(ctx context.Context, node redis.Cmdable) error {
+		iter := node.Scan(ctx, 0, pattern, 100).Iterator()
+		for iter.Next(ctx) {
+			mu.Lock()
+			keys = append(keys, iter.Val())
+			mu.Unlock()
+		}
+		return iter.Err()
+	}
+
+	if cluster, ok := s.client.(*redis.ClusterClient); ok {
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scan(ctx, node)
+		})
+		return keys, err
+	}
+	return keys, scan(ctx, s.client)
+}
+
+func (s *redisStore) remove(ctx context.Context, pipe redis.Pipeliner, set string, member string) {
+	if set == s.idsKey() {
+		pipe.SRem(ctx, set, member)
+	} else {
+		pipe.ZRem(ctx, set, member)
+	}
+}
+
+func (s *redisStore) gameKey(id string) string {
+	return s.prefix + "game:" + id
+}
+
+func (s *redisStore) idsKey() string {
+	return s.prefix + "ids"
+}
+
+func (s *redisStore) createdKey() string {
+	return s.prefix + "index:created"
+}
+
+func (s *redisStore) ownerKey(owner string) string {
+	return s.prefix + "index:owner:" + owner
+}
+
+func (s *redisStore) statusKey(status string) string {
+	return s.prefix + "index:status:" + status
+}
+
+func stringOf(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func nanosOf(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func timeOf(v interface{}) time.Time {
+	nanos, err := strconv.ParseInt(stringOf(v), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
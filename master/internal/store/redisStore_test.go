@@ -0,0 +1,346 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a Redis store backed by an in-process server
+func newTestRedisStore(t *testing.T, ttl time.Duration) (Store, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisStore(client, REDIS_KEY_PREFIX, ttl), server
+}
+
+type redisContent struct {
+	Rev   int   `json:"rev"`
+	Entry Entry `json:"entry"`
+}
+
+func (c redisContent) Version() int {
+	return c.Rev
+}
+
+func (c redisContent) Index() Entry {
+	return c.Entry
+}
+
+func TestRedisSaveAndLoad(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	s, _ := newTestRedisStore(t, 0)
+
+	tests := []struct {
+		id      string
+		content interface{}
+		result  string
+		err     error
+	}{
+		{id: "a", content: "first", result: `"first"`},
+		{id: "b", content: redisContent{Rev: 2}, result: `{"rev":2,"entry":{"Owner":"","Status":"","Created":"0001-01-01T00:00:00Z","Updated":"0001-01-01T00:00:00Z"}}`},
+		{id: "a", content: "replaced", result: `"replaced"`},
+		{id: "", content: "cause an error", err: ErrInvalidId},
+	}
+
+	for _, test := range tests {
+		err := s.Save(ctx, test.id, test.content)
+		assert.Equal(test.err, err, test.id)
+		if err != nil {
+			continue
+		}
+		content, err := s.Load(ctx, test.id)
+		require.NoError(err)
+		assert.Equal(json.RawMessage(test.result), content, test.id)
+	}
+
+	content, err := s.Load(ctx, "missing")
+	assert.NoError(err)
+	assert.Nil(content)
+
+	ok, err := s.Exists(ctx, "a")
+	require.NoError(err)
+	assert.True(ok)
+	ok, err = s.Exists(ctx, "missing")
+	require.NoError(err)
+	assert.False(ok)
+
+	keys, err := s.Keys(ctx)
+	require.NoError(err)
+	assert.ElementsMatch([]string{"a", "b"}, keys)
+
+	require.NoError(s.Delete(ctx, "a"))
+	assert.Equal(ErrInvalidId, s.Delete(ctx, "a"))
+	keys, err = s.Keys(ctx)
+	require.NoError(err)
+	assert.Equal([]string{"b"}, keys)
+
+	require.NoError(s.Ping(ctx))
+}
+
+func TestRedisCompareAndSwap(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	s, _ := newTestRedisStore(t, 0)
+
+	tests := []struct {
+		id      string
+		version int
+		content interface{}
+		err     error
+	}{
+		{id: "a", version: 1, content: redisContent{Rev: 1}, err: ErrVersionConflict}, // nothing stored yet
+		{id: "a", version: 0, content: redisContent{Rev: 1}},
+		{id: "a", version: 0, content: redisContent{Rev: 1}, err: ErrVersionConflict},
+		{id: "a", version: 1, content: redisContent{Rev: 2}},
+		{id: "a", version: 1, content: redisContent{Rev: 2}, err: ErrVersionConflict}, // stale
+		{id: "a", version: 2, content: redisContent{Rev: 3}},
+		{id: "b", version: 0, content: "not versioned"},
+		{id: "b", version: 0, content: redisContent{Rev: 1}, err: ErrVersionConflict},
+		{id: "", version: 0, content: redisContent{Rev: 1}, err: ErrInvalidId},
+	}
+
+	for i, test := range tests {
+		err := s.CompareAndSwap(ctx, test.id, test.version, test.content)
+		assert.Equal(test.err, err, i)
+	}
+
+	content, err := s.Load(ctx, "a")
+	require.NoError(err)
+	out := redisContent{}
+	require.NoError(json.Unmarshal(content.(json.RawMessage), &out))
+	assert.Equal(3, out.Rev)
+}
+
+func TestRedisQuery(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	s, server := newTestRedisStore(t, 0)
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := map[string]Entry{
+		"a": {Owner: "p1", Status: "InPlay", Created: base, Updated: base.Add(time.Hour)},
+		"b": {Owner: "p1", Status: "Won", Created: base.Add(time.Minute), Updated: base.Add(time.Minute)},
+		"c": {Owner: "p2", Status: "InPlay", Created: base.Add(2 * time.Minute), Updated: base.Add(2 * time.Minute)},
+		"d": {Owner: "p1", Status: "Lost", Created: base.Add(3 * time.Minute), Updated: base.Add(3 * time.Minute)},
+		"e": {Owner: "p1", Status: "InPlay", Created: base.Add(3 * time.Minute), Updated: base.Add(3 * time.Minute)},
+	}
+	for id, e := range entries {
+		require.NoError(s.Save(ctx, id, redisContent{Entry: e}))
+	}
+	require.NoError(s.Save(ctx, "f", "not indexed"))
+
+	tests := []struct {
+		query  Query
+		result []string
+	}{
+		{query: Query{}, result: []string{"e", "d", "c", "b", "a"}},
+		{query: Query{Owner: "p1"}, result: []string{"e", "d", "b", "a"}},
+		{query: Query{Owner: "p3"}, result: []string{}},
+		{query: Query{Owner: "p1", Statuses: []string{"InPlay", "Won"}}, result: []string{"e", "b", "a"}},
+		{query: Query{Statuses: []string{"InPlay"}}, result: []string{"e", "c", "a"}},
+		{query: Query{Statuses: []string{"Won", "Lost", "Resigned"}}, result: []string{"d", "b"}},
+		{query: Query{CreatedSince: base.Add(2 * time.Minute)}, result: []string{"e", "d", "c"}},
+		{query: Query{UpdatedSince: base.Add(30 * time.Minute)}, result: []string{"a"}},
+	}
+
+	for _, test := range tests {
+		page, err := s.Query(ctx, test.query)
+		require.NoError(err)
+		assert.Equal(test.result, page.Ids, test.query)
+		assert.Empty(page.Next)
+	}
+
+	// Pages follow on from each other, whichever indexes are read
+	pages := []struct {
+		query  Query
+		result []string
+	}{
+		{query: Query{Owner: "p1", Limit: 2}, result: []string{"e", "d", "b", "a"}},
+		{query: Query{Statuses: []string{"InPlay", "Won"}, Limit: 1}, result: []string{"e", "c", "b", "a"}},
+		{query: Query{Limit: 3}, result: []string{"e", "d", "c", "b", "a"}},
+	}
+	for _, test := range pages {
+		got := []string{}
+		q := test.query
+		for i := 0; i < 5; i++ {
+			page, err := s.Query(ctx, q)
+			require.NoError(err)
+			got = append(got, page.Ids...)
+			if len(page.Next) < 1 {
+				break
+			}
+			q.Cursor = page.Next
+		}
+		assert.Equal(test.result, got, test.query)
+	}
+
+	// The index follows saves and deletes
+	e := entries["c"]
+	e.Owner = "p1"
+	e.Status = "Won"
+	require.NoError(s.Save(ctx, "c", redisContent{Entry: e}))
+	require.NoError(s.Delete(ctx, "a"))
+	page, err := s.Query(ctx, Query{Owner: "p1"})
+	require.NoError(err)
+	assert.Equal([]string{"e", "d", "c", "b"}, page.Ids)
+	page, err = s.Query(ctx, Query{Owner: "p2"})
+	require.NoError(err)
+	assert.Empty(page.Ids)
+	page, err = s.Query(ctx, Query{Statuses: []string{"InPlay"}})
+	require.NoError(err)
+	assert.Equal([]string{"e"}, page.Ids)
+	members, err := server.ZMembers(REDIS_KEY_PREFIX + "index:status:InPlay")
+	require.NoError(err)
+	assert.Equal([]string{indexKey(entries["e"].Created, "e")}, members)
+
+	// Entries left in sets they no longer belong in are skipped
+	server.ZAdd(REDIS_KEY_PREFIX+"index:owner:p2", 0, indexKey(entries["c"].Created, "c"))
+	page, err = s.Query(ctx, Query{Owner: "p2"})
+	require.NoError(err)
+	assert.Empty(page.Ids)
+
+	require.NoError(s.PurgeAll(ctx))
+	page, err = s.Query(ctx, Query{})
+	require.NoError(err)
+	assert.Empty(page.Ids)
+
+	_, err = s.Query(ctx, Query{Cursor: "not a cursor"})
+	assert.ErrorIs(err, ErrInvalidCursor)
+}
+
+func TestRedisExpiry(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	s, server := newTestRedisStore(t, time.Hour)
+
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(s.Save(ctx, "old", redisContent{Entry: Entry{Owner: "p1", Created: created}}))
+	server.FastForward(40 * time.Minute)
+	require.NoError(s.Save(ctx, "new", redisContent{Entry: Entry{Owner: "p1", Created: created.Add(time.Minute)}}))
+	server.FastForward(40 * time.Minute)
+
+	// Only the entry saved within the last hour is left
+	content, err := s.Load(ctx, "old")
+	require.NoError(err)
+	assert.Nil(content)
+	keys, err := s.Keys(ctx)
+	require.NoError(err)
+	assert.Equal([]string{"new"}, keys)
+	page, err := s.Query(ctx, Query{Owner: "p1"})
+	require.NoError(err)
+	assert.Equal([]string{"new"}, page.Ids)
+
+	// Expired ids are dropped from the sets once seen
+	members, err := server.ZMembers(REDIS_KEY_PREFIX + "index:owner:p1")
+	require.NoError(err)
+	assert.Equal([]string{indexKey(created.Add(time.Minute), "new")}, members)
+
+	// An expired entry may be created again from version 0
+	assert.NoError(s.CompareAndSwap(ctx, "old", 0, redisContent{Rev: 1}))
+}
+
+// Records how many keys each script and each delete was given
+type scriptHook struct {
+	mu   sync.Mutex
+	keys []interface{}
+	dels []int
+}
+
+func (h *scriptHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *scriptHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.record(cmd)
+		return next(ctx, cmd)
+	}
+}
+
+func (h *scriptHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			h.record(cmd)
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func (h *scriptHook) record(cmd redis.Cmder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch cmd.Name() {
+	case "eval", "evalsha":
+		h.keys = append(h.keys, cmd.Args()[2])
+	case "del":
+		h.dels = append(h.dels, len(cmd.Args())-1)
+	}
+}
+
+func TestRedisCluster(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{server.Addr()}})
+	t.Cleanup(func() { client.Close() })
+	hook := &scriptHook{}
+	client.OnNewNode(func(node *redis.Client) { node.AddHook(hook) })
+	s := NewRedisStore(client, REDIS_KEY_PREFIX, 0)
+
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(s.CompareAndSwap(ctx, "a", 0, redisContent{Rev: 1, Entry: Entry{Owner: "p1", Created: created}}))
+	require.NoError(s.CompareAndSwap(ctx, "a", 1, redisContent{Rev: 2, Entry: Entry{Owner: "p2", Created: created}}))
+	require.NoError(s.Save(ctx, "b", redisContent{Entry: Entry{Owner: "p2", Created: created.Add(time.Minute)}}))
+	page, err := s.Query(ctx, Query{Owner: "p2"})
+	require.NoError(err)
+	assert.Equal([]string{"b", "a"}, page.Ids)
+	require.NoError(s.Delete(ctx, "a"))
+	page, err = s.Query(ctx, Query{})
+	require.NoError(err)
+	assert.Equal([]string{"b"}, page.Ids)
+
+	// Each script only touches the entry it is given, which keeps it in one
+	// slot of a cluster
+	require.NotEmpty(hook.keys)
+	for _, n := range hook.keys {
+		assert.EqualValues(1, n)
+	}
+	assert.False(server.Exists(REDIS_KEY_PREFIX + "index:owner:p1"))
+
+	// Purging scans every master and deletes one key at a time
+	require.NoError(s.PurgeAll(ctx))
+	require.NotEmpty(hook.dels)
+	for _, n := range hook.dels {
+		assert.Equal(1, n)
+	}
+	assert.Empty(server.Keys())
+}
+
+func TestRedisPing(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	s, server := newTestRedisStore(t, 0)
+	assert.NoError(s.Ping(ctx))
+	server.Close()
+	assert.Equal(ErrNotReady, s.Ping(ctx))
+}
@@ -0,0 +1,487 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/tracing"
+	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	_ "modernc.org/sqlite"
+)
+
+// SQL dialects, named after the database/sql drivers used for them
+const SQL_SQLITE = "sqlite"
+const SQL_POSTGRES = "postgres"
+
+// Content that implements Recorder is also kept as columns of the games table
+// and rows of the attempts table, so that it can be queried with SQL
+type Recorder interface {
+	Record() Record
+}
+
+type Record struct {
+	Mode     string
+	Word     string
+	Attempts []Attempt
+}
+
+type Attempt struct {
+	Word   string
+	Valid  bool
+	Result string
+	Time   time.Time
+}
+
+// Returns a store that keeps content as JSON in the games table of db, after
+// bringing its schema up to date. Load returns the JSON as a
+// json.RawMessage. Times are kept in UTC.
+func NewSqlStore(ctx context.Context, db *sql.DB, dialect string) (Store, error) {
+	s := &sqlStore{db: db, dialect: dialect}
+	if err := s.migrate(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *sqlStore) Save(ctx context.Context, id string, content interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "store.Save", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return err
+	}
+
+	if _, err := s.write(ctx, id, false, 0, content); err != nil {
+		return err
+	}
+	logging.Debug(ctx, "store save", "id", id)
+
+	return nil
+}
+
+func (s *sqlStore) CompareAndSwap(ctx context.Context, id string, version int, content interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "store.CompareAndSwap", attribute.String("store.id", id), attribute.Int("store.version", version))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return err
+	}
+
+	saved, err := s.write(ctx, id, true, version, content)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		logging.Debug(ctx, "store version conflict", "id", id, "version", version)
+		return ErrVersionConflict
+	}
+	logging.Debug(ctx, "store save", "id", id)
+
+	return nil
+}
+
+func (s *sqlStore) Load(ctx context.Context, id string) (_ interface{}, err error) {
+	ctx, span := tracing.Start(ctx, "store.Load", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return nil, err
+	}
+
+	var data string
+	err = s.db.QueryRowContext(ctx, s.rebind("SELECT data FROM games WHERE id = ?"), id).Scan(&data)
+	if err == sql.ErrNoRows {
+		logging.Debug(ctx, "store miss", "id", id)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(data), nil
+}
+
+func (s *sqlStore) Exists(ctx context.Context, id string) (_ bool, err error) {
+	_, span := tracing.Start(ctx, "store.Exists", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return false, err
+	}
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, s.rebind("SELECT COUNT(*) FROM games WHERE id = ?"), id).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s *sqlStore) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "store.Delete", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return err
+	}
+
+	deleted := false
+	err = s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, s.rebind("DELETE FROM attempts WHERE game_id = ?"), id); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, s.rebind("DELETE FROM games WHERE id = ?"), id)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		deleted = n > 0
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrInvalidId
+	}
+	logging.Debug(ctx, "store delete", "id", id)
+
+	return nil
+}
+
+func (s *sqlStore) PurgeAll(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "store.PurgeAll")
+	defer func() { tracing.End(span, err) }()
+
+	var count int64
+	err = s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM attempts"); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, "DELETE FROM games")
+		if err != nil {
+			return err
+		}
+		count, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	logging.Info(ctx, "store purged", "count", count)
+
+	return nil
+}
+
+func (s *sqlStore) Keys(ctx context.Context) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "store.Keys")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT id FROM games")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		keys = append(keys, id)
+	}
+
+	return keys, rows.Err()
+}
+
+func (s *sqlStore) Query(ctx context.Context, q Query) (_ Page, err error) {
+	ctx, span := tracing.Start(ctx, "store.Query")
+	defer func() { tracing.End(span, err) }()
+
+	where := []string{"indexed = ?"}
+	args := []interface{}{true}
+	if len(q.Owner) > 0 {
+		where = append(where, "owner = ?")
+		args = append(args, q.Owner)
+	}
+	if len(q.Statuses) > 0 {
+		where = append(where, "status IN (?"+strings.Repeat(", ?", len(q.Statuses)-1)+")")
+		for _, status := range q.Statuses {
+			args = append(args, status)
+		}
+	}
+	if !q.CreatedSince.IsZero() {
+		where = append(where, "created >= ?")
+		args = append(args, q.CreatedSince.UTC())
+	}
+	if !q.UpdatedSince.IsZero() {
+		where = append(where, "updated >= ?")
+		args = append(args, q.UpdatedSince.UTC())
+	}
+	if len(q.Cursor) > 0 {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return Page{}, err
+		}
+		where = append(where, "(created < ? OR (created = ? AND id < ?))")
+		args = append(args, c.created.UTC(), c.created.UTC(), c.id)
+	}
+	// One more than the limit tells whether there is a next page
+	limit := q.limit()
+	args = append(args, limit+1)
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(
+		"SELECT id, created FROM games WHERE "+strings.Join(where, " AND ")+
+			" ORDER BY created DESC, id DESC LIMIT ?"), args...)
+	if err != nil {
+		return Page{}, err
+	}
+	defer rows.Close()
+
+	page := Page{Ids: []string{}}
+	var last cursor
+	for rows.Next() {
+		var id string
+		var created sql.NullTime
+		if err := rows.Scan(&id, &created); err != nil {
+			return Page{}, err
+		}
+		if len(page.Ids) == limit {
+			page.Next = encodeCursor(last.created, last.id)
+			break
+		}
+		page.Ids = append(page.Ids, id)
+		last = cursor{created: created.Time, id: id}
+	}
+	if err := rows.Err(); err != nil {
+		return Page{}, err
+	}
+	span.SetAttributes(attribute.Int("store.count", len(page.Ids)))
+
+	return page, nil
+}
+
+func (s *sqlStore) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		logging.Warn(ctx, "database not reachable", "error", err.Error())
+		return ErrNotReady
+	}
+
+	return nil
+}
+
+/////////////////
+
+type sqlStore struct {
+	db      *sql.DB
+	dialect string
+}
+
+// Schema changes, applied in order. Each one is applied once, in a
+// transaction of its own, and recorded in schema_migrations. Never change
+// one that has been released; add another instead.
+var sqlMigrations = []string{
+	`CREATE TABLE games (
+		id TEXT PRIMARY KEY,
+		data TEXT NOT NULL,
+		version INTEGER,
+		indexed BOOLEAN NOT NULL DEFAULT FALSE,
+		owner TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT '',
+		created TIMESTAMP,
+		updated TIMESTAMP
+	);
+	CREATE INDEX games_created ON games (created, id);
+	CREATE INDEX games_owner_created ON games (owner, created, id);
+	CREATE INDEX games_status_created ON games (status, created, id)`,
+
+	`ALTER TABLE games ADD COLUMN mode TEXT NOT NULL DEFAULT '';
+	ALTER TABLE games ADD COLUMN secret_word TEXT NOT NULL DEFAULT '';
+	CREATE TABLE attempts (
+		game_id TEXT NOT NULL REFERENCES games (id),
+		number INTEGER NOT NULL,
+		word TEXT NOT NULL,
+		valid BOOLEAN NOT NULL,
+		result TEXT NOT NULL,
+		created TIMESTAMP NOT NULL,
+		PRIMARY KEY (game_id, number)
+	)`,
+}
+
+// Opens the database at url with the driver for dialect and brings its
+// schema up to date
+func openSql(ctx context.Context, dialect string, url string) (*sql.DB, Store, error) {
+	db, err := sql.Open(dialect, url)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dialect == SQL_SQLITE {
+		// SQLite allows one writer at a time, and each connection to an
+		// in-memory database would get a database of its own
+		db.SetMaxOpenConns(1)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	s, err := NewSqlStore(ctx, db, dialect)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return db, s, nil
+}
+
+// Applies the migrations the database has not had yet
+func (s *sqlStore) migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		applied TIMESTAMP NOT NULL
+	)`); err != nil {
+		return err
+	}
+
+	var current int
+	if err := s.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+		return err
+	}
+	if current > len(sqlMigrations) {
+		return ErrSchemaVersion
+	}
+
+	for i := current; i < len(sqlMigrations); i++ {
+		version := i + 1
+		err := s.inTx(ctx, func(tx *sql.Tx) error {
+			for _, stmt := range strings.Split(sqlMigrations[i], ";") {
+				if _, err := tx.ExecContext(ctx, stmt); err != nil {
+					return err
+				}
+			}
+			_, err := tx.ExecContext(ctx, s.rebind("INSERT INTO schema_migrations (version, applied) VALUES (?, ?)"), version, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		logging.Info(ctx, "store schema migrated", "version", version)
+	}
+
+	return nil
+}
+
+// Saves content, its index entry and its record in one transaction. When
+// check is set, content is only saved if the stored version is version, and
+// saved reports whether it was.
+func (s *sqlStore) write(ctx context.Context, id string, check bool, version int, content interface{}) (saved bool, err error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return false, err
+	}
+
+	var stored interface{}
+	if v, ok := content.(Versioned); ok {
+		stored = v.Version()
+	}
+	indexed := false
+	e := Entry{}
+	if v, ok := content.(Indexer); ok {
+		indexed = true
+		e = v.Index()
+	}
+	r := Record{}
+	if v, ok := content.(Recorder); ok {
+		r = v.Record()
+	}
+	values := []interface{}{string(data), stored, indexed, e.Owner, e.Status, utcOf(e.Created), utcOf(e.Updated), r.Mode, r.Word}
+
+	err = s.inTx(ctx, func(tx *sql.Tx) error {
+		var res sql.Result
+		var err error
+		switch {
+		case !check:
+			res, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO games (id, data, version, indexed, owner, status, created, updated, mode, secret_word)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (id) DO UPDATE SET data = excluded.data, version = excluded.version, indexed = excluded.indexed,
+				owner = excluded.owner, status = excluded.status, created = excluded.created, updated = excluded.updated,
+				mode = excluded.mode, secret_word = excluded.secret_word`), append([]interface{}{id}, values...)...)
+		case version == 0:
+			res, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO games (id, data, version, indexed, owner, status, created, updated, mode, secret_word)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (id) DO NOTHING`), append([]interface{}{id}, values...)...)
+		default:
+			res, err = tx.ExecContext(ctx, s.rebind(`UPDATE games SET data = ?, version = ?, indexed = ?, owner = ?, status = ?,
+				created = ?, updated = ?, mode = ?, secret_word = ?
+				WHERE id = ? AND version = ?`), append(values, id, version)...)
+		}
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil || n < 1 {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, s.rebind("DELETE FROM attempts WHERE game_id = ?"), id); err != nil {
+			return err
+		}
+		for i, a := range r.Attempts {
+			if _, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO attempts (game_id, number, word, valid, result, created)
+				VALUES (?, ?, ?, ?, ?, ?)`), id, i+1, a.Word, a.Valid, a.Result, a.Time.UTC()); err != nil {
+				return err
+			}
+		}
+		saved = true
+		return nil
+	})
+
+	return saved, err
+}
+
+// Runs fn in a transaction, which is committed if fn returns nil and rolled
+// back otherwise
+func (s *sqlStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Replaces the ? placeholders of query with the ones of the dialect
+func (s *sqlStore) rebind(query string) string {
+	if s.dialect != SQL_POSTGRES {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func utcOf(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
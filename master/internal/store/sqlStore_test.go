@@ -0,0 +1,295 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a SQL store backed by an in-memory SQLite database
+func newTestSqlStore(t *testing.T) (Store, *sql.DB) {
+	db, s, err := openSql(context.Background(), SQL_SQLITE, ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return s, db
+}
+
+type sqlContent struct {
+	Rev   int    `json:"rev"`
+	Entry Entry  `json:"entry"`
+	Rows  Record `json:"record"`
+}
+
+func (c sqlContent) Version() int {
+	return c.Rev
+}
+
+func (c sqlContent) Index() Entry {
+	return c.Entry
+}
+
+func (c sqlContent) Record() Record {
+	return c.Rows
+}
+
+func TestSqlMigrate(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "wordle.db")
+
+	// Opening the same database again applies nothing more
+	for i := 0; i < 2; i++ {
+		db, _, err := openSql(ctx, SQL_SQLITE, path)
+		require.NoError(err)
+		var count, version int
+		require.NoError(db.QueryRow("SELECT COUNT(*), MAX(version) FROM schema_migrations").Scan(&count, &version))
+		assert.Equal(len(sqlMigrations), count)
+		assert.Equal(len(sqlMigrations), version)
+		db.Close()
+	}
+
+	// A database migrated by a newer server is left alone
+	db, err := sql.Open(SQL_SQLITE, path)
+	require.NoError(err)
+	_, err = db.Exec("INSERT INTO schema_migrations (version, applied) VALUES (?, ?)", len(sqlMigrations)+1, time.Now().UTC())
+	require.NoError(err)
+	db.Close()
+	_, _, err = openSql(ctx, SQL_SQLITE, path)
+	assert.Equal(ErrSchemaVersion, err)
+}
+
+func TestSqlitePragma(t *testing.T) {
+	ctx := context.Background()
+
+	// Pragmas are set through the url, as in the default settings
+	db, _, err := openSql(ctx, SQL_SQLITE, "file:"+filepath.Join(t.TempDir(), "wordle.db")+"?_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	defer db.Close()
+	var timeout int
+	require.NoError(t, db.QueryRow("PRAGMA busy_timeout").Scan(&timeout))
+	assert.Equal(t, 5000, timeout)
+}
+
+func TestSqlSaveAndLoad(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	s, _ := newTestSqlStore(t)
+
+	tests := []struct {
+		id      string
+		content interface{}
+		result  string
+		err     error
+	}{
+		{id: "a", content: "first", result: `"first"`},
+		{id: "b", content: sqlContent{Rev: 2}, result: `{"rev":2,"entry":{"Owner":"","Status":"","Created":"0001-01-01T00:00:00Z","Updated":"0001-01-01T00:00:00Z"},"record":{"Mode":"","Word":"","Attempts":null}}`},
+		{id: "a", content: "replaced", result: `"replaced"`},
+		{id: "", content: "cause an error", err: ErrInvalidId},
+	}
+
+	for _, test := range tests {
+		err := s.Save(ctx, test.id, test.content)
+		assert.Equal(test.err, err, test.id)
+		if err != nil {
+			continue
+		}
+		content, err := s.Load(ctx, test.id)
+		require.NoError(err)
+		assert.Equal(json.RawMessage(test.result), content, test.id)
+	}
+
+	content, err := s.Load(ctx, "missing")
+	assert.NoError(err)
+	assert.Nil(content)
+
+	ok, err := s.Exists(ctx, "a")
+	require.NoError(err)
+	assert.True(ok)
+	ok, err = s.Exists(ctx, "missing")
+	require.NoError(err)
+	assert.False(ok)
+
+	keys, err := s.Keys(ctx)
+	require.NoError(err)
+	assert.ElementsMatch([]string{"a", "b"}, keys)
+
+	require.NoError(s.Delete(ctx, "a"))
+	assert.Equal(ErrInvalidId, s.Delete(ctx, "a"))
+	keys, err = s.Keys(ctx)
+	require.NoError(err)
+	assert.Equal([]string{"b"}, keys)
+
+	require.NoError(s.PurgeAll(ctx))
+	keys, err = s.Keys(ctx)
+	require.NoError(err)
+	assert.Empty(keys)
+
+	require.NoError(s.Ping(ctx))
+}
+
+func TestSqlCompareAndSwap(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	s, db := newTestSqlStore(t)
+
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := func(words ...string) Record {
+		r := Record{Mode: "Classic", Word: "HAPPY"}
+		for i, w := range words {
+			r.Attempts = append(r.Attempts, Attempt{Word: w, Valid: true, Result: "Grey", Time: at.Add(time.Duration(i) * time.Minute)})
+		}
+		return r
+	}
+
+	tests := []struct {
+		id      string
+		version int
+		content interface{}
+		err     error
+	}{
+		{id: "a", version: 1, content: sqlContent{Rev: 1}, err: ErrVersionConflict}, // nothing stored yet
+		{id: "a", version: 0, content: sqlContent{Rev: 1, Rows: record()}},
+		{id: "a", version: 0, content: sqlContent{Rev: 1}, err: ErrVersionConflict},
+		{id: "a", version: 1, content: sqlContent{Rev: 2, Rows: record("PUPPY")}},
+		{id: "a", version: 1, content: sqlContent{Rev: 2, Rows: record("LUCKY")}, err: ErrVersionConflict}, // stale
+		{id: "a", version: 2, content: sqlContent{Rev: 3, Rows: record("PUPPY", "HAPPY")}},
+		{id: "b", version: 0, content: "not versioned"},
+		{id: "b", version: 0, content: sqlContent{Rev: 1}, err: ErrVersionConflict},
+		{id: "", version: 0, content: sqlContent{Rev: 1}, err: ErrInvalidId},
+	}
+
+	for i, test := range tests {
+		err := s.CompareAndSwap(ctx, test.id, test.version, test.content)
+		assert.Equal(test.err, err, i)
+	}
+
+	content, err := s.Load(ctx, "a")
+	require.NoError(err)
+	out := sqlContent{}
+	require.NoError(json.Unmarshal(content.(json.RawMessage), &out))
+	assert.Equal(3, out.Rev)
+
+	// The record follows the saved content, not the rejected one
+	var mode, word string
+	require.NoError(db.QueryRow("SELECT mode, secret_word FROM games WHERE id = ?", "a").Scan(&mode, &word))
+	assert.Equal("Classic", mode)
+	assert.Equal("HAPPY", word)
+	rows, err := db.Query("SELECT number, word, created FROM attempts WHERE game_id = ? ORDER BY number", "a")
+	require.NoError(err)
+	defer rows.Close()
+	words := []string{}
+	for rows.Next() {
+		var number int
+		var word string
+		var created time.Time
+		require.NoError(rows.Scan(&number, &word, &created))
+		assert.Equal(len(words)+1, number)
+		assert.True(at.Add(time.Duration(len(words))*time.Minute).Equal(created), created)
+		words = append(words, word)
+	}
+	assert.Equal([]string{"PUPPY", "HAPPY"}, words)
+
+	require.NoError(s.Delete(ctx, "a"))
+	var count int
+	require.NoError(db.QueryRow("SELECT COUNT(*) FROM attempts").Scan(&count))
+	assert.Zero(count)
+}
+
+func TestSqlQuery(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	s, _ := newTestSqlStore(t)
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := map[string]Entry{
+		"a": {Owner: "p1", Status: "InPlay", Created: base, Updated: base.Add(time.Hour)},
+		"b": {Owner: "p1", Status: "Won", Created: base.Add(time.Minute), Updated: base.Add(time.Minute)},
+		"c": {Owner: "p2", Status: "InPlay", Created: base.Add(2 * time.Minute), Updated: base.Add(2 * time.Minute)},
+		"d": {Owner: "p1", Status: "Lost", Created: base.Add(3 * time.Minute), Updated: base.Add(3 * time.Minute)},
+		"e": {Owner: "p1", Status: "InPlay", Created: base.Add(3 * time.Minute), Updated: base.Add(3 * time.Minute)},
+	}
+	for id, e := range entries {
+		require.NoError(s.Save(ctx, id, sqlContent{Entry: e}))
+	}
+	require.NoError(s.Save(ctx, "f", "not indexed"))
+
+	tests := []struct {
+		query  Query
+		result []string
+	}{
+		{query: Query{}, result: []string{"e", "d", "c", "b", "a"}},
+		{query: Query{Owner: "p1"}, result: []string{"e", "d", "b", "a"}},
+		{query: Query{Owner: "p3"}, result: []string{}},
+		{query: Query{Owner: "p1", Statuses: []string{"InPlay", "Won"}}, result: []string{"e", "b", "a"}},
+		{query: Query{CreatedSince: base.Add(2 * time.Minute)}, result: []string{"e", "d", "c"}},
+		{query: Query{UpdatedSince: base.Add(30 * time.Minute)}, result: []string{"a"}},
+	}
+
+	for _, test := range tests {
+		page, err := s.Query(ctx, test.query)
+		require.NoError(err)
+		assert.Equal(test.result, page.Ids, test.query)
+		assert.Empty(page.Next)
+	}
+
+	// Pages follow on from each other
+	got := []string{}
+	q := Query{Owner: "p1", Limit: 2}
+	for i := 0; i < 3; i++ {
+		page, err := s.Query(ctx, q)
+		require.NoError(err)
+		got = append(got, page.Ids...)
+		if len(page.Next) < 1 {
+			break
+		}
+		q.Cursor = page.Next
+	}
+	assert.Equal([]string{"e", "d", "b", "a"}, got)
+
+	// The index follows saves and deletes
+	e := entries["c"]
+	e.Owner = "p1"
+	require.NoError(s.Save(ctx, "c", sqlContent{Entry: e}))
+	require.NoError(s.Delete(ctx, "a"))
+	page, err := s.Query(ctx, Query{Owner: "p1"})
+	require.NoError(err)
+	assert.Equal([]string{"e", "d", "c", "b"}, page.Ids)
+	page, err = s.Query(ctx, Query{Owner: "p2"})
+	require.NoError(err)
+	assert.Empty(page.Ids)
+
+	_, err = s.Query(ctx, Query{Cursor: "not a cursor"})
+	assert.ErrorIs(err, ErrInvalidCursor)
+}
+
+func TestRebind(t *testing.T) {
+	assert := assert.New(t)
+
+	query := "SELECT id FROM games WHERE owner = ? AND status IN (?, ?)"
+	tests := []struct {
+		dialect string
+		result  string
+	}{
+		{dialect: SQL_SQLITE, result: query},
+		{dialect: SQL_POSTGRES, result: "SELECT id FROM games WHERE owner = $1 AND status IN ($2, $3)"},
+	}
+
+	for _, test := range tests {
+		s := &sqlStore{dialect: test.dialect}
+		assert.Equal(test.result, s.rebind(query), test.dialect)
+	}
+}
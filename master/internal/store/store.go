@@ -1,9 +1,113 @@
 package store
 
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Backends that can be chosen with Setup
+const STORE_MEMORY = "memory"
+const STORE_REDIS = "redis"
+const STORE_REDIS_CLUSTER = "redis-cluster"
+const STORE_SQLITE = "sqlite"
+const STORE_POSTGRES = "postgres"
+const STORE_BOLT = "bolt"
+
+// Content that implements Versioned can be saved with CompareAndSwap
+type Versioned interface {
+	Version() int
+}
+
 type Store interface {
-	Save(id string, content interface{}) error
-	Load(id string) (interface{}, error)
-	Exists(id string) (bool, error)
-	Delete(id string) error
-	PurgeAll() error
+	Save(ctx context.Context, id string, content interface{}) error
+	// Saves content only if the stored content is at version, or if there is
+	// none when version is 0. Otherwise returns ErrVersionConflict.
+	CompareAndSwap(ctx context.Context, id string, version int, content interface{}) error
+	Load(ctx context.Context, id string) (interface{}, error)
+	Exists(ctx context.Context, id string) (bool, error)
+	Delete(ctx context.Context, id string) error
+	PurgeAll(ctx context.Context) error
+	Keys(ctx context.Context) ([]string, error)
+	// Returns a page of the ids of content implementing Indexer, read from
+	// the indexes described with Query
+	Query(ctx context.Context, q Query) (Page, error)
+	// Reports an error when the store cannot be used
+	Ping(ctx context.Context) error
+}
+
+// Returns the store in use, which is kept in memory unless Setup or Use chose
+// another
+func WordleStore() (Store, error) {
+	activeMutex.RLock()
+	defer activeMutex.RUnlock()
+	if active != nil {
+		return active, nil
+	}
+	return getWordleStore(), nil
+}
+
+// Returns an empty store kept in memory, separate from the one WordleStore
+// falls back to
+func NewMemoryStore() Store {
+	return newWordleStore()
+}
+
+// Makes s the store in use, or the memory store again when s is nil
+func Use(s Store) {
+	activeMutex.Lock()
+	defer activeMutex.Unlock()
+	active = s
 }
+
+// Chooses the store in use. Stores other than the memory store are reached
+// at url, and the Redis store keeps content for ttl after it was last saved. Call shutdown to
+// close the connection and go back to the memory store.
+func Setup(ctx context.Context, backend string, url string, ttl time.Duration) (shutdown func() error, err error) {
+	switch backend {
+	case "", STORE_MEMORY:
+		Use(nil)
+		return func() error { return nil }, nil
+	case STORE_REDIS, STORE_REDIS_CLUSTER:
+		client, err := dialRedis(ctx, url, backend == STORE_REDIS_CLUSTER)
+		if err != nil {
+			return nil, err
+		}
+		Use(NewRedisStore(client, REDIS_KEY_PREFIX, ttl))
+		return func() error {
+			Use(nil)
+			return client.Close()
+		}, nil
+	case STORE_SQLITE, STORE_POSTGRES:
+		dialect := SQL_SQLITE
+		if backend == STORE_POSTGRES {
+			dialect = SQL_POSTGRES
+		}
+		db, s, err := openSql(ctx, dialect, url)
+		if err != nil {
+			return nil, err
+		}
+		Use(s)
+		return func() error {
+			Use(nil)
+			return db.Close()
+		}, nil
+	case STORE_BOLT:
+		db, s, err := openBolt(url)
+		if err != nil {
+			return nil, err
+		}
+		Use(s)
+		return func() error {
+			Use(nil)
+			return db.Close()
+		}, nil
+	default:
+		return nil, ErrBackend
+	}
+}
+
+/////////////
+
+var active Store
+var activeMutex sync.RWMutex
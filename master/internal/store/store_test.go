@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetup(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	memory := getWordleStore()
+
+	tests := []struct {
+		backend string
+		url     string
+		result  Store
+		err     bool
+	}{
+		{backend: "", url: "", result: memory},
+		{backend: STORE_MEMORY, url: "", result: memory},
+		{backend: STORE_REDIS, url: "redis://" + server.Addr() + "/0", result: &redisStore{}},
+		{backend: STORE_REDIS_CLUSTER, url: "redis://" + server.Addr(), result: &redisStore{}},
+		{backend: STORE_REDIS_CLUSTER, url: "not a url", err: true},
+		{backend: STORE_SQLITE, url: ":memory:", result: &sqlStore{}},
+		{backend: STORE_BOLT, url: filepath.Join(t.TempDir(), "wordle.bolt"), result: &boltStore{}},
+		{backend: STORE_REDIS, url: "not a url", err: true},
+		{backend: STORE_REDIS, url: "redis://127.0.0.1:1/0", err: true},
+		{backend: "carrier-pigeon", err: true},
+	}
+
+	for _, test := range tests {
+		shutdown, err := Setup(ctx, test.backend, test.url, time.Hour)
+		if test.err {
+			assert.Error(err, test.backend, test.url)
+			continue
+		}
+		require.NoError(err)
+
+		s, err := WordleStore()
+		require.NoError(err)
+		if test.result == memory {
+			assert.Same(memory, s)
+		} else {
+			assert.IsType(test.result, s)
+		}
+
+		require.NoError(shutdown())
+		s, err = WordleStore()
+		require.NoError(err)
+		assert.Same(memory, s)
+	}
+}
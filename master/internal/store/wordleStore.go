@@ -1,52 +1,115 @@
 package store
 
 import (
+	"context"
+	"sync"
+
+	"aluance.io/wordleserver/internal/logging"
+	"aluance.io/wordleserver/internal/tracing"
 	"github.com/matryer/resync"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func WordleStore() (Store, error) {
-	ws := getWordleStore()
-	return ws, nil
+func (s *wordleStore) Save(ctx context.Context, id string, content interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "store.Save", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateId(id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.games[id] = content
+	s.unindex(id)
+	if v, ok := content.(Indexer); ok {
+		s.reindex(id, v.Index())
+	}
+	logging.Debug(ctx, "store save", "id", id)
+
+	return nil
 }
 
-func (s wordleStore) Save(id string, content interface{}) error {
+func (s *wordleStore) CompareAndSwap(ctx context.Context, id string, version int, content interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "store.CompareAndSwap", attribute.String("store.id", id), attribute.Int("store.version", version))
+	defer func() { tracing.End(span, err) }()
+
 	if err := validateId(id); err != nil {
 		return err
 	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := 0
+	if c, ok := s.games[id]; ok {
+		v, ok := c.(Versioned)
+		if !ok {
+			return ErrVersionConflict
+		}
+		current = v.Version()
+	}
+	if current != version {
+		logging.Debug(ctx, "store version conflict", "id", id, "version", version, "current", current)
+		return ErrVersionConflict
+	}
+
 	s.games[id] = content
+	s.unindex(id)
+	if v, ok := content.(Indexer); ok {
+		s.reindex(id, v.Index())
+	}
+	logging.Debug(ctx, "store save", "id", id)
 
 	return nil
 }
 
-func (s wordleStore) Load(id string) (interface{}, error) {
+func (s *wordleStore) Load(ctx context.Context, id string) (_ interface{}, err error) {
+	ctx, span := tracing.Start(ctx, "store.Load", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
 	if err := validateId(id); err != nil {
 		return nil, err
 	}
 
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	c, ok := s.games[id]
 	if !ok {
+		logging.Debug(ctx, "store miss", "id", id)
 		return nil, nil
 	}
 
 	return c, nil
 }
 
-func (s wordleStore) Exists(id string) (bool, error) {
+func (s *wordleStore) Exists(ctx context.Context, id string) (_ bool, err error) {
+	_, span := tracing.Start(ctx, "store.Exists", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
 	if err := validateId(id); err != nil {
 		return false, err
 	}
 
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	_, ok := s.games[id]
 	return ok, nil
 }
 
-func (s wordleStore) Delete(id string) error {
+func (s *wordleStore) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "store.Delete", attribute.String("store.id", id))
+	defer func() { tracing.End(span, err) }()
+
 	if err := validateId(id); err != nil {
 		return err
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if _, ok := s.games[id]; ok {
 		delete(s.games, id)
+		s.unindex(id)
+		logging.Debug(ctx, "store delete", "id", id)
 	} else {
 		return ErrInvalidId
 	}
@@ -54,10 +117,75 @@ func (s wordleStore) Delete(id string) error {
 	return nil
 }
 
-func (s wordleStore) PurgeAll() error {
+func (s *wordleStore) PurgeAll(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "store.PurgeAll")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := len(s.games)
 	for k, _ := range s.games {
 		delete(s.games, k)
 	}
+	s.index = make(map[string]Entry)
+	s.byOwner = make(map[string]map[string]struct{})
+	s.byStatus = make(map[string]map[string]struct{})
+	logging.Info(ctx, "store purged", "count", count)
+
+	return nil
+}
+
+func (s *wordleStore) Keys(ctx context.Context) ([]string, error) {
+	_, span := tracing.Start(ctx, "store.Keys")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.games))
+	for k := range s.games {
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+func (s *wordleStore) Query(ctx context.Context, q Query) (_ Page, err error) {
+	_, span := tracing.Start(ctx, "store.Query")
+	defer func() { tracing.End(span, err) }()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Only the owner's entries, or those of the statuses, need to be looked at
+	candidates := s.index
+	if len(q.Owner) > 0 {
+		candidates = make(map[string]Entry, len(s.byOwner[q.Owner]))
+		for id := range s.byOwner[q.Owner] {
+			candidates[id] = s.index[id]
+		}
+	} else if len(q.Statuses) > 0 {
+		candidates = make(map[string]Entry)
+		for _, status := range q.Statuses {
+			for id := range s.byStatus[status] {
+				candidates[id] = s.index[id]
+			}
+		}
+	}
+
+	page, err := q.page(candidates)
+	if err != nil {
+		return Page{}, err
+	}
+	span.SetAttributes(attribute.Int("store.count", len(page.Ids)))
+
+	return page, nil
+}
+
+func (s *wordleStore) Ping(ctx context.Context) error {
+	if s.games == nil || s.mu == nil {
+		return ErrNotReady
+	}
 
 	return nil
 }
@@ -65,7 +193,11 @@ func (s wordleStore) PurgeAll() error {
 /////////////////
 
 type wordleStore struct {
-	games map[string]interface{}
+	games    map[string]interface{}
+	index    map[string]Entry
+	byOwner  map[string]map[string]struct{}
+	byStatus map[string]map[string]struct{}
+	mu       *sync.RWMutex
 }
 
 var singleStore *wordleStore
@@ -75,20 +207,65 @@ func getWordleStore() *wordleStore {
 	if singleStore == nil {
 		once.Do(
 			func() {
-				singleStore = new(wordleStore) //&wordleStore{}
-				singleStore.games = make(map[string]interface{})
+				singleStore = newWordleStore()
 			})
 	}
 
 	return singleStore
 }
 
+func newWordleStore() *wordleStore {
+	return &wordleStore{
+		games:    make(map[string]interface{}),
+		index:    make(map[string]Entry),
+		byOwner:  make(map[string]map[string]struct{}),
+		byStatus: make(map[string]map[string]struct{}),
+		mu:       new(sync.RWMutex),
+	}
+}
+
 // Created to facilitate testing
 func resetWordleStore() {
 	singleStore = nil
 	once.Reset()
 }
 
+// Both expect the caller to hold the write lock
+func (s *wordleStore) reindex(id string, e Entry) {
+	s.index[id] = e
+	addId(s.byOwner, e.Owner, id)
+	addId(s.byStatus, e.Status, id)
+}
+
+func (s *wordleStore) unindex(id string) {
+	e, ok := s.index[id]
+	if !ok {
+		return
+	}
+	delete(s.index, id)
+	removeId(s.byOwner, e.Owner, id)
+	removeId(s.byStatus, e.Status, id)
+}
+
+func addId(index map[string]map[string]struct{}, key string, id string) {
+	if len(key) < 1 {
+		return
+	}
+	if index[key] == nil {
+		index[key] = make(map[string]struct{})
+	}
+	index[key][id] = struct{}{}
+}
+
+func removeId(index map[string]map[string]struct{}, key string, id string) {
+	if ids := index[key]; ids != nil {
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(index, key)
+		}
+	}
+}
+
 func validateId(id string) error {
 	if len(id) < 1 {
 		return ErrInvalidId
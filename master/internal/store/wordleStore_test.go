@@ -1,8 +1,10 @@
 package store
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -37,6 +39,28 @@ func TestWordleStore(t *testing.T) {
 	}
 }
 
+func TestNewMemoryStore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	resetWordleStore()
+	shared, err := WordleStore()
+	require.NoError(err)
+	require.NoError(shared.Save(ctx, "a", "shared"))
+
+	// Each store has games of its own
+	s := NewMemoryStore()
+	ok, err := s.Exists(ctx, "a")
+	require.NoError(err)
+	assert.False(ok)
+	require.NoError(s.Save(ctx, "b", "own"))
+	ok, err = shared.Exists(ctx, "b")
+	require.NoError(err)
+	assert.False(ok)
+	assert.NotSame(s, NewMemoryStore())
+}
+
 // func (s wordleStore) Save(id string, content interface{}) error
 func TestSave(t *testing.T) {
 	assert := assert.New(t)
@@ -52,13 +76,14 @@ func TestSave(t *testing.T) {
 		{id: "", content: "cause an error", err: errors.New("invalid id")},
 	}
 
+	ctx := context.Background()
 	resetWordleStore() // Ensure that we get a new instance
 	store, err := WordleStore()
 	require.NoError(err, "error obtaining the instance")
 	require.NotNil(store, "instance is nil")
 
 	for count, test := range tests {
-		err := store.Save(test.id, test.content)
+		err := store.Save(ctx, test.id, test.content)
 		assert.IsType(test.err, err, "unexpected error type")
 		if err != nil {
 			assert.EqualError(err, test.err.Error())
@@ -90,6 +115,7 @@ func TestLoad(t *testing.T) {
 		{id: "2a4b6c8d0e", content: "This is the second content", err: nil},
 	}
 
+	ctx := context.Background()
 	resetWordleStore()
 	store, err := WordleStore()
 	require.NoError(err, "error obtaining the instance")
@@ -100,13 +126,13 @@ func TestLoad(t *testing.T) {
 		if test.err != nil {
 			continue
 		}
-		err := store.Save(test.id, test.content)
+		err := store.Save(ctx, test.id, test.content)
 		require.NoError(err, "problem saving the test data")
 	}
 
 	// Test the Load function
 	for _, test := range tests {
-		content, err := store.Load(test.id)
+		content, err := store.Load(ctx, test.id)
 		assert.IsType(test.err, err, "unexpected error type")
 		if err != nil {
 			assert.EqualError(err, test.err.Error())
@@ -131,6 +157,7 @@ func TestExists(t *testing.T) {
 		{id: "2a4b6c8d0e", content: "This is the second content", err: nil},
 	}
 
+	ctx := context.Background()
 	resetWordleStore()
 	store, err := WordleStore()
 	require.NoError(err, "error obtaining the instance")
@@ -138,7 +165,7 @@ func TestExists(t *testing.T) {
 
 	// Test for non existance
 	for _, test := range tests {
-		e, err := store.Exists(test.id)
+		e, err := store.Exists(ctx, test.id)
 		assert.IsType(test.err, err, "unexpected error type")
 		if err != nil {
 			assert.EqualError(err, test.err.Error())
@@ -152,13 +179,13 @@ func TestExists(t *testing.T) {
 		if test.err != nil {
 			continue
 		}
-		err := store.Save(test.id, test.content)
+		err := store.Save(ctx, test.id, test.content)
 		require.NoError(err, "problem saving the test data")
 	}
 
 	// Test for existance
 	for _, test := range tests {
-		e, err := store.Exists(test.id)
+		e, err := store.Exists(ctx, test.id)
 		assert.IsType(test.err, err, "unexpected error type")
 		if err != nil {
 			assert.EqualError(err, test.err.Error())
@@ -182,6 +209,7 @@ func TestDelete(t *testing.T) {
 		{id: "2a4b6c8d0e", content: "This is the second content", err: nil},
 	}
 
+	ctx := context.Background()
 	resetWordleStore()
 	store, err := WordleStore()
 	require.NoError(err, "error obtaining the instance")
@@ -192,7 +220,7 @@ func TestDelete(t *testing.T) {
 		if test.err != nil {
 			continue
 		}
-		err := store.Save(test.id, test.content)
+		err := store.Save(ctx, test.id, test.content)
 		require.NoError(err, "problem saving the test data")
 	}
 
@@ -202,7 +230,7 @@ func TestDelete(t *testing.T) {
 		require.True(ok)
 		storeSize := len(v.games)
 
-		err := store.Delete(test.id)
+		err := store.Delete(ctx, test.id)
 		assert.IsType(test.err, err, "unexpected error type")
 		if err != nil {
 			assert.EqualError(err, test.err.Error())
@@ -219,7 +247,7 @@ func TestDelete(t *testing.T) {
 		require.True(ok)
 		storeSize := len(v.games)
 
-		err := store.Delete(test.id)
+		err := store.Delete(ctx, test.id)
 		assert.Error(err)
 		if test.err != nil {
 			assert.EqualError(err, test.err.Error())
@@ -246,13 +274,14 @@ func TestPurgeAll(t *testing.T) {
 		{id: "2a4b6c8d0e", content: "This is the second content", err: nil},
 	}
 
+	ctx := context.Background()
 	resetWordleStore()
 	store, err := WordleStore()
 	require.NoError(err, "error obtaining the instance")
 	require.NotNil(store, "instance is nil")
 
 	// Test purging empty store
-	err = store.PurgeAll()
+	err = store.PurgeAll(ctx)
 	assert.NoError(err)
 
 	// Save the test data
@@ -260,12 +289,12 @@ func TestPurgeAll(t *testing.T) {
 		if test.err != nil {
 			continue
 		}
-		err := store.Save(test.id, test.content)
+		err := store.Save(ctx, test.id, test.content)
 		require.NoError(err, "problem saving the test data")
 	}
 
 	// Test purging store with data
-	err = store.PurgeAll()
+	err = store.PurgeAll(ctx)
 	assert.NoError(err)
 
 	// Ensure store is empty
@@ -275,9 +304,58 @@ func TestPurgeAll(t *testing.T) {
 	assert.Zero(storeSize)
 
 	// Test purging empty store tat has just been purged
-	err = store.PurgeAll()
+	err = store.PurgeAll(ctx)
+	assert.NoError(err)
+
+}
+
+func TestKeys(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tests := []struct {
+		id      string
+		content string
+	}{
+		{id: "1a2b3c4d5e", content: "This is the first content"},
+		{id: "2a4b6c8d0e", content: "This is the second content"},
+	}
+
+	ctx := context.Background()
+	resetWordleStore()
+	store, err := WordleStore()
+	require.NoError(err, "error obtaining the instance")
+	require.NotNil(store, "instance is nil")
+
+	// Test empty store
+	keys, err := store.Keys(ctx)
 	assert.NoError(err)
+	assert.Empty(keys)
 
+	// Save the test data
+	for _, test := range tests {
+		err := store.Save(ctx, test.id, test.content)
+		require.NoError(err, "problem saving the test data")
+	}
+
+	keys, err = store.Keys(ctx)
+	assert.NoError(err)
+	assert.Len(keys, len(tests))
+	for _, test := range tests {
+		assert.Contains(keys, test.id)
+	}
+}
+
+func TestPing(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	resetWordleStore()
+	store, err := WordleStore()
+	assert.NoError(err)
+	assert.NoError(store.Ping(ctx))
+
+	assert.ErrorIs((&wordleStore{}).Ping(ctx), ErrNotReady)
 }
 
 // func createCleanStore() (Store, error) {
@@ -294,3 +372,137 @@ func TestPurgeAll(t *testing.T) {
 
 // 	return store, err
 // }
+
+type indexed struct {
+	entry Entry
+}
+
+func (c indexed) Index() Entry {
+	return c.entry
+}
+
+func TestQuery(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	resetWordleStore()
+	store, err := WordleStore()
+	require.NoError(err)
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := map[string]Entry{
+		"a": {Owner: "p1", Status: "InPlay", Created: base, Updated: base.Add(time.Hour)},
+		"b": {Owner: "p1", Status: "Won", Created: base.Add(time.Minute), Updated: base.Add(time.Minute)},
+		"c": {Owner: "p2", Status: "InPlay", Created: base.Add(2 * time.Minute), Updated: base.Add(2 * time.Minute)},
+		"d": {Owner: "p1", Status: "Lost", Created: base.Add(3 * time.Minute), Updated: base.Add(3 * time.Minute)},
+		"e": {Owner: "p1", Status: "InPlay", Created: base.Add(3 * time.Minute), Updated: base.Add(3 * time.Minute)},
+	}
+	for id, e := range entries {
+		require.NoError(store.Save(ctx, id, indexed{entry: e}))
+	}
+	require.NoError(store.Save(ctx, "f", "not indexed"))
+
+	tests := []struct {
+		query  Query
+		result []string
+	}{
+		{query: Query{}, result: []string{"e", "d", "c", "b", "a"}},
+		{query: Query{Owner: "p1"}, result: []string{"e", "d", "b", "a"}},
+		{query: Query{Owner: "p3"}, result: []string{}},
+		{query: Query{Owner: "p1", Statuses: []string{"InPlay", "Won"}}, result: []string{"e", "b", "a"}},
+		{query: Query{Statuses: []string{"InPlay"}}, result: []string{"e", "c", "a"}},
+		{query: Query{Statuses: []string{"Won", "Lost", "Resigned"}}, result: []string{"d", "b"}},
+		{query: Query{CreatedSince: base.Add(2 * time.Minute)}, result: []string{"e", "d", "c"}},
+		{query: Query{UpdatedSince: base.Add(30 * time.Minute)}, result: []string{"a"}},
+	}
+
+	for _, test := range tests {
+		page, err := store.Query(ctx, test.query)
+		require.NoError(err)
+		assert.Equal(test.result, page.Ids, test.query)
+		assert.Empty(page.Next)
+	}
+
+	// Pages follow on from each other
+	got := []string{}
+	q := Query{Owner: "p1", Limit: 2}
+	for i := 0; i < 3; i++ {
+		page, err := store.Query(ctx, q)
+		require.NoError(err)
+		got = append(got, page.Ids...)
+		if len(page.Next) < 1 {
+			break
+		}
+		q.Cursor = page.Next
+	}
+	assert.Equal([]string{"e", "d", "b", "a"}, got)
+
+	// The index follows saves and deletes
+	e := entries["c"]
+	e.Owner = "p1"
+	e.Status = "Won"
+	require.NoError(store.Save(ctx, "c", indexed{entry: e}))
+	require.NoError(store.Delete(ctx, "a"))
+	page, err := store.Query(ctx, Query{Owner: "p1"})
+	require.NoError(err)
+	assert.Equal([]string{"e", "d", "c", "b"}, page.Ids)
+	page, err = store.Query(ctx, Query{Owner: "p2"})
+	require.NoError(err)
+	assert.Empty(page.Ids)
+	page, err = store.Query(ctx, Query{Statuses: []string{"InPlay"}})
+	require.NoError(err)
+	assert.Equal([]string{"e"}, page.Ids)
+
+	require.NoError(store.PurgeAll(ctx))
+	page, err = store.Query(ctx, Query{})
+	require.NoError(err)
+	assert.Empty(page.Ids)
+
+	_, err = store.Query(ctx, Query{Cursor: "not a cursor"})
+	assert.ErrorIs(err, ErrInvalidCursor)
+}
+
+type versioned struct {
+	version int
+}
+
+func (c versioned) Version() int {
+	return c.version
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	resetWordleStore()
+	store, err := WordleStore()
+	require.NoError(err)
+
+	tests := []struct {
+		id      string
+		version int
+		content interface{}
+		err     error
+	}{
+		{id: "a", version: 1, content: versioned{1}, err: ErrVersionConflict}, // nothing stored yet
+		{id: "a", version: 0, content: versioned{1}},
+		{id: "a", version: 0, content: versioned{1}, err: ErrVersionConflict},
+		{id: "a", version: 1, content: versioned{2}},
+		{id: "a", version: 1, content: versioned{2}, err: ErrVersionConflict}, // stale
+		{id: "a", version: 2, content: versioned{3}},
+		{id: "b", version: 0, content: "not versioned"},
+		{id: "b", version: 0, content: versioned{1}, err: ErrVersionConflict},
+		{id: "", version: 0, content: versioned{1}, err: ErrInvalidId},
+	}
+
+	for i, test := range tests {
+		err := store.CompareAndSwap(ctx, test.id, test.version, test.content)
+		assert.Equal(test.err, err, i)
+	}
+
+	c, err := store.Load(ctx, "a")
+	require.NoError(err)
+	assert.Equal(versioned{3}, c)
+}
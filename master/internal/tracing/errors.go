@@ -0,0 +1,7 @@
+package tracing
+
+import "errors"
+
+var (
+	ErrExporter = errors.New("invalid trace exporter")
+)
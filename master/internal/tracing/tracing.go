@@ -0,0 +1,92 @@
+/*
+Package tracing records OpenTelemetry spans for the server.
+
+Spans are started with Start from the context handed down by the caller, so
+that the spans of the api, game, store and dictionary packages of one request
+belong to the same trace. Until Setup is called with an exporter, spans are
+not recorded at all.
+
+Key functions:
+	Setup(ctx, exporter, endpoint) - Sends spans to stdout or an OTLP collector.
+	Start(ctx, name, attrs...) - Starts a span that is a child of any span in ctx.
+	End(span, err) - Ends a span, marking it as failed when err is not nil.
+
+*/
+
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Name of the tracer and of the service in exported spans
+const TRACER_NAME = "aluance.io/wordleserver"
+const SERVICE_NAME = "wordleserver"
+
+// Exporters that can be given to Setup
+const (
+	EXPORTER_NONE   = "none"
+	EXPORTER_STDOUT = "stdout"
+	EXPORTER_OTLP   = "otlp"
+)
+
+// Installs a tracer provider sending spans to the exporter. The OTLP exporter
+// sends them over HTTP to endpoint, a URL such as http://localhost:4318, or
+// to the collector named by the standard OTEL_EXPORTER_OTLP_* environment
+// variables when endpoint is empty. The returned function flushes the spans
+// not yet exported and must be called before the server exits.
+func Setup(ctx context.Context, exporter string, endpoint string) (shutdown func(context.Context) error, err error) {
+	var exp sdktrace.SpanExporter
+	switch exporter {
+	case "", EXPORTER_NONE:
+		return func(context.Context) error { return nil }, nil
+	case EXPORTER_STDOUT:
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case EXPORTER_OTLP:
+		var opts []otlptracehttp.Option
+		if len(endpoint) > 0 {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		exp, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, ErrExporter
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", SERVICE_NAME))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return tp.Shutdown, nil
+}
+
+// Starts a span that is a child of any span in ctx. The span must be ended,
+// preferably with End.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TRACER_NAME).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// Ends the span, recording err when it is not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup(t *testing.T) {
+	assert := assert.New(t)
+
+	saved := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(saved)
+
+	tests := []struct {
+		exporter string
+		err      error
+	}{
+		{exporter: "", err: nil},
+		{exporter: EXPORTER_NONE, err: nil},
+		{exporter: EXPORTER_STDOUT, err: nil},
+		{exporter: "zipkin", err: ErrExporter},
+	}
+
+	for _, test := range tests {
+		shutdown, err := Setup(context.Background(), test.exporter, "")
+		if test.err != nil {
+			assert.ErrorIs(err, test.err, test.exporter)
+			continue
+		}
+		if assert.NoError(err, test.exporter) {
+			assert.NoError(shutdown(context.Background()), test.exporter)
+		}
+	}
+}
+
+func TestSetupOtlp(t *testing.T) {
+	require := require.New(t)
+
+	saved := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(saved)
+
+	// Stands in for a collector
+	var received atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/traces" {
+			received.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	shutdown, err := Setup(context.Background(), EXPORTER_OTLP, collector.URL)
+	require.NoError(err)
+
+	_, span := Start(context.Background(), "test")
+	span.End()
+
+	// Shutting down flushes the spans to the collector
+	require.NoError(shutdown(context.Background()))
+	require.Equal(int32(1), received.Load())
+}
+
+func TestStartEnd(t *testing.T) {
+	assert := assert.New(t)
+
+	saved := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(saved)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx, parent := Start(context.Background(), "parent", attribute.String("key", "value"))
+	_, child := Start(ctx, "child")
+	End(child, errors.New("failed"))
+	End(parent, nil)
+
+	spans := recorder.Ended()
+	if assert.Len(spans, 2) {
+		assert.Equal("child", spans[0].Name())
+		assert.Equal(codes.Error, spans[0].Status().Code)
+		assert.Equal("failed", spans[0].Status().Description)
+		assert.Len(spans[0].Events(), 1)
+		assert.Equal(spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+
+		assert.Equal("parent", spans[1].Name())
+		assert.Equal(codes.Unset, spans[1].Status().Code)
+		assert.Contains(spans[1].Attributes(), attribute.String("key", "value"))
+	}
+}
@@ -1,7 +1,157 @@
 package main
 
-import "aluance.io/wordleserver/internal/api"
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"aluance.io/wordleserver/internal/api"
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/logging"
+)
+
+const usage = `usage: wordleserver <command> [flags] [arguments]
+
+  serve                          serve the API, the command when none is given
+  play                           play against a running server in the terminal
+  solve <word>                   show how the solver finds the word
+  dict validate [file]           check a word list, the built-in one by default
+  dict stats [file]              count the words and letters of a word list
+  admin purge -yes               delete every game in the store
+  admin export <file>            write every game to file, gzipped if it ends in .gz
+  admin import [-purge] <file>   save the games in file, replacing those with the same id
+  admin backup <file>            copy the bolt store to file
+  admin restore <file>           replace the bolt store with file
+
+Every command takes -store, -store-url and -log-level, which override
+WORDLE_STORE, the address of that store and WORDLE_LOG_LEVEL; the other
+settings come from the environment. "-" is stdout or stdin for export and
+import. purge, export and import need a store other than memory, which only
+the server can see. backup and restore work on WORDLE_BOLT_PATH, or on
+-store-url with -store bolt, while no server is using it. "wordleserver <command> -h" lists
+the flags of a command.`
+
+// Where a command reads and writes
+type console struct {
+	in  io.Reader
+	out io.Writer
+	err io.Writer
+}
+
+// Runs a command with the arguments after its name
+type command func(ctx context.Context, con console, args []string) error
+
+var commands = map[string]command{
+	"serve": serve,
+	"play":  play,
+	"solve": solve,
+}
+
+// Commands with subcommands of their own
+var groups = map[string]map[string]command{
+	"dict":  dictCommands,
+	"admin": adminCommands,
+}
 
 func main() {
-	api.Initialize()
+	os.Exit(run(context.Background(), console{in: os.Stdin, out: os.Stdout, err: os.Stderr}, os.Args[1:]))
+}
+
+// Runs the command named by args and returns the exit code: 2 when the
+// command line is wrong and 1 when the command fails
+func run(ctx context.Context, con console, args []string) int {
+	if len(args) < 1 {
+		args = []string{"serve"}
+	}
+	name, cmd, args := lookup(args)
+	if cmd == nil {
+		fmt.Fprintln(con.err, usage)
+		return 2
+	}
+
+	// Only the server logs to stdout, where the other commands write results
+	if name != "serve" {
+		logging.SetOutput(con.err)
+	}
+
+	err := cmd(ctx, con, args)
+	switch err {
+	case nil:
+		return 0
+	case ErrFlags:
+		return 2 // the flag set has said what is wrong
+	case ErrUsage:
+		fmt.Fprintln(con.err, usage)
+		return 2
+	}
+	fmt.Fprintln(con.err, name+" failed:", err)
+	return 1
+}
+
+func serve(ctx context.Context, con console, args []string) error {
+	fs := newFlagSet("serve", con)
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return ErrUsage
+	}
+
+	return api.Initialize()
+}
+
+/////////////
+
+// Flags of a command, starting with those every command shares
+type flagSet struct {
+	*flag.FlagSet
+	store    *string
+	storeUrl *string
+	logLevel *string
+}
+
+func newFlagSet(name string, con console) *flagSet {
+	settings := config.Current()
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(con.err)
+
+	return &flagSet{
+		FlagSet:  fs,
+		store:    fs.String("store", settings.Store, "where games are kept: memory, redis, redis-cluster, sqlite, postgres or bolt"),
+		storeUrl: fs.String("store-url", "", "address of the store, in place of the one in the environment"),
+		logLevel: fs.String("log-level", settings.LogLevel, "lowest level logged: debug, info, warn or error"),
+	}
+}
+
+// Parses args and puts the shared flags into the settings in use
+func (fs *flagSet) parse(args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return ErrFlags
+	}
+
+	settings := config.Current()
+	settings.Store = *fs.store
+	if len(*fs.storeUrl) > 0 {
+		settings.SetStoreUrl(*fs.storeUrl)
+	}
+	settings.LogLevel = *fs.logLevel
+	config.Set(settings)
+
+	return logging.SetLevel(settings.LogLevel)
+}
+
+// Returns the name of the command args start with, the command and the
+// arguments that follow the name, or no command when there is none
+func lookup(args []string) (string, command, []string) {
+	if cmd, ok := commands[args[0]]; ok {
+		return args[0], cmd, args[1:]
+	}
+	if group, ok := groups[args[0]]; ok && len(args) > 1 {
+		if cmd, ok := group[args[1]]; ok {
+			return args[0] + " " + args[1], cmd, args[2:]
+		}
+	}
+	return args[0], nil, nil
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"aluance.io/wordleserver/internal/config"
+	"aluance.io/wordleserver/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	logging.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// Runs the command line with input on stdin and returns the exit code with
+// what was written to stdout and stderr
+func runCommand(t *testing.T, input string, args ...string) (int, string, string) {
+	config.Set(config.Load())
+	t.Cleanup(func() { config.Set(config.Load()) })
+	var out, err bytes.Buffer
+	con := console{in: strings.NewReader(input), out: &out, err: &err}
+	code := run(context.Background(), con, args)
+	return code, out.String(), err.String()
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{args: []string{"bogus"}, code: 2, stderr: "usage:"},
+		{args: []string{"serve", "extra"}, code: 2, stderr: "usage:"},
+		{args: []string{"serve", "-store", "bogus"}, code: 1, stderr: "serve failed:"},
+		{args: []string{"admin"}, code: 2, stderr: "usage:"},
+		{args: []string{"dict", "bogus"}, code: 2, stderr: "usage:"},
+		{args: []string{"solve", "-h"}, code: 2, stderr: "-strategy"},
+		{args: []string{"solve", "-bogus", "happy"}, code: 2, stderr: "flag provided but not defined"},
+		{args: []string{"solve", "-log-level", "loud", "happy"}, code: 1, stderr: "solve failed:"},
+		{args: []string{"admin", "export", "-store", "bogus", "-"}, code: 1, stderr: "admin export failed:"},
+		{args: []string{"admin", "export", "-store", "memory", "-"}, code: 1, stderr: ErrMemoryStore.Error()},
+		{args: []string{"admin", "import", "-store", "memory", "-"}, code: 1, stderr: ErrMemoryStore.Error()},
+		{args: []string{"admin", "purge", "-store", "memory", "-yes"}, code: 1, stderr: ErrMemoryStore.Error()},
+		{args: []string{"admin", "export", "-h"}, code: 2, stderr: "-store-url"},
+	}
+
+	for _, test := range tests {
+		code, _, stderr := runCommand(t, "", test.args...)
+		assert.Equal(t, test.code, code, test.args)
+		assert.Contains(t, stderr, test.stderr, test.args)
+	}
+}
+
+func TestFlagSet(t *testing.T) {
+	assert := assert.New(t)
+	t.Cleanup(func() { config.Set(config.Load()) })
+
+	fs := newFlagSet("test", console{err: io.Discard})
+	assert.NoError(fs.parse([]string{"-store", "bolt", "-store-url", "other.bolt", "-log-level", "warn", "arg"}))
+	assert.Equal([]string{"arg"}, fs.Args())
+	settings := config.Current()
+	assert.Equal("bolt", settings.Store)
+	assert.Equal("other.bolt", settings.BoltPath)
+	assert.Equal("warn", settings.LogLevel)
+	assert.Equal("warn", logging.Level())
+
+	// Settings that are not given are left as they are
+	fs = newFlagSet("test", console{err: io.Discard})
+	assert.NoError(fs.parse(nil))
+	assert.Equal(settings, config.Current())
+}
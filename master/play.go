@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"aluance.io/wordleserver/internal/client"
+	"aluance.io/wordleserver/internal/game"
+)
+
+func play(ctx context.Context, con console, args []string) error {
+	fs := newFlagSet("play", con)
+	session := client.Flags(fs.FlagSet)
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return ErrUsage
+	}
+
+	c, opts := session()
+	return client.Run(ctx, c, opts, con.in, con.out)
+}
+
+func solve(ctx context.Context, con console, args []string) error {
+	fs := newFlagSet("solve", con)
+	strategy := fs.String("strategy", "entropy", "how guesses are ranked: entropy or minmax")
+	plain := fs.Bool("plain", len(os.Getenv("NO_COLOR")) > 0, "mark letters without color")
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return ErrUsage
+	}
+
+	attempts, err := game.Solve(ctx, fs.Arg(0), *strategy)
+	if err != nil {
+		return err
+	}
+
+	screen := client.Screen{Color: !*plain}
+	for _, a := range attempts {
+		for i, r := range a.TryWord {
+			fmt.Fprint(con.out, screen.Tile(r, a.TryResult[i]))
+		}
+		fmt.Fprintln(con.out)
+	}
+	if n := len(attempts); n > 0 && strings.EqualFold(attempts[n-1].TryWord, fs.Arg(0)) {
+		fmt.Fprintln(con.out, "solved in", len(attempts), "with", *strategy)
+	} else {
+		fmt.Fprintln(con.out, "not solved in", len(attempts), "with", *strategy)
+	}
+	return nil
+}
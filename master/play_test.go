@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"g1","gameStatus":"InPlay","mode":"Daily"}`))
+	}))
+	defer server.Close()
+
+	code, stdout, stderr := runCommand(t, ":quit\n", "play", "-server", server.URL, "-mode", "daily", "-plain")
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "Daily game g1")
+	assert.Contains(t, stdout, "resume with g1")
+
+	code, _, _ = runCommand(t, "", "play", "extra")
+	assert.Equal(t, 2, code)
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+	}{
+		{args: []string{"-plain", "happy"}, stdout: "[H][A][P][P][Y]\nsolved in"},
+		{args: []string{"-plain", "-strategy", "minmax", "lucky"}, stdout: "[L][U][C][K][Y]\nsolved in"},
+		{args: []string{"xxxxx"}, code: 1},
+		{args: []string{"-strategy", "random", "happy"}, code: 1},
+		{args: []string{}, code: 2},
+	}
+
+	for _, test := range tests {
+		code, stdout, _ := runCommand(t, "", append([]string{"solve"}, test.args...)...)
+		assert.Equal(t, test.code, code, test.args)
+		assert.True(t, strings.Contains(stdout, test.stdout), stdout)
+	}
+}